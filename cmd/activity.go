@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// sparkBlocks are the bars of the activity sparkline, from low to high
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+var activityPeriods int
+var activityBucket string
+
+// activityColumn is the sparkline of a contributor's commits per period.
+// It is as wide as the number of periods.
+func activityColumn() column {
+	return column{
+		Name: "activity", Header: "ACTIVITY", Title: "Activity", Width: max(activityPeriods, len("ACTIVITY")),
+		Value: func(r contributorRecord) string { return sparkline(r.Activity) },
+	}
+}
+
+// showsActivity reports whether the activity column is rendered, so the
+// activity counts need to be computed
+func showsActivity() bool {
+	return slices.ContainsFunc(activeColumns(), func(col column) bool { return col.Name == "activity" })
+}
+
+// activityCounts counts the commits in each of the last periods buckets up
+// to and including the one containing now, oldest first
+func activityCounts(dates []time.Time, bucket string, periods int, now time.Time) []int {
+	starts := make([]time.Time, periods)
+	start := bucketStart(now, bucket)
+	for i := periods - 1; i >= 0; i-- {
+		starts[i] = start
+		start = previousBucket(start, bucket)
+	}
+
+	counts := make([]int, periods)
+	for _, date := range dates {
+		if i := slices.Index(starts, bucketStart(date, bucket)); i >= 0 {
+			counts[i]++
+		}
+	}
+	return counts
+}
+
+// previousBucket returns the start of the bucket before the one at start
+func previousBucket(start time.Time, bucket string) time.Time {
+	switch bucket {
+	case "week":
+		return start.AddDate(0, 0, -7)
+	case "quarter":
+		return start.AddDate(0, -3, 0)
+	case "year":
+		return start.AddDate(-1, 0, 0)
+	}
+	return start.AddDate(0, -1, 0)
+}
+
+// sparkline renders counts as a row of block characters scaled to the
+// largest count. Periods without commits are left blank.
+func sparkline(counts []int) string {
+	highest := slices.Max(append([]int{0}, counts...))
+
+	var b strings.Builder
+	for _, count := range counts {
+		if count == 0 {
+			b.WriteRune(' ')
+			continue
+		}
+		level := (count*len(sparkBlocks) - 1) / highest
+		b.WriteRune(sparkBlocks[level])
+	}
+	return b.String()
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"strings"
+)
+
+var attributionMode string
+
+// isValidAttribution reports whether mode is a supported --use value
+func isValidAttribution(mode string) bool {
+	return mode == "author" || mode == "committer" || mode == "both"
+}
+
+// attributeCommits credits the commits according to mode. With "committer"
+// the committer replaces the author, with "both" a commit whose committer
+// differs from its author is credited to each of them. The copy for the
+// committer keeps the hash, so the totals count the commit once.
+func attributeCommits(commits []*Commit, mode string) []*Commit {
+	if mode == "author" {
+		return commits
+	}
+
+	var attributed []*Commit
+	for _, commit := range commits {
+		if commit.CommitterEmail == "" {
+			// Records without committer fields, such as fixtures
+			attributed = append(attributed, commit)
+			continue
+		}
+		if mode == "committer" {
+			commit.Name, commit.Email, commit.Date = commit.CommitterName, commit.CommitterEmail, commit.CommitDate
+			attributed = append(attributed, commit)
+			continue
+		}
+
+		attributed = append(attributed, commit)
+		if !strings.EqualFold(commit.CommitterEmail, commit.Email) {
+			committed := *commit
+			committed.Name, committed.Email, committed.Date = commit.CommitterName, commit.CommitterEmail, commit.CommitDate
+			committed.CoAuthors = nil
+			attributed = append(attributed, &committed)
+		}
+	}
+	return attributed
+}
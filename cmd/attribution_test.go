@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/PerArneng/gitwho/pkg/gitwho"
+)
+
+func TestAttributeBothKeepsCommitCount(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit("alice", map[string]string{"main.go": "a\n"})
+	// A patch by alice applied by bob
+	if err := os.WriteFile(filepath.Join(repo.dir, "main.go"), []byte("a\nb\nc\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo.git("-c", "user.name=bob", "-c", "user.email=bob@example.com",
+		"commit", "-q", "-a", "--author", "alice <alice@example.com>", "-m", "Apply the patch")
+
+	defer func(previous string) { attributionMode = previous }(attributionMode)
+	summaries := make(map[string]Summary)
+	for _, mode := range []string{"author", "both"} {
+		attributionMode = mode
+		commits, err := collectCommits([]string{repo.dir}, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		contributors := gitwho.Aggregate(commits)
+		gitwho.ComputeShares(contributors)
+		summaries[mode] = gitwho.Summarize(contributors)
+	}
+
+	author, both := summaries["author"], summaries["both"]
+	if both.Contributors != 2 {
+		t.Errorf("--use both credits %d contributors, want alice and bob", both.Contributors)
+	}
+	if both.Commits != author.Commits || both.MedianCommitSize != author.MedianCommitSize {
+		t.Errorf("--use both counts %d commits of median size %v, want %d of %v like --use author",
+			both.Commits, both.MedianCommitSize, author.Commits, author.MedianCommitSize)
+	}
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/PerArneng/gitwho/pkg/gitwho"
+	"github.com/spf13/cobra"
+)
+
+var busThreshold float64
+var busFormat string
+
+// busfactorCmd represents the busfactor command
+var busfactorCmd = &cobra.Command{
+	Use:   "busfactor [file/directory]",
+	Short: "Compute the bus factor of a file or directory",
+	Long: `Busfactor finds the smallest set of contributors who together made more
+than --threshold percent of the line changes to a file or directory. The
+size of that set is the bus factor: how many people would have to leave
+before most of the knowledge of the code is gone.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+		if !isValidTableFormat(busFormat) {
+			fmt.Printf("Error: Invalid format %s. Supported formats: table, csv, json\n", busFormat)
+			os.Exit(1)
+		}
+		if busThreshold <= 0 || busThreshold >= 100 {
+			fmt.Println("Error: --threshold must be between 0 and 100")
+			os.Exit(1)
+		}
+
+		commits, err := collectCommits([]string{path}, lastTimeRange, repoPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		contributors := gitwho.Aggregate(commits)
+		gitwho.ComputeShares(contributors)
+
+		result := computeBusFactor(contributors, busThreshold)
+		if busFormat == "table" {
+			fmt.Printf("Bus factor for %s: %d (%s of changes by %s)\n\n",
+				path, result.BusFactor, formatPercent(result.Share), pluralize(result.BusFactor, "contributor"))
+		}
+		if err := displayTable(os.Stdout, busFormat, busFactorTable(result), result); err != nil {
+			fmt.Printf("Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	busfactorCmd.Flags().Float64Var(&busThreshold, "threshold", 50, "Percentage of changes the set of contributors must exceed")
+	busfactorCmd.Flags().StringVar(&busFormat, "format", "table", "Output format (table, csv, json)")
+	rootCmd.AddCommand(busfactorCmd)
+}
+
+// busFactor is the smallest set of contributors covering the threshold
+type busFactor struct {
+	BusFactor int                 `json:"busFactor"`
+	Threshold float64             `json:"threshold"`
+	Share     float64             `json:"share"`
+	Members   []contributorRecord `json:"members"`
+}
+
+// computeBusFactor takes contributors in order of their changes until their
+// combined share exceeds threshold percent
+func computeBusFactor(contributors []*Contributor, threshold float64) busFactor {
+	sortContributorsBy(contributors, "total", false)
+
+	var members []*Contributor
+	share := 0.0
+	for _, contributor := range contributors {
+		if share > threshold {
+			break
+		}
+		members = append(members, contributor)
+		share += contributor.ChangeShare
+	}
+
+	return busFactor{
+		BusFactor: len(members),
+		Threshold: threshold,
+		Share:     share,
+		Members:   buildReportDocument(&Report{Contributors: members}, newRenderOptions(false)).Contributors,
+	}
+}
+
+// busFactorTable lays out the members with their cumulative share
+func busFactorTable(result busFactor) textTable {
+	table := textTable{
+		Headers: []string{"NAME", "EMAIL", "TOTAL", "SHARE", "CUMULATIVE"},
+		Numeric: []bool{false, false, true, true, true},
+	}
+	cumulative := 0.0
+	for _, record := range result.Members {
+		cumulative += record.ChangeShare
+		table.Rows = append(table.Rows, []string{
+			record.Name,
+			record.Email,
+			strconv.Itoa(record.Total),
+			formatPercent(record.ChangeShare),
+			formatPercent(cumulative),
+		})
+	}
+	return table
+}
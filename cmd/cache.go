@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var cacheFormat string
+var cacheOlderThan string
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the clones of remote repositories",
+	Long: `Cache manages the directory remote repositories given with --repo are
+cloned into: the user cache directory (~/.cache/gitwho/repos on Linux), or
+$GITWHO_CACHE_DIR when it is set.
+
+Cached clones are fetched every time they are used, unless --cache-ttl is
+given and they were fetched more recently than that.`,
+}
+
+// cacheListCmd represents the cache list command
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the cached clones",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !isValidTableFormat(cacheFormat) {
+			fmt.Printf("Error: Invalid format %s. Supported formats: table, csv, json\n", cacheFormat)
+			os.Exit(1)
+		}
+		clones, err := cachedClones(nil)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := displayTable(os.Stdout, cacheFormat, cacheTable(clones), clones); err != nil {
+			fmt.Printf("Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// cacheCleanCmd represents the cache clean command
+var cacheCleanCmd = &cobra.Command{
+	Use:   "clean [repository...]",
+	Short: "Remove cached clones",
+	Long: `Clean removes the given cached clones, by URL, shorthand or cache path,
+or all of them. With --older-than only the clones that were not fetched
+within that duration are removed.`,
+	Args: cobra.ArbitraryArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		removed, err := cleanCache(args, cacheOlderThan, time.Now())
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		infof("Removed %s\n", pluralize(removed, "cached clone"))
+	},
+}
+
+// cleanCache removes the given cached clones, or all of them, that were
+// not fetched since olderThan before now, and returns how many it removed.
+// An invalid olderThan fails before any clone is looked at.
+func cleanCache(repos []string, olderThan string, now time.Time) (int, error) {
+	var cutoff time.Time
+	if olderThan != "" {
+		var err error
+		if cutoff, err = cacheCutoff(olderThan, now); err != nil {
+			return 0, err
+		}
+	}
+	clones, err := cachedClones(repos)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, clone := range clones {
+		if olderThan != "" && clone.Fetched.After(cutoff) {
+			continue
+		}
+		if err := os.RemoveAll(clone.Dir); err != nil {
+			return removed, fmt.Errorf("Error removing %s: %v", clone.Dir, err)
+		}
+		infof("Removed %s\n", clone.Path)
+		removed++
+	}
+	return removed, nil
+}
+
+// cacheUpdateCmd represents the cache update command
+var cacheUpdateCmd = &cobra.Command{
+	Use:   "update [repository...]",
+	Short: "Fetch cached clones",
+	Long: `Update fetches the given cached clones, by URL, shorthand or cache path,
+or all of them, so the next analysis does not have to.`,
+	Args: cobra.ArbitraryArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		clones, err := cachedClones(args)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		var stderr io.Writer = os.Stderr
+		if quiet {
+			stderr = nil
+		}
+		for _, clone := range clones {
+			infof("Updating %s\n", clone.URL)
+			if err := updateClone(clone.Dir, clone.URL, stderr); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+func init() {
+	cacheListCmd.Flags().StringVar(&cacheFormat, "format", "table", "Output format (table, csv, json)")
+	cacheCleanCmd.Flags().StringVar(&cacheOlderThan, "older-than", "", "Only remove clones not fetched within this duration (e.g. 12h, 30d, 3m for months)")
+	cacheCmd.AddCommand(cacheListCmd, cacheCleanCmd, cacheUpdateCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+// cachedClone is a clone of a remote repository in the cache
+type cachedClone struct {
+	Path    string    `json:"path"` // relative to the cache directory
+	Dir     string    `json:"dir"`
+	URL     string    `json:"url"`
+	Size    int64     `json:"size"`
+	Fetched time.Time `json:"fetched"`
+}
+
+// cachedClones returns the clones in the cache, recognized by the marker
+// gitwho leaves when it fetches them. When repos are given, only the
+// clones of those URLs, shorthands or cache paths are returned.
+func cachedClones(repos []string) ([]cachedClone, error) {
+	cache, err := cacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool)
+	config, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	for _, repo := range repos {
+		if url, ok := expandShorthand(repo, config.Forges); ok {
+			repo = url
+		}
+		wanted[cacheKey(repo)] = true
+	}
+
+	var clones []cachedClone
+	err = filepath.WalkDir(cache, func(path string, entry fs.DirEntry, err error) error {
+		if os.IsNotExist(err) && path == cache {
+			return filepath.SkipDir
+		}
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		// Only clones gitwho made carry the marker, so repositories that
+		// happen to be below a custom cache directory are never touched
+		rel, _ := filepath.Rel(cache, path)
+		if rel == "." || !isRegularFile(filepath.Join(path, ".git", fetchedMarker)) {
+			return nil
+		}
+
+		if len(repos) == 0 || wanted[rel] {
+			url, _ := runGit(nil, "-C", path, "remote", "get-url", "origin")
+			clones = append(clones, cachedClone{
+				Path:    rel,
+				Dir:     path,
+				URL:     strings.TrimSpace(url),
+				Size:    dirSize(path),
+				Fetched: lastFetched(path),
+			})
+		}
+		return filepath.SkipDir
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error reading the cache: %v", err)
+	}
+	if len(clones) < len(wanted) {
+		return nil, fmt.Errorf("Error: %s not in the cache", pluralize(len(wanted)-len(clones), "requested clone"))
+	}
+	return clones, nil
+}
+
+// dirSize returns the total size of the files below dir
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && entry.Type().IsRegular() {
+			if info, err := entry.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+// formatSize formats a number of bytes with a binary unit, e.g. 12.3 MiB
+func formatSize(size int64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	value := float64(size)
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return strconv.FormatInt(size, 10) + " B"
+	}
+	return strconv.FormatFloat(value, 'f', 1, 64) + " " + units[unit]
+}
+
+// cacheTable lays out the cached clones
+func cacheTable(clones []cachedClone) textTable {
+	table := textTable{
+		Headers: []string{"PATH", "URL", "SIZE", "FETCHED"},
+		Numeric: []bool{false, false, true, false},
+	}
+	for _, clone := range clones {
+		fetched := "-"
+		if !clone.Fetched.IsZero() {
+			fetched = clone.Fetched.Format("2006-01-02 15:04")
+		}
+		table.Rows = append(table.Rows, []string{clone.Path, clone.URL, formatSize(clone.Size), fetched})
+	}
+	return table
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"strings"
+)
+
+var coAuthorMode string
+
+// isValidCoAuthorMode reports whether mode is a supported --co-authors value
+func isValidCoAuthorMode(mode string) bool {
+	return mode == "none" || mode == "full" || mode == "split"
+}
+
+// creditCoAuthors adds a copy of each commit for every co-author. With
+// mode "full" every author is credited with all changed lines, with "split"
+// the lines are divided evenly between the authors. Each author is counted
+// as having made the commit.
+func creditCoAuthors(commits []*Commit, mode string) []*Commit {
+	if mode == "none" {
+		return commits
+	}
+
+	var credited []*Commit
+	for _, commit := range commits {
+		authors := []*Commit{commit}
+		for _, coAuthor := range commit.CoAuthors {
+			name, email, ok := parseIdentity(coAuthor)
+			if !ok || hasAuthor(authors, name, email) {
+				continue
+			}
+			authors = append(authors, &Commit{Hash: commit.Hash, Name: name, Email: email, Date: commit.Date, Signed: commit.Signed, Subject: commit.Subject})
+		}
+
+		divideFiles(authors, commit.Files, mode)
+		credited = append(credited, authors...)
+	}
+	return credited
+}
+
+// divideFiles gives each of the authors' commits the changed files. With
+// mode "split" the lines are divided evenly between them, otherwise each
+// gets all of them.
+func divideFiles(authors []*Commit, files []FileChange, mode string) {
+	for i, author := range authors {
+		author.Files = make([]FileChange, len(files))
+		for j, change := range files {
+			if mode == "split" && !change.Binary {
+				change.Additions = splitLines(change.Additions, len(authors), i)
+				change.Deletions = splitLines(change.Deletions, len(authors), i)
+			}
+			author.Files[j] = change
+		}
+	}
+}
+
+// hasAuthor reports whether one of the commits is by the given email, or
+// by the given name when the email is empty
+func hasAuthor(commits []*Commit, name string, email string) bool {
+	for _, commit := range commits {
+		if email != "" && strings.EqualFold(commit.Email, email) || email == "" && commit.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// splitLines returns the share of n lines for author i of count authors.
+// The remainder goes to the first authors so the shares add up to n.
+func splitLines(n int, count int, i int) int {
+	share := n / count
+	if i < n%count {
+		share++
+	}
+	return share
+}
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/PerArneng/gitwho/pkg/gitwho"
+	"github.com/spf13/cobra"
+)
+
+var codeownersThreshold float64
+var codeownersMaxOwners int
+var codeownersDepth int
+var codeownersOutput string
+
+// codeownersCmd represents the codeowners command
+var codeownersCmd = &cobra.Command{
+	Use:   "codeowners",
+	Short: "Generate and check CODEOWNERS files",
+	Long: `Codeowners works with GitHub-style CODEOWNERS files, which map path
+patterns to the people responsible for them, based on the commit history.`,
+}
+
+// codeownersGenerateCmd represents the codeowners generate command
+var codeownersGenerateCmd = &cobra.Command{
+	Use:   "generate [directory]",
+	Short: "Draft a CODEOWNERS file from the commit history",
+	Long: `Generate walks the directory and its subdirectories down to --depth
+levels and lists, for each of them, the contributors who made at least
+--threshold percent of its changes as owners. Subdirectories with the same
+owners as their parent are left out, since the parent's rule already
+covers them.
+
+Owners are written as emails, which GitHub accepts for users whose email
+is verified. With --group-by team the team names are used, so name the
+teams in the config file after their GitHub teams, e.g. @org/platform.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := "."
+		if len(args) == 1 {
+			dir = args[0]
+		}
+		if codeownersThreshold <= 0 || codeownersThreshold > 100 {
+			fmt.Println("Error: --threshold must be between 0 and 100")
+			os.Exit(1)
+		}
+		if codeownersDepth < 0 {
+			fmt.Println("Error: --depth must not be negative")
+			os.Exit(1)
+		}
+
+		commits, err := collectCommits([]string{dir}, lastTimeRange, repoPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		repo := repoPath
+		if repo == "" {
+			if repo, err = findRepoForPath(dir); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+		base, err := getRelativePath(dir, repo)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		w := io.Writer(os.Stdout)
+		if codeownersOutput != "" {
+			file, err := os.Create(codeownersOutput)
+			if err != nil {
+				fmt.Printf("Error creating %s: %v\n", codeownersOutput, err)
+				os.Exit(1)
+			}
+			defer file.Close()
+			w = file
+		}
+
+		rules := generateCodeowners(commits, base, codeownersDepth, codeownersThreshold, codeownersMaxOwners)
+		if err := writeCodeowners(w, rules); err != nil {
+			fmt.Printf("Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+		if codeownersOutput != "" {
+			infof("Wrote %d rules to %s\n", len(rules), codeownersOutput)
+		}
+	},
+}
+
+func init() {
+	codeownersGenerateCmd.Flags().Float64Var(&codeownersThreshold, "threshold", 25, "Percentage of a directory's changes a contributor needs to own it")
+	codeownersGenerateCmd.Flags().IntVar(&codeownersMaxOwners, "max-owners", 3, "Maximum number of owners per directory (0 for no limit)")
+	codeownersGenerateCmd.Flags().IntVar(&codeownersDepth, "depth", 2, "Number of subdirectory levels to write rules for")
+	codeownersGenerateCmd.Flags().StringVarP(&codeownersOutput, "output", "o", "", "Write the CODEOWNERS file to a file instead of stdout")
+	codeownersCmd.AddCommand(codeownersGenerateCmd)
+	rootCmd.AddCommand(codeownersCmd)
+}
+
+// codeownersRule is one line of a CODEOWNERS file
+type codeownersRule struct {
+	Pattern string
+	Owners  []string
+}
+
+// codeownersPattern returns the CODEOWNERS pattern of a directory relative
+// to the repository root
+func codeownersPattern(dir string) string {
+	if dir == "." {
+		return "*"
+	}
+	return "/" + dir + "/"
+}
+
+// ownerHandle returns how a contributor is written as a code owner
+func ownerHandle(name string, email string) string {
+	if email == "" {
+		return name
+	}
+	return email
+}
+
+// generateCodeowners computes the owners of base and its subdirectories
+// down to maxDepth levels. Directories without an owner above threshold,
+// or with the same owners as their closest listed parent, get no rule.
+func generateCodeowners(commits []*Commit, base string, maxDepth int, threshold float64, maxOwners int) []codeownersRule {
+	owners := make(map[string][]string)
+	var dirs []string
+	for depth := 0; depth <= maxDepth; depth++ {
+		groups := splitCommits(commits, func(file string) string {
+			return treeKey(file, base, depth)
+		})
+		for dir, dirCommits := range groups {
+			if dir == "" {
+				continue
+			}
+			contributors := gitwho.Aggregate(dirCommits)
+			gitwho.ComputeShares(contributors)
+			sortContributorsBy(contributors, "total", false)
+			for _, contributor := range contributors {
+				if contributor.ChangeShare < threshold || maxOwners > 0 && len(owners[dir]) == maxOwners {
+					break
+				}
+				owners[dir] = append(owners[dir], ownerHandle(contributor.Name, contributor.Email))
+			}
+			dirs = append(dirs, dir)
+		}
+	}
+
+	// Parents come before their subdirectories, so the more specific rules
+	// further down the file take precedence
+	slices.SortFunc(dirs, func(a, b string) int {
+		return slices.Compare(strings.Split(a, "/"), strings.Split(b, "/"))
+	})
+
+	var rules []codeownersRule
+	listed := make(map[string][]string)
+	for _, dir := range dirs {
+		if len(owners[dir]) == 0 || slices.Equal(inheritedOwners(dir, base, listed), owners[dir]) {
+			continue
+		}
+		listed[dir] = owners[dir]
+		rules = append(rules, codeownersRule{Pattern: codeownersPattern(dir), Owners: owners[dir]})
+	}
+	return rules
+}
+
+// inheritedOwners returns the owners of the closest parent of dir, up to
+// base, that has a rule
+func inheritedOwners(dir string, base string, listed map[string][]string) []string {
+	for dir != base && dir != "." {
+		dir = path.Dir(dir)
+		if owners, ok := listed[dir]; ok {
+			return owners
+		}
+	}
+	return nil
+}
+
+// writeCodeowners writes the rules in CODEOWNERS syntax
+func writeCodeowners(w io.Writer, rules []codeownersRule) error {
+	if _, err := fmt.Fprintln(w, "# Generated by gitwho codeowners generate. Review before committing."); err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if _, err := fmt.Fprintf(w, "%s %s\n", rule.Pattern, strings.Join(rule.Owners, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,320 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/PerArneng/gitwho/pkg/gitwho"
+	"github.com/spf13/cobra"
+)
+
+// codeownersLocations are the places GitHub looks for a CODEOWNERS file,
+// relative to the repository root, in order
+var codeownersLocations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// defaultCheckWindow is the history checked when no time range is given
+const defaultCheckWindow = "1y"
+
+var codeownersMinCommits int
+var codeownersFormat string
+
+// codeownersCheckCmd represents the codeowners check command
+var codeownersCheckCmd = &cobra.Command{
+	Use:   "check [CODEOWNERS file]",
+	Short: "Flag CODEOWNERS entries whose owners no longer work on the code",
+	Long: `Check reads a CODEOWNERS file, by default the one GitHub would use, and
+counts the recent commits each listed owner made to the files the entry
+governs. As in CODEOWNERS, a file is governed by the last entry matching
+it. Owners with fewer than --min-commits commits are flagged as stale and
+the command exits with status 1, so it can run in CI.
+
+Owners given as emails are matched against commit emails. @user handles
+are matched against GitHub noreply emails and the identities with that
+github handle in the aliases of the config file, and @org/team handles
+against the members of the team with that name in the config file.
+Handles that cannot be mapped to any commit author are reported as
+unverifiable rather than stale. The history of the last year is checked
+unless --last, --since or --until is given.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !isValidTableFormat(codeownersFormat) {
+			fmt.Printf("Error: Invalid format %s. Supported formats: table, csv, json\n", codeownersFormat)
+			os.Exit(1)
+		}
+
+		repo := repoPath
+		if repo == "" {
+			var err error
+			if repo, err = findRepoForPath("."); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+		root, err := findGitRoot(repo)
+		if err != nil {
+			fmt.Printf("Error finding git root: %v\n", err)
+			os.Exit(1)
+		}
+
+		file := ""
+		if len(args) == 1 {
+			file = args[0]
+		} else if file = findCodeowners(root); file == "" {
+			fmt.Printf("Error: no CODEOWNERS file found in %s\n", root)
+			os.Exit(1)
+		}
+		rules, err := readCodeowners(file)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		timeRange := lastTimeRange
+		if timeRange == "" && sinceDate == "" && untilDate == "" {
+			timeRange = defaultCheckWindow
+		}
+		commits, err := collectCommits([]string{root}, timeRange, root)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		config, err := loadConfig()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		checks, err := checkCodeowners(rules, commits, config, codeownersMinCommits)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := displayTable(os.Stdout, codeownersFormat, ownerCheckTable(checks), checks); err != nil {
+			fmt.Printf("Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+
+		stale, unverifiable := 0, 0
+		for _, check := range checks {
+			if check.Stale {
+				stale++
+			}
+			if check.Unverifiable {
+				unverifiable++
+			}
+		}
+		if unverifiable > 0 {
+			infof("%s in %s, map their handles with github in the aliases of the config file\n", pluralize(unverifiable, "unverifiable owner"), file)
+		}
+		if stale > 0 {
+			infof("%s in %s\n", pluralize(stale, "stale owner"), file)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	codeownersCheckCmd.Flags().IntVar(&codeownersMinCommits, "min-commits", 1, "Commits an owner needs in the governed files to not be stale")
+	codeownersCheckCmd.Flags().StringVar(&codeownersFormat, "format", "table", "Output format (table, csv, json)")
+	codeownersCmd.AddCommand(codeownersCheckCmd)
+}
+
+// ownerCheck is the recent activity of one owner of a CODEOWNERS entry
+type ownerCheck struct {
+	Line         int    `json:"line"`
+	Pattern      string `json:"pattern"`
+	Owner        string `json:"owner"`
+	Commits      int    `json:"commits"`
+	Stale        bool   `json:"stale"`
+	Unverifiable bool   `json:"unverifiable"`
+}
+
+// findCodeowners returns the CODEOWNERS file GitHub would use for the
+// repository, or "" when there is none
+func findCodeowners(root string) string {
+	for _, location := range codeownersLocations {
+		file := filepath.Join(root, location)
+		if isRegularFile(file) {
+			return file
+		}
+	}
+	return ""
+}
+
+// codeownersEntry is a parsed line of a CODEOWNERS file
+type codeownersEntry struct {
+	codeownersRule
+	Line int
+}
+
+// readCodeowners parses the entries of a CODEOWNERS file, skipping blank
+// lines and comments
+func readCodeowners(file string) ([]codeownersEntry, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading CODEOWNERS: %v", err)
+	}
+	defer f.Close()
+
+	var entries []codeownersEntry
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		entries = append(entries, codeownersEntry{codeownersRule{Pattern: fields[0], Owners: fields[1:]}, line})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Error reading CODEOWNERS: %v", err)
+	}
+	return entries, nil
+}
+
+// ownerMatches reports whether a CODEOWNERS owner refers to the author.
+// The author has already been mapped to their canonical identity.
+func ownerMatches(owner string, name string, email string, config *Config) bool {
+	handle, ok := strings.CutPrefix(owner, "@")
+	if !ok {
+		_, ownerEmail := canonicalIdentity("", owner, config.Aliases)
+		return strings.EqualFold(ownerEmail, email)
+	}
+	if strings.Contains(handle, "/") {
+		for _, team := range config.Teams {
+			if strings.EqualFold(strings.TrimPrefix(team.Name, "@"), handle) {
+				return teamOf(name, email, []Team{team}) != noTeam
+			}
+		}
+		return false
+	}
+	if user, isNoreply := noreplyUser(email); isNoreply && strings.EqualFold(handle, user) {
+		return true
+	}
+	for _, identity := range config.Aliases {
+		if strings.EqualFold(strings.TrimPrefix(identity.GitHub, "@"), handle) &&
+			identity.Name == name && strings.EqualFold(identity.Email, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// ownerVerifiable reports whether the owner can be told apart from someone
+// who made no commits: emails always can, a team when it is configured,
+// and a @user handle when an identity in the config has the handle or a
+// commit was made with the user's noreply email
+func ownerVerifiable(owner string, commits []*Commit, config *Config) bool {
+	handle, ok := strings.CutPrefix(owner, "@")
+	if !ok {
+		return true
+	}
+	if strings.Contains(handle, "/") {
+		return slices.ContainsFunc(config.Teams, func(team Team) bool {
+			return strings.EqualFold(strings.TrimPrefix(team.Name, "@"), handle)
+		})
+	}
+	if slices.ContainsFunc(config.Aliases, func(identity Identity) bool {
+		return strings.EqualFold(strings.TrimPrefix(identity.GitHub, "@"), handle)
+	}) {
+		return true
+	}
+	return slices.ContainsFunc(commits, func(commit *Commit) bool {
+		user, isNoreply := noreplyUser(commit.Email)
+		return isNoreply && strings.EqualFold(handle, user)
+	})
+}
+
+// checkCodeowners counts the commits each owner made to the files governed
+// by their entry and flags owners with fewer than minCommits
+func checkCodeowners(entries []codeownersEntry, commits []*Commit, config *Config, minCommits int) ([]ownerCheck, error) {
+	matchers := make([]*gitwho.PathMatcher, len(entries))
+	for i, entry := range entries {
+		m, err := newPathMatcher([]string{entry.Pattern})
+		if err != nil {
+			return nil, err
+		}
+		matchers[i] = m
+	}
+
+	// The last matching entry governs a file
+	governing := func(file string) int {
+		for i := len(entries) - 1; i >= 0; i-- {
+			if matchers[i].Match(file) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	counts := make([]map[string]int, len(entries))
+	for i := range counts {
+		counts[i] = make(map[string]int)
+	}
+	for _, commit := range commits {
+		touched := make(map[int]bool)
+		for _, change := range commit.Files {
+			if i := governing(change.Path); i >= 0 {
+				touched[i] = true
+			}
+		}
+		for i := range touched {
+			for _, owner := range entries[i].Owners {
+				if ownerMatches(owner, commit.Name, commit.Email, config) {
+					counts[i][owner]++
+				}
+			}
+		}
+	}
+
+	// Owners that cannot be matched to any author are not called stale
+	verifiable := make(map[string]bool)
+	var checks []ownerCheck
+	for i, entry := range entries {
+		for _, owner := range entry.Owners {
+			known, ok := verifiable[owner]
+			if !ok {
+				known = ownerVerifiable(owner, commits, config)
+				verifiable[owner] = known
+			}
+			checks = append(checks, ownerCheck{
+				Line:         entry.Line,
+				Pattern:      entry.Pattern,
+				Owner:        owner,
+				Commits:      counts[i][owner],
+				Stale:        known && counts[i][owner] < minCommits,
+				Unverifiable: !known,
+			})
+		}
+	}
+	return checks, nil
+}
+
+// ownerCheckTable lays out the owners of each entry and their activity
+func ownerCheckTable(checks []ownerCheck) textTable {
+	table := textTable{
+		Headers: []string{"LINE", "PATTERN", "OWNER", "COMMITS", "STATUS"},
+		Numeric: []bool{true, false, false, true, false},
+	}
+	for _, check := range checks {
+		status := "ok"
+		if check.Stale {
+			status = "stale"
+		} else if check.Unverifiable {
+			status = "unverifiable"
+		}
+		table.Rows = append(table.Rows, []string{
+			strconv.Itoa(check.Line),
+			check.Pattern,
+			check.Owner,
+			strconv.Itoa(check.Commits),
+			status,
+		})
+	}
+	return table
+}
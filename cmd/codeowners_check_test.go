@@ -0,0 +1,49 @@
+package cmd
+
+import "testing"
+
+func TestCheckCodeowners(t *testing.T) {
+	config := &Config{
+		Aliases: []Identity{{Name: "Jane Doe", Email: "jane@example.com", GitHub: "janedoe", Aliases: []string{"jane@old.example.com"}}},
+		Teams:   []Team{{Name: "@org/platform", Members: []string{"*@platform.example.com"}}},
+	}
+	commits := []*Commit{
+		{Name: "Jane Doe", Email: "jane@example.com", Files: []FileChange{{Path: "src/a.go"}}},
+		{Name: "bob", Email: "bob@platform.example.com", Files: []FileChange{{Path: "src/b.go"}}},
+		{Name: "carol", Email: "1234+carol@users.noreply.github.com", Files: []FileChange{{Path: "docs/x.md"}}},
+		// An author named like a handle is not the handle's owner
+		{Name: "ghost", Email: "ghost@example.com", Files: []FileChange{{Path: "src/c.go"}}},
+	}
+	entries := []codeownersEntry{
+		{codeownersRule{Pattern: "src/", Owners: []string{"@janedoe", "jane@old.example.com", "@org/platform", "@ghost", "@org/unknown"}}, 1},
+		{codeownersRule{Pattern: "docs/", Owners: []string{"@carol", "@janedoe"}}, 2},
+	}
+
+	checks, err := checkCodeowners(entries, commits, config, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		owner        string
+		commits      int
+		stale        bool
+		unverifiable bool
+	}{
+		{"@janedoe", 1, false, false},
+		{"jane@old.example.com", 1, false, false},
+		{"@org/platform", 1, false, false},
+		{"@ghost", 0, false, true},
+		{"@org/unknown", 0, false, true},
+		{"@carol", 1, false, false},
+		{"@janedoe", 0, true, false},
+	}
+	if len(checks) != len(want) {
+		t.Fatalf("got %d checks, want %d", len(checks), len(want))
+	}
+	for i, w := range want {
+		c := checks[i]
+		if c.Owner != w.owner || c.Commits != w.commits || c.Stale != w.stale || c.Unverifiable != w.unverifiable {
+			t.Errorf("check %d = %+v, want %+v", i, c, w)
+		}
+	}
+}
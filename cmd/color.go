@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// ANSI escape sequences used by the table output
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+)
+
+var colorMode string
+
+// resolveColor decides whether to color the output written to w. In auto
+// mode color is used when w is a terminal and NO_COLOR is not set.
+func resolveColor(mode string, w io.Writer) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto":
+		if os.Getenv("NO_COLOR") != "" {
+			return false, nil
+		}
+		return isTerminal(w), nil
+	}
+	return false, fmt.Errorf("Error: invalid color mode %s, expected auto, always or never", mode)
+}
+
+// colorize wraps s in the given ANSI code when color is enabled
+func colorize(s string, code string, enabled bool) string {
+	if !enabled || code == "" {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+// isTerminal reports whether w is a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(interface{ Fd() uintptr })
+	return ok && term.IsTerminal(int(f.Fd()))
+}
@@ -0,0 +1,323 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+	"golang.org/x/term"
+)
+
+// column describes one field of the tabular output formats
+type column struct {
+	Name    string // identifier used in csv/tsv headers
+	Header  string // header in the table output
+	Title   string // header in the markdown output
+	Width   int    // width in the table output
+	Numeric bool   // numeric columns are right-aligned
+	Color   string // ANSI color of the values in the table output
+	Value   func(record contributorRecord) string
+	Count   func(record contributorRecord) int // set for integer count columns
+}
+
+var (
+	nameColumn = column{
+		Name: "name", Header: "NAME", Title: "Name", Width: 30,
+		Value: func(r contributorRecord) string { return r.Name },
+	}
+	emailColumn = column{
+		Name: "email", Header: "EMAIL", Title: "Email", Width: 30,
+		Value: func(r contributorRecord) string { return r.Email },
+	}
+	commitsColumn = column{
+		Name: "commits", Header: "COMMITS", Title: "Commits", Width: 10, Numeric: true,
+		Count: func(r contributorRecord) int { return r.Commits },
+	}
+	filesColumn = column{
+		Name: "files", Header: "FILES", Title: "Files", Width: 8, Numeric: true,
+		Count: func(r contributorRecord) int { return r.Files },
+	}
+	binaryColumn = column{
+		Name: "binary", Header: "BINARY", Title: "Binary", Width: 8, Numeric: true,
+		Count: func(r contributorRecord) int { return r.Binary },
+	}
+	additionsColumn = column{
+		Name: "additions", Header: "ADDED", Title: "Added", Width: 10, Numeric: true, Color: ansiGreen,
+		Count: func(r contributorRecord) int { return r.Additions },
+	}
+	deletionsColumn = column{
+		Name: "deletions", Header: "DELETED", Title: "Deleted", Width: 10, Numeric: true, Color: ansiRed,
+		Count: func(r contributorRecord) int { return r.Deletions },
+	}
+	totalColumn = column{
+		Name: "total", Header: "TOTAL", Title: "Total", Width: 10, Numeric: true,
+		Count: func(r contributorRecord) int { return r.Total },
+	}
+	avgColumn = column{
+		Name: "avg", Header: "AVG", Title: "Avg", Width: 8, Numeric: true,
+		Value: func(r contributorRecord) string { return formatDecimal(r.AvgCommitSize) },
+	}
+	medianColumn = column{
+		Name: "median", Header: "MEDIAN", Title: "Median", Width: 8, Numeric: true,
+		Value: func(r contributorRecord) string { return formatDecimal(r.MedianCommitSize) },
+	}
+	firstColumn = column{
+		Name: "first", Header: "FIRST", Title: "First", Width: 10,
+		Value: func(r contributorRecord) string { return formatDate(r.FirstCommit) },
+	}
+	lastColumn = column{
+		Name: "last", Header: "LAST", Title: "Last", Width: 10,
+		Value: func(r contributorRecord) string { return formatDate(r.LastCommit) },
+	}
+	activeDaysColumn = column{
+		Name: "active_days", Header: "ACTIVE_DAYS", Title: "Active days", Width: 11, Numeric: true,
+		Count: func(r contributorRecord) int { return r.ActiveDays },
+	}
+	tenureColumn = column{
+		Name: "tenure", Header: "TENURE", Title: "Tenure", Width: 8, Numeric: true,
+		Value: func(r contributorRecord) string { return formatTenure(r.FirstCommit, r.LastCommit) },
+	}
+	commitShareColumn = column{
+		Name: "commit_share", Header: "COMMITS%", Title: "Commits %", Width: 10, Numeric: true,
+		Value: func(r contributorRecord) string { return formatPercent(r.CommitShare) },
+	}
+	changeShareColumn = column{
+		Name: "change_share", Header: "CHANGES%", Title: "Changes %", Width: 10, Numeric: true,
+		Value: func(r contributorRecord) string { return formatPercent(r.ChangeShare) },
+	}
+)
+
+// text returns the value of the column for a record. Count columns are
+// humanized according to mode (see formatCount).
+func (c column) text(record contributorRecord, mode string) string {
+	if c.Count != nil {
+		return formatCount(c.Count(record), mode)
+	}
+	return c.Value(record)
+}
+
+// allColumns lists every column that can be picked with --columns
+func allColumns() []column {
+	return []column{
+		nameColumn, emailColumn, githubColumn, commitsColumn, filesColumn, binaryColumn, additionsColumn, deletionsColumn, totalColumn,
+		avgColumn, medianColumn, firstColumn, lastColumn, tenureColumn, activeDaysColumn,
+		commitShareColumn, changeShareColumn, signedColumn, activityColumn(),
+	}
+}
+
+// parseColumns resolves a comma-separated list of column names
+func parseColumns(list string) ([]column, error) {
+	available := allColumns()
+	var columns []column
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		i := slices.IndexFunc(available, func(col column) bool { return col.Name == name })
+		if i < 0 {
+			names := make([]string, len(available))
+			for j, col := range available {
+				names[j] = col.Name
+			}
+			return nil, fmt.Errorf("Error: unknown column %q, available columns: %s", name, strings.Join(names, ", "))
+		}
+		columns = append(columns, available[i])
+	}
+	return columns, nil
+}
+
+// activeColumns returns the columns to render based on the output flags.
+// An explicit --columns list takes precedence over the other flags.
+func activeColumns() []column {
+	if columnList != "" {
+		// The list was validated before the analysis started
+		columns, _ := parseColumns(columnList)
+		return columns
+	}
+
+	columns := []column{nameColumn, emailColumn}
+	if enrichMode == "github" {
+		columns = append(columns, githubColumn)
+	}
+	columns = append(columns, commitsColumn, filesColumn, additionsColumn, deletionsColumn, totalColumn)
+	if showExtended {
+		columns = append(columns, binaryColumn, avgColumn, medianColumn, firstColumn, lastColumn, tenureColumn, activeDaysColumn)
+	}
+	if showPercent {
+		columns = append(columns, commitShareColumn, changeShareColumn)
+	}
+	if showSigned {
+		columns = append(columns, signedColumn)
+	}
+	if showActivity {
+		columns = append(columns, activityColumn())
+	}
+	return columns
+}
+
+// formatPercent formats a percentage with one decimal
+func formatPercent(value float64) string {
+	return fmt.Sprintf("%.1f%%", value)
+}
+
+// formatCount formats an integer count. Mode "separators" groups thousands
+// (1,234,567), "compact" uses suffixes (1.2M) and anything else prints the
+// plain number.
+func formatCount(n int, mode string) string {
+	switch mode {
+	case "separators":
+		return groupThousands(n)
+	case "compact":
+		return compactCount(n)
+	}
+	return strconv.Itoa(n)
+}
+
+// groupThousands formats n with commas between groups of three digits
+func groupThousands(n int) string {
+	digits := strconv.Itoa(n)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	var b strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	return sign + b.String()
+}
+
+// compactCount formats n with a k, M or B suffix once it reaches a thousand
+func compactCount(n int) string {
+	units := []struct {
+		size   float64
+		suffix string
+	}{
+		{1e9, "B"},
+		{1e6, "M"},
+		{1e3, "k"},
+	}
+
+	value := float64(n)
+	for _, unit := range units {
+		if math.Abs(value) >= unit.size {
+			return strconv.FormatFloat(value/unit.size, 'f', 1, 64) + unit.suffix
+		}
+	}
+	return strconv.Itoa(n)
+}
+
+// formatDecimal formats a number with one decimal
+func formatDecimal(value float64) string {
+	return strconv.FormatFloat(value, 'f', 1, 64)
+}
+
+// formatDate formats a commit date as YYYY-MM-DD, or "-" when unknown
+func formatDate(date time.Time) string {
+	if date.IsZero() {
+		return "-"
+	}
+	return date.Format("2006-01-02")
+}
+
+// formatTenure formats the time between two dates as years and months
+// ("2y 3m"), months and days ("3m 12d") or days ("5d")
+func formatTenure(first, last time.Time) string {
+	if first.IsZero() || last.IsZero() {
+		return "-"
+	}
+
+	years, months, days := calendarDiff(first.UTC(), last.UTC())
+	switch {
+	case years > 0:
+		return fmt.Sprintf("%dy %dm", years, months)
+	case months > 0:
+		return fmt.Sprintf("%dm %dd", months, days)
+	default:
+		return fmt.Sprintf("%dd", days)
+	}
+}
+
+// calendarDiff returns the number of whole years, months and days from
+// first to last
+func calendarDiff(first, last time.Time) (years, months, days int) {
+	years = last.Year() - first.Year()
+	months = int(last.Month()) - int(first.Month())
+	days = last.Day() - first.Day()
+
+	if days < 0 {
+		// Borrow the length of the month before last's month
+		months--
+		days += time.Date(last.Year(), last.Month(), 0, 0, 0, 0, 0, time.UTC).Day()
+	}
+	if months < 0 {
+		years--
+		months += 12
+	}
+	return years, months, days
+}
+
+// minTextWidth is the narrowest a text column is shrunk to
+const minTextWidth = 10
+
+// layoutColumns sizes the text columns of the table for the records, with
+// numbers formatted as humanize selects. With wide set, text columns are
+// as wide as their longest value. On a terminal of termWidth columns they
+// are sized to the content and shrunk to fit. Otherwise (termWidth 0) the
+// default widths are kept.
+func layoutColumns(columns []column, records []contributorRecord, wide bool, termWidth int, humanize string) []column {
+	if !wide && termWidth == 0 {
+		return columns
+	}
+
+	laid := slices.Clone(columns)
+	for i, col := range laid {
+		if col.Numeric {
+			continue
+		}
+		width := runewidth.StringWidth(col.Header)
+		for _, record := range records {
+			width = max(width, runewidth.StringWidth(col.text(record, humanize)))
+		}
+		laid[i].Width = width
+	}
+	if wide {
+		return laid
+	}
+
+	// Shrink the widest text column until the table fits the terminal
+	for tableWidth(laid) > termWidth {
+		widest := -1
+		for i, col := range laid {
+			if !col.Numeric && col.Width > minTextWidth && (widest < 0 || col.Width > laid[widest].Width) {
+				widest = i
+			}
+		}
+		if widest < 0 {
+			break
+		}
+		laid[widest].Width--
+	}
+	return laid
+}
+
+// terminalWidth returns the width of the terminal w writes to, or 0 when
+// w is not a terminal
+func terminalWidth(w io.Writer) int {
+	if !isTerminal(w) {
+		return 0
+	}
+	fd := int(w.(interface{ Fd() uintptr }).Fd())
+	width, _, err := term.GetSize(fd)
+	if err != nil {
+		return 0
+	}
+	return width
+}
@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PerArneng/gitwho/pkg/gitwho"
+	"github.com/spf13/cobra"
+)
+
+var compareAgainst string
+var againstSince string
+var againstUntil string
+var compareFormat string
+
+// compareCmd represents the compare command
+var compareCmd = &cobra.Command{
+	Use:   "compare [file/directory]",
+	Short: "Compare contributor statistics between two time windows",
+	Long: `Compare shows how each contributor's commits and changed lines differ
+between two time windows, to see who ramped up and who dropped off.
+
+The current window is set with --last, or with --since and --until. The
+window to compare against defaults to the one right before it. Use
+--against previous-<duration>, e.g. previous-month or previous-90d, to
+pick its length, or --against-since and --against-until to give it
+explicitly. Dates are YYYY-MM-DD, and --until dates are included.`,
+	Example: `  gitwho compare --last month --against previous-month src/
+  gitwho compare --since 2025-04-01 --until 2025-06-30 --against-since 2025-01-01 --against-until 2025-03-31`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+		if !isValidTableFormat(compareFormat) {
+			fmt.Printf("Error: Invalid format %s. Supported formats: table, csv, json\n", compareFormat)
+			os.Exit(1)
+		}
+
+		current, previous, err := compareWindows(time.Now())
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		// Both windows are cut from the full history, so git log must not
+		// be limited to the current one
+		sinceDate, untilDate = "", ""
+		commits, err := collectCommits([]string{path}, "", repoPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		deltas := buildDeltas(
+			gitwho.Aggregate(current.filter(commits)),
+			gitwho.Aggregate(previous.filter(commits)))
+		if compareFormat == "table" {
+			fmt.Printf("Comparing %s with %s\n\n", current, previous)
+		}
+		if err := displayTable(os.Stdout, compareFormat, deltaTable(deltas), deltas); err != nil {
+			fmt.Printf("Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	compareCmd.Flags().StringVar(&compareAgainst, "against", "previous", "Window to compare against (previous or previous-<duration>, e.g. previous-month or previous-90d)")
+	compareCmd.Flags().StringVar(&againstSince, "against-since", "", "Start date of the window to compare against")
+	compareCmd.Flags().StringVar(&againstUntil, "against-until", "", "End date of the window to compare against")
+	compareCmd.Flags().StringVar(&compareFormat, "format", "table", "Output format (table, csv, json)")
+	rootCmd.AddCommand(compareCmd)
+}
+
+// timeWindow is the half-open interval of commit dates [Since, Until)
+type timeWindow struct {
+	Since time.Time
+	Until time.Time
+}
+
+// String formats the window with its first and last day
+func (w timeWindow) String() string {
+	return fmt.Sprintf("%s to %s", w.Since.Format("2006-01-02"), w.Until.Add(-time.Nanosecond).Format("2006-01-02"))
+}
+
+// filter returns the commits made within the window
+func (w timeWindow) filter(commits []*Commit) []*Commit {
+	var filtered []*Commit
+	for _, commit := range commits {
+		if !commit.Date.Before(w.Since) && commit.Date.Before(w.Until) {
+			filtered = append(filtered, commit)
+		}
+	}
+	return filtered
+}
+
+// parseWindow parses the since and until dates of a window. An empty
+// until means now. The until date is included in the window.
+func parseWindow(since string, until string, now time.Time) (timeWindow, error) {
+	window := timeWindow{Until: now}
+	start, err := time.ParseInLocation("2006-01-02", since, time.Local)
+	if err != nil {
+		return window, fmt.Errorf("Error: invalid date %q, expected YYYY-MM-DD", since)
+	}
+	window.Since = start
+	if until != "" {
+		end, err := time.ParseInLocation("2006-01-02", until, time.Local)
+		if err != nil {
+			return window, fmt.Errorf("Error: invalid date %q, expected YYYY-MM-DD", until)
+		}
+		window.Until = end.AddDate(0, 0, 1)
+	}
+	if !window.Since.Before(window.Until) {
+		return window, fmt.Errorf("Error: the window %s ends before it starts", window)
+	}
+	return window, nil
+}
+
+// compareWindows resolves the current window and the one to compare
+// against from the flags
+func compareWindows(now time.Time) (timeWindow, timeWindow, error) {
+	var current, previous timeWindow
+	var err error
+
+	switch {
+	case sinceDate != "":
+		if current, err = parseWindow(sinceDate, untilDate, now); err != nil {
+			return current, previous, err
+		}
+	case lastTimeRange != "":
+		since, until, err := timeRangeWindow(lastTimeRange, now)
+		if err != nil {
+			return current, previous, err
+		}
+		current = timeWindow{Since: since, Until: until}
+	default:
+		return current, previous, fmt.Errorf("Error: set the window to compare with --last or --since")
+	}
+
+	if againstSince != "" {
+		previous, err = parseWindow(againstSince, againstUntil, now)
+		return current, previous, err
+	}
+
+	unit, ok := strings.CutPrefix(compareAgainst, "previous")
+	unit = strings.TrimPrefix(unit, "-")
+	if !ok {
+		return current, previous, fmt.Errorf("Error: invalid --against %s, expected previous or previous-<duration>", compareAgainst)
+	}
+	if unit == "" && sinceDate == "" {
+		unit = lastTimeRange
+	}
+
+	previous.Until = current.Since
+	if unit == "" {
+		// A window of the same length right before the current one
+		previous.Since = current.Since.Add(-current.Until.Sub(current.Since))
+	} else if previous.Since, _, err = timeRangeWindow(unit, current.Since); err != nil {
+		return current, previous, fmt.Errorf("Error: invalid --against %s, expected previous or previous-<duration>", compareAgainst)
+	}
+	return current, previous, nil
+}
+
+// contributorDelta compares a contributor's statistics in two windows
+type contributorDelta struct {
+	Name            string `json:"name"`
+	Email           string `json:"email"`
+	Commits         int    `json:"commits"`
+	PreviousCommits int    `json:"previousCommits"`
+	Total           int    `json:"total"`
+	PreviousTotal   int    `json:"previousTotal"`
+	CommitsDelta    int    `json:"commitsDelta"`
+	TotalDelta      int    `json:"totalDelta"`
+	Status          string `json:"status"`
+}
+
+// buildDeltas matches the contributors of both windows. Contributors who
+// ramped up the most come first and those who dropped off the most last.
+func buildDeltas(current []*Contributor, previous []*Contributor) []*contributorDelta {
+	deltas := make(map[string]*contributorDelta)
+	deltaFor := func(contributor *Contributor) *contributorDelta {
+		key := contributor.Name + "|" + contributor.Email
+		delta, exists := deltas[key]
+		if !exists {
+			delta = &contributorDelta{Name: contributor.Name, Email: contributor.Email}
+			deltas[key] = delta
+		}
+		return delta
+	}
+	for _, contributor := range current {
+		delta := deltaFor(contributor)
+		delta.Commits = contributor.Commits
+		delta.Total = contributor.Additions + contributor.Deletions
+	}
+	for _, contributor := range previous {
+		delta := deltaFor(contributor)
+		delta.PreviousCommits = contributor.Commits
+		delta.PreviousTotal = contributor.Additions + contributor.Deletions
+	}
+
+	result := make([]*contributorDelta, 0, len(deltas))
+	for _, delta := range deltas {
+		delta.CommitsDelta = delta.Commits - delta.PreviousCommits
+		delta.TotalDelta = delta.Total - delta.PreviousTotal
+		switch {
+		case delta.PreviousCommits == 0:
+			delta.Status = "new"
+		case delta.Commits == 0:
+			delta.Status = "gone"
+		case delta.TotalDelta > 0:
+			delta.Status = "up"
+		case delta.TotalDelta < 0:
+			delta.Status = "down"
+		default:
+			delta.Status = "same"
+		}
+		result = append(result, delta)
+	}
+
+	slices.SortFunc(result, func(a, b *contributorDelta) int {
+		return cmp.Or(
+			cmp.Compare(b.TotalDelta, a.TotalDelta),
+			cmp.Compare(b.CommitsDelta, a.CommitsDelta),
+			cmp.Compare(a.Name, b.Name),
+			cmp.Compare(a.Email, b.Email))
+	})
+	return result
+}
+
+// formatDelta formats a difference with an explicit sign
+func formatDelta(n int) string {
+	if n > 0 {
+		return "+" + strconv.Itoa(n)
+	}
+	return strconv.Itoa(n)
+}
+
+// deltaTable lays out one row per contributor
+func deltaTable(deltas []*contributorDelta) textTable {
+	table := textTable{
+		Headers: []string{"NAME", "EMAIL", "COMMITS", "PREV_COMMITS", "COMMITS_DELTA", "TOTAL", "PREV_TOTAL", "TOTAL_DELTA", "STATUS"},
+		Numeric: []bool{false, false, true, true, true, true, true, true, false},
+	}
+	for _, d := range deltas {
+		table.Rows = append(table.Rows, []string{
+			d.Name,
+			d.Email,
+			strconv.Itoa(d.Commits),
+			strconv.Itoa(d.PreviousCommits),
+			formatDelta(d.CommitsDelta),
+			strconv.Itoa(d.Total),
+			strconv.Itoa(d.PreviousTotal),
+			formatDelta(d.TotalDelta),
+			d.Status,
+		})
+	}
+	return table
+}
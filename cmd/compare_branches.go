@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/PerArneng/gitwho/pkg/gitwho"
+	"github.com/spf13/cobra"
+)
+
+var compareBranchesFormat string
+
+// compareBranchesCmd represents the compare-branches command
+var compareBranchesCmd = &cobra.Command{
+	Use:   "compare-branches <base> <branch> [file/directory]",
+	Short: "Show who authored the work on a branch that is not on base yet",
+	Long: `Compare-branches analyzes the commits reachable from branch but not from
+base, as in git log base..branch, and shows who authored the work that
+exists on the branch but has not been merged into base yet.`,
+	Example: `  gitwho compare-branches main feature-x src/`,
+	Args:    cobra.RangeArgs(2, 3),
+	Run: func(cmd *cobra.Command, args []string) {
+		base, branch := args[0], args[1]
+		path := "."
+		if len(args) == 3 {
+			path = args[2]
+		}
+		if !isValidTableFormat(compareBranchesFormat) {
+			fmt.Printf("Error: Invalid format %s. Supported formats: table, csv, json\n", compareBranchesFormat)
+			os.Exit(1)
+		}
+		// The branches select the revisions, in place of the flags
+		if revisionRange != "" || sinceTag != "" || len(betweenTags) > 0 || branchRef != "" || allRefs {
+			fmt.Println("Error: compare-branches cannot be combined with --range, --since-tag, --between-tags, --branch or --all")
+			os.Exit(1)
+		}
+
+		repo := repoPath
+		if repo == "" {
+			var err error
+			if repo, err = findRepoForPath(path); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+		for _, ref := range []string{base, branch} {
+			if err := checkRevision(repo, ref); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+
+		revisionRange = base + ".." + branch
+		commits, err := collectCommits([]string{path}, lastTimeRange, repoPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		contributors := gitwho.Aggregate(commits)
+		gitwho.ComputeShares(contributors)
+		sortContributorsBy(contributors, sortField, reverseSort)
+
+		records := buildReportDocument(&Report{Contributors: contributors}, newRenderOptions(false)).Contributors
+		if compareBranchesFormat == "table" {
+			fmt.Printf("Work on %s that is not on %s: %s by %s\n\n",
+				branch, base, pluralize(len(commits), "commit"), pluralize(len(contributors), "contributor"))
+		}
+		if err := displayTable(os.Stdout, compareBranchesFormat, contributorTable(records), records); err != nil {
+			fmt.Printf("Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	compareBranchesCmd.Flags().StringVar(&compareBranchesFormat, "format", "table", "Output format (table, csv, json)")
+	rootCmd.AddCommand(compareBranchesCmd)
+}
+
+// contributorTable lays out the basic statistics of each contributor
+func contributorTable(records []contributorRecord) textTable {
+	table := textTable{
+		Headers: []string{"NAME", "EMAIL", "COMMITS", "FILES", "ADDED", "DELETED", "TOTAL", "SHARE"},
+		Numeric: []bool{false, false, true, true, true, true, true, true},
+	}
+	for _, record := range records {
+		table.Rows = append(table.Rows, []string{
+			record.Name,
+			record.Email,
+			strconv.Itoa(record.Commits),
+			strconv.Itoa(record.Files),
+			strconv.Itoa(record.Additions),
+			strconv.Itoa(record.Deletions),
+			strconv.Itoa(record.Total),
+			formatPercent(record.ChangeShare),
+		})
+	}
+	return table
+}
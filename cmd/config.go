@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var configPath string
+
+// Config is the user's gitwho configuration, read from --config or from
+// gitwho/config.yaml in the user's config directory
+type Config struct {
+	Aliases []Identity `yaml:"aliases"`
+	Teams   []Team     `yaml:"teams"`
+	Bots    []string   `yaml:"bots"`    // regexes of extra bot accounts for --exclude-bots
+	Tickets []string   `yaml:"tickets"` // regexes of issue references for the tickets command
+
+	// Forges maps repository shorthand prefixes such as gl to base URLs
+	Forges map[string]string `yaml:"forges"`
+}
+
+// Identity is a canonical contributor identity and the other names and
+// emails the contributor committed as. An alias is an email, a name, or
+// both in the form "Name <email>". GitHub is the contributor's GitHub
+// handle, which CODEOWNERS files name them by.
+type Identity struct {
+	Name    string   `yaml:"name"`
+	Email   string   `yaml:"email"`
+	GitHub  string   `yaml:"github"`
+	Aliases []string `yaml:"aliases"`
+}
+
+// Team is a named group of contributors. Members are emails or names and
+// may contain glob patterns such as *@platform.example.com.
+type Team struct {
+	Name    string   `yaml:"name"`
+	Members []string `yaml:"members"`
+}
+
+// noTeam is the group of contributors that are not a member of any team
+const noTeam = "(no team)"
+
+// defaultConfigPath returns the path of the configuration file in the
+// user's config directory
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "gitwho", "config.yaml")
+}
+
+// loadConfig reads the configuration file. A missing default file yields
+// an empty configuration, a missing --config file is an error.
+func loadConfig() (*Config, error) {
+	path := configPath
+	if path == "" {
+		path = defaultConfigPath()
+		if _, err := os.Stat(path); path == "" || os.IsNotExist(err) {
+			return &Config{}, nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading config: %v", err)
+	}
+	config := &Config{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("Error parsing config %s: %v", path, err)
+	}
+	debugf("Loaded config from %s\n", path)
+	return config, nil
+}
+
+// memberMatches reports whether a team member entry refers to the author
+// name and email. Entries with an @ are matched against the email, others
+// against the name, ignoring case.
+func memberMatches(member string, name string, email string) bool {
+	member = strings.ToLower(strings.TrimSpace(member))
+	value := strings.ToLower(name)
+	if strings.Contains(member, "@") {
+		value = strings.ToLower(email)
+	}
+	matched, err := path.Match(member, value)
+	return err == nil && matched
+}
+
+// teamOf returns the name of the first team the author is a member of
+func teamOf(name string, email string, teams []Team) string {
+	for _, team := range teams {
+		for _, member := range team.Members {
+			if memberMatches(member, name, email) {
+				return team.Name
+			}
+		}
+	}
+	return noTeam
+}
+
+// groupByTeam replaces the author of each commit with their team, so the
+// statistics are aggregated per team
+func groupByTeam(commits []*Commit, teams []Team) []*Commit {
+	for _, commit := range commits {
+		commit.Name, commit.Email = teamOf(commit.Name, commit.Email, teams), ""
+	}
+	return commits
+}
+
+// aliasMatches reports whether an alias refers to the author name and email
+func aliasMatches(alias string, name string, email string) bool {
+	alias = strings.TrimSpace(alias)
+	if aliasName, aliasEmail, ok := strings.Cut(alias, "<"); ok {
+		aliasEmail = strings.TrimSuffix(aliasEmail, ">")
+		aliasName = strings.TrimSpace(aliasName)
+		return strings.EqualFold(aliasEmail, email) && (aliasName == "" || aliasName == name)
+	}
+	if strings.Contains(alias, "@") {
+		return strings.EqualFold(alias, email)
+	}
+	return alias == name
+}
+
+// canonicalIdentity returns the canonical name and email for an author, or
+// the author itself when no alias matches
+func canonicalIdentity(name string, email string, identities []Identity) (string, string) {
+	for _, identity := range identities {
+		for _, alias := range identity.Aliases {
+			if aliasMatches(alias, name, email) {
+				return identity.Name, identity.Email
+			}
+		}
+	}
+	return name, email
+}
+
+// applyAliases rewrites the authors of the commits that match an alias to
+// the canonical identity
+func applyAliases(commits []*Commit, identities []Identity) []*Commit {
+	for _, commit := range commits {
+		commit.Name, commit.Email = canonicalIdentity(commit.Name, commit.Email, identities)
+	}
+	return commits
+}
@@ -0,0 +1,310 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+var enrichMode string
+
+// githubAPIEnv overrides the GitHub API URL, e.g. for GitHub Enterprise.
+// GitHub Actions sets it to the API of the instance the workflow runs on.
+const githubAPIEnv = "GITHUB_API_URL"
+
+// githubTokenEnv is the token GitHub Actions and the gh CLI use. It is the
+// only token sent to the GitHub API: --token and GITWHO_TOKEN are meant
+// for cloning and may belong to another host.
+const githubTokenEnv = "GITHUB_TOKEN"
+
+// githubTimeout limits each request to the GitHub API
+const githubTimeout = 10 * time.Second
+
+// githubMaxWait is the longest wait for a rate limit to reset before a
+// lookup gives up. The search API resets every minute.
+const githubMaxWait = 70 * time.Second
+
+// githubCacheTTL is how long cached lookups are used, and githubMissTTL
+// how long lookups that found nothing are, so accounts that made their
+// email public later are found
+const (
+	githubCacheTTL = 30 * 24 * time.Hour
+	githubMissTTL  = 24 * time.Hour
+)
+
+// profileCache is the cache file of the GitHub accounts by email
+const profileCache = "github.json"
+
+// errRateLimited is returned once the GitHub API refuses further requests
+var errRateLimited = errors.New("GitHub API rate limit exceeded")
+
+// errNotFound is returned for API paths that do not exist
+var errNotFound = errors.New("not found")
+
+// githubProfile is the GitHub account behind a commit email
+type githubProfile struct {
+	Login     string `json:"login"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+// isValidEnrichMode reports whether mode is a supported --enrich value
+func isValidEnrichMode(mode string) bool {
+	return mode == "" || mode == "github"
+}
+
+// githubColumn shows the GitHub handle resolved with --enrich github
+var githubColumn = column{
+	Name: "github", Header: "GITHUB", Title: "GitHub", Width: 20,
+	Value: func(r contributorRecord) string {
+		if r.GitHub == "" {
+			return "-"
+		}
+		return "@" + r.GitHub
+	},
+}
+
+// enrichGitHub looks up the GitHub account of each contributor by email.
+// Lookups are cached on disk, misses for a shorter time, so later runs do
+// not spend the API's rate limit again. Contributors that cannot be
+// resolved keep an empty login.
+func enrichGitHub(contributors []*Contributor) error {
+	cache := loadGitHubCache(profileCache)
+	client := &http.Client{Timeout: githubTimeout}
+
+	var lookupErr error
+	unresolved := 0
+	for _, contributor := range contributors {
+		if contributor.Email == "" {
+			continue
+		}
+		var profile githubProfile
+		ok := cache.get(contributor.Email, &profile)
+		if !ok && lookupErr == nil {
+			profile, lookupErr = lookupGitHub(client, contributor.Email)
+			if lookupErr == nil {
+				cache.put(contributor.Email, profile, profile.Login == "")
+				ok = true
+			}
+		}
+		if !ok {
+			unresolved++
+			continue
+		}
+		contributor.GitHubLogin = profile.Login
+		contributor.AvatarURL = profile.AvatarURL
+	}
+
+	if err := cache.save(); err != nil {
+		debugf("Not caching GitHub profiles: %v\n", err)
+	}
+	if lookupErr != nil {
+		return fmt.Errorf("Error looking up %s on GitHub: %v", pluralize(unresolved, "contributor"), lookupErr)
+	}
+	return nil
+}
+
+// lookupGitHub finds the GitHub account of an email. Noreply addresses
+// carry the login already; other emails are matched against the public
+// emails of users, then against the authors of public commits. An email
+// without a match returns an empty profile.
+func lookupGitHub(client *http.Client, email string) (githubProfile, error) {
+	if user, ok := noreplyUser(email); ok {
+		return githubProfile{Login: user, AvatarURL: "https://github.com/" + user + ".png"}, nil
+	}
+
+	var users struct {
+		Items []githubProfile `json:"items"`
+	}
+	if err := githubGet(client, "/search/users?q="+url.QueryEscape(email+" in:email"), &users); err != nil {
+		return githubProfile{}, err
+	}
+	if len(users.Items) > 0 {
+		return users.Items[0], nil
+	}
+
+	var commits struct {
+		Items []struct {
+			Author *githubProfile `json:"author"`
+		} `json:"items"`
+	}
+	if err := githubGet(client, "/search/commits?per_page=1&q="+url.QueryEscape("author-email:"+email), &commits); err != nil {
+		return githubProfile{}, err
+	}
+	if len(commits.Items) > 0 && commits.Items[0].Author != nil {
+		return *commits.Items[0].Author, nil
+	}
+	return githubProfile{}, nil
+}
+
+// githubGet fetches an API path and decodes the JSON response into value.
+// When the rate limit is exhausted it waits for the reset once, if that
+// is within githubMaxWait.
+func githubGet(client *http.Client, path string, value interface{}) error {
+	api := os.Getenv(githubAPIEnv)
+	if api == "" {
+		api = "https://api.github.com"
+	}
+	token := os.Getenv(githubTokenEnv)
+
+	for waited := false; ; waited = true {
+		req, err := http.NewRequestWithContext(runCtx, http.MethodGet, api+path, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "application/vnd.github+json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		debugf("+ GET %s\n", req.URL)
+		resp, err := client.Do(req)
+		if err != nil {
+			if runCtx.Err() != nil {
+				return interruption(runCtx)
+			}
+			return err
+		}
+		limited := resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0" ||
+			resp.StatusCode == http.StatusTooManyRequests
+		if !limited {
+			defer resp.Body.Close()
+			switch {
+			case resp.StatusCode == http.StatusNotFound:
+				return errNotFound
+			case resp.StatusCode != http.StatusOK:
+				return fmt.Errorf("GET %s: %s", path, resp.Status)
+			}
+			return json.NewDecoder(resp.Body).Decode(value)
+		}
+		resp.Body.Close()
+
+		wait := rateLimitWait(resp.Header, time.Now())
+		if waited || wait > githubMaxWait {
+			if token == "" {
+				return fmt.Errorf("%w, set %s for a higher limit", errRateLimited, githubTokenEnv)
+			}
+			return errRateLimited
+		}
+		infof("GitHub API rate limit reached, waiting %s\n", wait.Round(time.Second))
+		select {
+		case <-time.After(wait):
+		case <-runCtx.Done():
+			return interruption(runCtx)
+		}
+	}
+}
+
+// rateLimitWait returns how long to wait before retrying a rate limited
+// request, from its Retry-After or X-RateLimit-Reset header, or a minute
+// when it has neither
+func rateLimitWait(header http.Header, now time.Time) time.Duration {
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		// The reset is in whole seconds, so wait for the next one
+		return max(time.Unix(reset, 0).Sub(now)+time.Second, 0)
+	}
+	return time.Minute
+}
+
+// githubCache is a cache file of GitHub lookups by key. Entries expire
+// after githubCacheTTL, or githubMissTTL when the lookup found nothing.
+type githubCache struct {
+	name    string
+	entries map[string]githubCacheEntry
+}
+
+// githubCacheEntry is a cached lookup and when it was made
+type githubCacheEntry struct {
+	Value   json.RawMessage `json:"value"`
+	Fetched time.Time       `json:"fetched"`
+	Miss    bool            `json:"miss,omitempty"`
+}
+
+// githubCacheFile returns the file a kind of GitHub lookup is cached in
+func githubCacheFile(name string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gitwho", name), nil
+}
+
+// loadGitHubCache reads a cache file. A missing or unreadable cache is
+// empty.
+func loadGitHubCache(name string) *githubCache {
+	cache := &githubCache{name: name, entries: make(map[string]githubCacheEntry)}
+	file, err := githubCacheFile(name)
+	if err != nil {
+		return cache
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache.entries); err != nil {
+		debugf("Ignoring %s: %v\n", file, err)
+		cache.entries = make(map[string]githubCacheEntry)
+	}
+	return cache
+}
+
+// get decodes the cached lookup of key into value and reports whether
+// there was one that has not expired
+func (c *githubCache) get(key string, value interface{}) bool {
+	entry, ok := c.entries[key]
+	if !ok {
+		return false
+	}
+	ttl := githubCacheTTL
+	if entry.Miss {
+		ttl = githubMissTTL
+	}
+	if time.Since(entry.Fetched) > ttl {
+		return false
+	}
+	return json.Unmarshal(entry.Value, value) == nil
+}
+
+// put caches the lookup of key; miss marks a lookup that found nothing
+func (c *githubCache) put(key string, value interface{}, miss bool) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	c.entries[key] = githubCacheEntry{Value: data, Fetched: time.Now(), Miss: miss}
+}
+
+// save writes the cache, leaving out expired entries. The file is only
+// readable by the user since it maps emails to accounts.
+func (c *githubCache) save() error {
+	file, err := githubCacheFile(c.name)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for key, entry := range c.entries {
+		if now.Sub(entry.Fetched) > githubCacheTTL || entry.Miss && now.Sub(entry.Fetched) > githubMissTTL {
+			delete(c.entries, key)
+		}
+	}
+	data, err := json.MarshalIndent(c.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, data, 0600); err != nil {
+		return err
+	}
+	// Files written by earlier versions were readable by everyone
+	return os.Chmod(file, 0600)
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRateLimitWait(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tests := []struct {
+		header http.Header
+		want   time.Duration
+	}{
+		{http.Header{"Retry-After": {"30"}}, 30 * time.Second},
+		{http.Header{"X-Ratelimit-Reset": {"1700000020"}}, 21 * time.Second},
+		{http.Header{"X-Ratelimit-Reset": {"1699999990"}}, 0},
+		{http.Header{}, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := rateLimitWait(tt.header, now); got != tt.want {
+			t.Errorf("rateLimitWait(%v) = %s, want %s", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestGitHubCache(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	cache := loadGitHubCache("test.json")
+	cache.put("hit", githubProfile{Login: "jane"}, false)
+	cache.put("miss", githubProfile{}, true)
+	cache.put("stale-miss", githubProfile{}, true)
+	entry := cache.entries["stale-miss"]
+	entry.Fetched = time.Now().Add(-2 * githubMissTTL)
+	cache.entries["stale-miss"] = entry
+	if err := cache.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := githubCacheFile("test.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("cache file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	cache = loadGitHubCache("test.json")
+	var profile githubProfile
+	if !cache.get("hit", &profile) || profile.Login != "jane" {
+		t.Errorf("get(hit) = %+v, want jane", profile)
+	}
+	if !cache.get("miss", &profile) {
+		t.Errorf("get(miss) found nothing, want the cached miss")
+	}
+	if cache.get("stale-miss", &profile) {
+		t.Errorf("get(stale-miss) found an expired miss")
+	}
+}
+
+func TestGitHubGetRetriesAndToken(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if got := r.Header.Get("Authorization"); got != "Bearer github-token" {
+			t.Errorf("Authorization = %q, want the GITHUB_TOKEN", got)
+		}
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"login": "jane"}`))
+	}))
+	defer server.Close()
+	t.Setenv(githubAPIEnv, server.URL)
+	t.Setenv(githubTokenEnv, "github-token")
+	t.Setenv(tokenEnv, "clone-token")
+
+	var profile githubProfile
+	if err := githubGet(server.Client(), "/users/jane", &profile); err != nil {
+		t.Fatal(err)
+	}
+	if profile.Login != "jane" || requests != 2 {
+		t.Errorf("got %+v after %d requests, want jane after 2", profile, requests)
+	}
+}
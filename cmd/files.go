@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/PerArneng/gitwho/pkg/gitwho"
+	"github.com/spf13/cobra"
+)
+
+var filesFormat string
+
+// filesCmd represents the files command
+var filesCmd = &cobra.Command{
+	Use:   "files [directory]",
+	Short: "Show the top contributor of each file",
+	Long: `Files lists every file changed under a directory with its total churn,
+the number of contributors and the top contributor by changed lines, so
+you know who to ask about a specific file. Files with the most churn come
+first.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+		if !isValidTableFormat(filesFormat) {
+			fmt.Printf("Error: Invalid format %s. Supported formats: table, csv, json\n", filesFormat)
+			os.Exit(1)
+		}
+
+		commits, err := collectCommits([]string{path}, lastTimeRange, repoPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		files := buildFileStats(commits)
+		if err := displayTable(os.Stdout, filesFormat, ownershipTable("FILE", files), files); err != nil {
+			fmt.Printf("Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	filesCmd.Flags().StringVar(&filesFormat, "format", "table", "Output format (table, csv, json)")
+	rootCmd.AddCommand(filesCmd)
+}
+
+// ownershipStats summarizes the changes to a file or directory and who
+// made most of them
+type ownershipStats struct {
+	Path         string  `json:"path"`
+	Commits      int     `json:"commits"`
+	Additions    int     `json:"additions"`
+	Deletions    int     `json:"deletions"`
+	Churn        int     `json:"churn"`
+	Contributors int     `json:"contributors"`
+	Owner        string  `json:"owner"`
+	OwnerEmail   string  `json:"ownerEmail"`
+	OwnerShare   float64 `json:"ownerShare"`
+}
+
+// buildOwnership aggregates the commits of one file or directory
+func buildOwnership(path string, commits []*Commit) ownershipStats {
+	contributors := gitwho.Aggregate(commits)
+	gitwho.ComputeShares(contributors)
+	sortContributorsBy(contributors, "total", false)
+
+	stats := ownershipStats{Path: path, Contributors: len(contributors)}
+	hashes := make(map[string]bool)
+	for _, commit := range commits {
+		hashes[commit.Hash] = true
+	}
+	stats.Commits = len(hashes)
+	for _, contributor := range contributors {
+		stats.Additions += contributor.Additions
+		stats.Deletions += contributor.Deletions
+	}
+	stats.Churn = stats.Additions + stats.Deletions
+	if len(contributors) > 0 {
+		stats.Owner = contributors[0].Name
+		stats.OwnerEmail = contributors[0].Email
+		stats.OwnerShare = contributors[0].ChangeShare
+	}
+	return stats
+}
+
+// buildFileStats computes the ownership of every changed file, with the
+// most churned files first
+func buildFileStats(commits []*Commit) []ownershipStats {
+	var files []ownershipStats
+	for file, fileCommits := range splitCommits(commits, func(path string) string { return path }) {
+		files = append(files, buildOwnership(file, fileCommits))
+	}
+
+	slices.SortFunc(files, func(a, b ownershipStats) int {
+		return cmp.Or(cmp.Compare(b.Churn, a.Churn), cmp.Compare(a.Path, b.Path))
+	})
+	return files
+}
+
+// ownershipTable lays out one row per file or directory
+func ownershipTable(label string, stats []ownershipStats) textTable {
+	table := textTable{
+		Headers: []string{label, "COMMITS", "CHURN", "AUTHORS", "TOP CONTRIBUTOR", "SHARE"},
+		Numeric: []bool{false, true, true, true, false, true},
+	}
+	for _, s := range stats {
+		table.Rows = append(table.Rows, []string{
+			s.Path,
+			strconv.Itoa(s.Commits),
+			strconv.Itoa(s.Churn),
+			strconv.Itoa(s.Contributors),
+			s.Owner,
+			formatPercent(s.OwnerShare),
+		})
+	}
+	return table
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// HourWindow is a range of local hours, start inclusive and end exclusive.
+// A window whose start is after its end wraps around midnight.
+type HourWindow struct {
+	Start int
+	End   int
+}
+
+// parseHourWindow parses a window such as "9-17" or "22-6"
+func parseHourWindow(s string) (HourWindow, error) {
+	startStr, endStr, ok := strings.Cut(s, "-")
+	if !ok {
+		return HourWindow{}, fmt.Errorf("Error: invalid hour window %q, expected start-end (e.g. 9-17)", s)
+	}
+
+	start, err := strconv.Atoi(strings.TrimSpace(startStr))
+	if err != nil || start < 0 || start > 23 {
+		return HourWindow{}, fmt.Errorf("Error: invalid start hour in %q, expected 0-23", s)
+	}
+	end, err := strconv.Atoi(strings.TrimSpace(endStr))
+	if err != nil || end < 0 || end > 24 {
+		return HourWindow{}, fmt.Errorf("Error: invalid end hour in %q, expected 0-24", s)
+	}
+	if start == end {
+		return HourWindow{}, fmt.Errorf("Error: empty hour window %q", s)
+	}
+
+	return HourWindow{Start: start, End: end}, nil
+}
+
+// Contains reports whether the given hour of the day falls inside the window
+func (w HourWindow) Contains(hour int) bool {
+	if w.Start < w.End {
+		return hour >= w.Start && hour < w.End
+	}
+	return hour >= w.Start || hour < w.End
+}
+
+// filterByHours keeps commits authored inside the window when inside is
+// true, and commits authored outside of it otherwise. The hour is taken in
+// the commit's own timezone offset, i.e. the author's local time.
+func filterByHours(commits []*Commit, window HourWindow, inside bool) []*Commit {
+	var filtered []*Commit
+	for _, commit := range commits {
+		if window.Contains(commit.Date.Hour()) == inside {
+			filtered = append(filtered, commit)
+		}
+	}
+	return filtered
+}
+
+// filterByContribution drops contributors with fewer commits or fewer
+// changed lines than the given minimums
+func filterByContribution(contributors []*Contributor, minCommits int, minLines int) []*Contributor {
+	var filtered []*Contributor
+	for _, contributor := range contributors {
+		if contributor.Commits < minCommits {
+			continue
+		}
+		if contributor.Additions+contributor.Deletions < minLines {
+			continue
+		}
+		filtered = append(filtered, contributor)
+	}
+	return filtered
+}
+
+// builtinBotPatterns match the automated accounts skipped by --exclude-bots
+var builtinBotPatterns = []string{
+	`(?i)dependabot`,
+	`(?i)renovate`,
+	`(?i)\[bot\]`,
+	`(?i)^github-actions`,
+	`(?i)greenkeeper`,
+	`(?i)snyk-bot`,
+}
+
+// compilePatterns compiles regular expressions given on the command line
+// or in the config file
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("Error: invalid pattern %q: %v", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// authorMatches reports whether any of the patterns matches the author's
+// name or email
+func authorMatches(patterns []*regexp.Regexp, name string, email string) bool {
+	for _, re := range patterns {
+		if re.MatchString(name) || re.MatchString(email) {
+			return true
+		}
+	}
+	return false
+}
+
+// excludeAuthors drops the commits whose author matches any of the patterns
+func excludeAuthors(commits []*Commit, patterns []*regexp.Regexp) []*Commit {
+	if len(patterns) == 0 {
+		return commits
+	}
+
+	var filtered []*Commit
+	for _, commit := range commits {
+		if !authorMatches(patterns, commit.Name, commit.Email) {
+			filtered = append(filtered, commit)
+		}
+	}
+	return filtered
+}
+
+// includeAuthors keeps only the commits whose author matches one of the
+// patterns. Without patterns every commit is kept.
+func includeAuthors(commits []*Commit, patterns []*regexp.Regexp) []*Commit {
+	if len(patterns) == 0 {
+		return commits
+	}
+
+	var filtered []*Commit
+	for _, commit := range commits {
+		if authorMatches(patterns, commit.Name, commit.Email) {
+			filtered = append(filtered, commit)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseHourWindow(t *testing.T) {
+	for _, s := range []string{"9-17", "22-6", "0-24"} {
+		if _, err := parseHourWindow(s); err != nil {
+			t.Errorf("parseHourWindow(%q): %v", s, err)
+		}
+	}
+	for _, s := range []string{"9", "9-9", "24-3", "a-17", "9-25"} {
+		if _, err := parseHourWindow(s); err == nil {
+			t.Errorf("parseHourWindow(%q) succeeded, want an error", s)
+		}
+	}
+}
+
+func TestFilterByHours(t *testing.T) {
+	// The commits are at the same instant, in the authors' own timezones
+	instant := time.Date(2024, 3, 4, 16, 30, 0, 0, time.UTC)
+	commits := []*Commit{
+		{Hash: "utc", Date: instant},                                        // 16:30
+		{Hash: "tokyo", Date: instant.In(time.FixedZone("JST", 9*3600))},    // 01:30
+		{Hash: "newyork", Date: instant.In(time.FixedZone("EST", -5*3600))}, // 11:30
+		{Hash: "late", Date: time.Date(2024, 3, 4, 17, 0, 0, 0, time.UTC)},  // 17:00
+	}
+	window := HourWindow{Start: 9, End: 17}
+
+	assertHashes(t, filterByHours(commits, window, true), "utc", "newyork")
+	assertHashes(t, filterByHours(commits, window, false), "tokyo", "late")
+
+	// A window that wraps around midnight
+	night := HourWindow{Start: 22, End: 6}
+	assertHashes(t, filterByHours(commits, night, true), "tokyo")
+}
+
+// assertHashes checks that commits are the commits with the hashes, in order
+func assertHashes(t *testing.T, commits []*Commit, hashes ...string) {
+	t.Helper()
+	var got []string
+	for _, commit := range commits {
+		got = append(got, commit.Hash)
+	}
+	if len(got) != len(hashes) {
+		t.Fatalf("got commits %v, want %v", got, hashes)
+	}
+	for i := range got {
+		if got[i] != hashes[i] {
+			t.Fatalf("got commits %v, want %v", got, hashes)
+		}
+	}
+}
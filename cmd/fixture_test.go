@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PerArneng/gitwho/pkg/gitwho"
+)
+
+// fixtureCommits returns a small history with two authors sharing a file
+func fixtureCommits() []*Commit {
+	date := time.Date(2024, 5, 6, 10, 0, 0, 0, time.FixedZone("CEST", 2*3600))
+	return []*Commit{
+		{Hash: "c3", Name: "Jane Doe", Email: "jane@example.com", Date: date, Files: []FileChange{
+			{Path: "src/parser/lexer.go", Additions: 12, Deletions: 3},
+			{Path: "docs/logo.png", Binary: true},
+		}},
+		{Hash: "c2", Name: "John Smith", Email: "john@corp.example", Date: date.Add(-time.Hour), Files: []FileChange{
+			{Path: "src/parser/lexer.go", Additions: 4, Deletions: 9},
+		}},
+		{Hash: "c1", Name: "Jane Doe", Email: "jane@example.com", Date: date.Add(-48 * time.Hour), Files: []FileChange{
+			{Path: "src/main.go", Additions: 30},
+			{Path: "README.md", Additions: 5},
+		}},
+	}
+}
+
+// shape describes contributor statistics without their identities
+func shape(contributors []*Contributor) []string {
+	var rows []string
+	for _, c := range contributors {
+		var files []int
+		for _, lines := range c.Files {
+			files = append(files, lines)
+		}
+		slices.Sort(files)
+		rows = append(rows, fmt.Sprint(c.Commits, c.Additions, c.Deletions, len(c.BinaryFiles), files))
+	}
+	slices.Sort(rows)
+	return rows
+}
+
+func TestExportedFixtureKeepsAggregateShape(t *testing.T) {
+	commits := fixtureCommits()
+	fixture := formatCommits(anonymizeCommits(commits))
+
+	parsed, err := gitwho.ParseLog(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := shape(gitwho.Aggregate(fixtureCommits()))
+	got := shape(gitwho.Aggregate(parsed))
+	if !slices.Equal(got, want) {
+		t.Errorf("got shape %v, want %v", got, want)
+	}
+
+	// Files shared by several authors stay shared
+	if edges := buildEdges(gitwho.Aggregate(parsed)); len(edges) != 1 || edges[0].SharedFiles != 1 {
+		t.Errorf("got edges %+v, want one edge with one shared file", edges)
+	}
+
+	for i, commit := range parsed {
+		if shift := commit.Date.Sub(commits[i].Date); shift < -maxDateJitter || shift > maxDateJitter {
+			t.Errorf("date of %s shifted by %s", commit.Hash, shift)
+		}
+	}
+}
+
+func TestExportedFixtureLeaksNothing(t *testing.T) {
+	fixture := formatCommits(anonymizeCommits(fixtureCommits()))
+	for _, secret := range []string{"Jane", "Doe", "John", "jane@example.com", "corp.example", "src", "parser", "lexer", "main", "README", "logo", "c1", "c2", "c3"} {
+		if strings.Contains(fixture, secret) {
+			t.Errorf("fixture contains %q:\n%s", secret, fixture)
+		}
+	}
+	for _, ext := range []string{".go", ".md", ".png"} {
+		if !strings.Contains(fixture, ext) {
+			t.Errorf("fixture lost the %s extension:\n%s", ext, fixture)
+		}
+	}
+}
+
+func TestExportedFixtureIsDeterministic(t *testing.T) {
+	first := formatCommits(anonymizeCommits(fixtureCommits()))
+	second := formatCommits(anonymizeCommits(fixtureCommits()))
+	if first != second {
+		t.Errorf("exports differ:\n%s\n%s", first, second)
+	}
+}
+
+func TestReplayedFixtureKeepsTotals(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit("alice", map[string]string{"src/main.go": "a\nb\n", "README.md": "hi\n"})
+	repo.commit("bob", map[string]string{"src/main.go": "a\nc\nd\n"})
+	repo.commit("alice", map[string]string{"docs/guide.md": "x\n"})
+
+	want, err := buildReport([]string{repo.dir}, "", nil, HourWindow{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commits, err := collectCommits([]string{repo.dir}, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixture := filepath.Join(t.TempDir(), "fixture.txt")
+	if err := os.WriteFile(fixture, []byte(formatCommits(anonymizeCommits(commits))), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(previous string) { fixtureInput = previous }(fixtureInput)
+	fixtureInput = fixture
+	got, err := buildReport([]string{"."}, "", nil, HourWindow{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Path != fixture {
+		t.Errorf("the report is of %s, want the fixture %s", got.Path, fixture)
+	}
+	g, w := got.Summary, want.Summary
+	if g.Contributors != w.Contributors || g.Commits != w.Commits || g.Files != w.Files || g.Additions != w.Additions || g.Deletions != w.Deletions {
+		t.Errorf("the replayed fixture totals %+v, want %+v like the repository", g, w)
+	}
+}
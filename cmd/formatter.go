@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"io"
+	"slices"
+	"text/template"
+)
+
+// Formatter renders a report in one output format. Each format selectable
+// with --format is one, so adding a format means registering a Formatter,
+// and rendering does not depend on how the report was produced.
+type Formatter interface {
+	Render(w io.Writer, report *Report) error
+}
+
+// FormatterFunc adapts a rendering function to a Formatter
+type FormatterFunc func(w io.Writer, report *Report) error
+
+func (f FormatterFunc) Render(w io.Writer, report *Report) error {
+	return f(w, report)
+}
+
+// multiFormatter is a Formatter whose output must stay one document when
+// several reports are rendered, as with --per-path. Formatters that are
+// not render the reports one after the other.
+type multiFormatter interface {
+	Formatter
+	RenderAll(w io.Writer, reports []*Report) error
+}
+
+// renderReports renders the reports as one document when the formatter
+// supports it, or one after the other
+func renderReports(f Formatter, w io.Writer, reports []*Report) error {
+	if multi, ok := f.(multiFormatter); ok {
+		return multi.RenderAll(w, reports)
+	}
+	for _, report := range reports {
+		if err := f.Render(w, report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderOptions are the settings that shape the output. Formatters get
+// them when they are created instead of reading the flags, so they render
+// the same wherever they are used.
+type renderOptions struct {
+	Columns  []column // columns of the tabular formats, in order
+	Color    bool     // color the table
+	Humanize string   // number format of the table and markdown: "", separators or compact
+	NoHeader bool     // leave out the title and header rows
+	Totals   bool     // add a totals row to the tabular formats
+	Wide     bool     // size the table's text columns to their longest value
+
+	// Qualifiers follow the path in the table's title and the period of the
+	// summary text, e.g. "since 2024-01-01" or "on main..topic"
+	Qualifiers []string
+
+	ActivityBucket  string // period of the activity counts
+	ActivityPeriods int    // number of periods of the activity counts
+}
+
+// newRenderOptions returns the render options the flags select, coloring
+// the output when color is set
+func newRenderOptions(color bool) renderOptions {
+	options := renderOptions{
+		Columns:         activeColumns(),
+		Color:           color,
+		Humanize:        humanizeMode,
+		NoHeader:        noHeader,
+		Totals:          showSummary,
+		Wide:            wideOutput,
+		ActivityBucket:  activityBucket,
+		ActivityPeriods: activityPeriods,
+	}
+	if sinceDate != "" {
+		options.Qualifiers = append(options.Qualifiers, "since "+sinceDate)
+	}
+	if untilDate != "" {
+		options.Qualifiers = append(options.Qualifiers, "until "+untilDate)
+	}
+	if label := revisionLabel(); label != "" {
+		options.Qualifiers = append(options.Qualifiers, "on "+label)
+	}
+	return options
+}
+
+// hasColumn reports whether the column with the name is rendered
+func (o renderOptions) hasColumn(name string) bool {
+	return slices.ContainsFunc(o.Columns, func(col column) bool { return col.Name == name })
+}
+
+// withOptions adapts a rendering function to a Formatter constructor
+func withOptions(render func(w io.Writer, report *Report, options renderOptions) error) func(renderOptions) Formatter {
+	return func(options renderOptions) Formatter {
+		return FormatterFunc(func(w io.Writer, report *Report) error {
+			return render(w, report, options)
+		})
+	}
+}
+
+// reportsFormatter adapts rendering functions for one report and for
+// several to a multiFormatter
+type reportsFormatter struct {
+	FormatterFunc
+	all func(w io.Writer, reports []*Report) error
+}
+
+func (f reportsFormatter) RenderAll(w io.Writer, reports []*Report) error {
+	return f.all(w, reports)
+}
+
+// withReportsOptions is like withOptions for formats that render several
+// reports as one document
+func withReportsOptions(render func(w io.Writer, report *Report, options renderOptions) error, renderAll func(w io.Writer, reports []*Report, options renderOptions) error) func(renderOptions) Formatter {
+	return func(options renderOptions) Formatter {
+		return reportsFormatter{
+			FormatterFunc: func(w io.Writer, report *Report) error { return render(w, report, options) },
+			all:           func(w io.Writer, reports []*Report) error { return renderAll(w, reports, options) },
+		}
+	}
+}
+
+// formatters create the formatters of the output formats, by name
+var formatters = map[string]func(options renderOptions) Formatter{
+	"table":        withOptions(displayResults),
+	"json":         withReportsOptions(displayJSON, displayJSONReports),
+	"yaml":         withReportsOptions(displayYAML, displayYAMLReports),
+	"csv":          withReportsOptions(displayCSV, displayCSVReports),
+	"tsv":          withReportsOptions(displayTSV, displayTSVReports),
+	"markdown":     withOptions(displayMarkdown),
+	"html":         withOptions(displayHTML),
+	"edges":        withReportsOptions(displayEdges, displayEdgesReports),
+	"summary-text": withOptions(displaySummaryText),
+}
+
+// isValidFormat reports whether format is a supported output format
+func isValidFormat(format string) bool {
+	_, ok := formatters[format]
+	return ok
+}
+
+// templateFormatter renders reports with a --template
+type templateFormatter struct {
+	tmpl    *template.Template
+	options renderOptions
+}
+
+func (f templateFormatter) Render(w io.Writer, report *Report) error {
+	return displayTemplate(w, f.tmpl, report, f.options)
+}
+
+// formatter returns the formatter of the selected output format, or one
+// rendering tmpl when a template was given
+func formatter(tmpl *template.Template, options renderOptions) Formatter {
+	if tmpl != nil {
+		return templateFormatter{tmpl, options}
+	}
+	return formatters[outputFormat](options)
+}
+
+// errWriter passes writes on to w until one fails, and then keeps failing
+// with that error, so a formatter can write line by line and check once
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *errWriter) Write(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	n, err := e.w.Write(p)
+	e.err = err
+	return n, err
+}
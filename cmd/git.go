@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var verbose bool
+
+// debugOutput receives the diagnostic messages of --verbose
+var debugOutput io.Writer = os.Stderr
+var timeout time.Duration
+
+// runCtx is cancelled when gitwho is interrupted with Ctrl-C or runs longer
+// than --timeout. The git commands and the in-process backends stop with it.
+var runCtx = context.Background()
+
+// stopRun releases the resources of runCtx
+var stopRun context.CancelFunc = func() {}
+
+// startRun derives runCtx from the interrupt signals and --timeout. A
+// second Ctrl-C ends gitwho at once, as it would without the handler.
+func startRun() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	stopRun = stop
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		stopRun = func() {
+			cancel()
+			stop()
+		}
+	}
+	runCtx = ctx
+}
+
+// interruption returns why ctx was cancelled
+func interruption(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) && timeout > 0 {
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+	return fmt.Errorf("interrupted")
+}
+
+// gitPathEnv are the environment variables holding paths git resolves
+// relative to its working directory. Git hooks run with a relative GIT_DIR.
+var gitPathEnv = []string{"GIT_DIR", "GIT_WORK_TREE", "GIT_INDEX_FILE", "GIT_OBJECT_DIRECTORY", "GIT_COMMON_DIR"}
+
+// absoluteGitEnv makes the relative paths in git's environment absolute,
+// so they keep pointing at the same place when git runs with -C
+func absoluteGitEnv() error {
+	for _, name := range gitPathEnv {
+		value := os.Getenv(name)
+		if value == "" || filepath.IsAbs(value) {
+			continue
+		}
+		abs, err := filepath.Abs(value)
+		if err != nil {
+			return fmt.Errorf("Error resolving %s: %v", name, err)
+		}
+		debugf("Using %s=%s\n", name, abs)
+		if err := os.Setenv(name, abs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ceilingDirectories returns the directories of GIT_CEILING_DIRECTORIES,
+// which repository discovery does not move up into
+func ceilingDirectories() []string {
+	var ceilings []string
+	for _, dir := range filepath.SplitList(os.Getenv("GIT_CEILING_DIRECTORIES")) {
+		// Git ignores empty and relative entries
+		if filepath.IsAbs(dir) {
+			ceilings = append(ceilings, filepath.Clean(dir))
+		}
+	}
+	return ceilings
+}
+
+// runGit runs git with the given arguments and returns its stdout. The
+// stderr of git is written to stderr, or discarded when it is nil. With
+// --verbose the command line, timing and output size are logged.
+func runGit(stderr io.Writer, args ...string) (string, error) {
+	return runGitInput("", stderr, args...)
+}
+
+// runGitInput is like runGit but feeds input to the stdin of git
+func runGitInput(input string, stderr io.Writer, args ...string) (string, error) {
+	return runGitEnv(nil, input, stderr, args...)
+}
+
+// runGitEnv is like runGitInput but adds env to the environment of git.
+// The environment is not logged, so it can carry credentials.
+func runGitEnv(env []string, input string, stderr io.Writer, args ...string) (string, error) {
+	return runGitContext(runCtx, env, input, stderr, args...)
+}
+
+// runGitContext is like runGitEnv but stops git when ctx is cancelled
+func runGitContext(ctx context.Context, env []string, input string, stderr io.Writer, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	// Processes git started may hold on to its output after it was killed
+	cmd.WaitDelay = time.Second
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	var out bytes.Buffer
+	if input != "" {
+		cmd.Stdin = strings.NewReader(input)
+	}
+	cmd.Stdout = &out
+	cmd.Stderr = stderr
+
+	debugf("+ %s\n", formatCommandLine("git", args))
+	start := time.Now()
+	err := cmd.Run()
+	debugf("  took %s, %d bytes of output", time.Since(start).Round(time.Millisecond), out.Len())
+	if err != nil {
+		debugf(", failed: %v", err)
+	}
+	debugf("\n")
+
+	if err != nil && ctx.Err() != nil {
+		return "", interruption(ctx)
+	}
+	return out.String(), err
+}
+
+// debugf prints a diagnostic message to stderr when --verbose is set
+func debugf(format string, args ...interface{}) {
+	if verbose {
+		fmt.Fprintf(debugOutput, format, args...)
+	}
+}
+
+// formatCommandLine renders a command and its arguments so it can be
+// copied into a shell, quoting arguments that need it
+func formatCommandLine(name string, args []string) string {
+	parts := []string{name}
+	for _, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'\\$`|&;<>()*?[]{}~#") || !strconv.CanBackquote(arg) {
+			arg = strconv.Quote(arg)
+		}
+		parts = append(parts, arg)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,1009 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/PerArneng/gitwho/pkg/gitwho"
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/gitattributes"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// usesGoGit reports whether repositories are read with go-git instead of
+// the git binary
+func usesGoGit() bool {
+	return backend == "gogit"
+}
+
+// goGitSource reads the history in-process with go-git
+type goGitSource struct{}
+
+func (goGitSource) Commits(ctx context.Context, query gitwho.Query) ([]*Commit, error) {
+	commits, err := goGitLog(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading the history with go-git: %v", err)
+	}
+	return commits, nil
+}
+
+// Unsupported returns the flags go-git cannot honor: it detects no
+// copies, takes no git arguments and cannot deepen a shallow clone
+func (goGitSource) Unsupported() string {
+	switch {
+	case findCopies != "":
+		return "--find-copies"
+	case len(extraGitArgs) > 0:
+		return "--git-arg"
+	case fetchFullHistory:
+		return "--fetch-full-history"
+	}
+	return ""
+}
+
+// goGitRepos caches the opened repositories by path
+var goGitRepos = make(map[string]*git.Repository)
+
+// openGoGit opens the repository containing repoPath. As with git,
+// GIT_DIR names the repository instead of discovery, with GIT_WORK_TREE or
+// the current directory as its work tree unless it is bare.
+func openGoGit(repoPath string) (*git.Repository, error) {
+	if repo, ok := goGitRepos[repoPath]; ok {
+		return repo, nil
+	}
+
+	var repo *git.Repository
+	var err error
+	if gitDir := os.Getenv("GIT_DIR"); gitDir != "" {
+		storage := filesystem.NewStorage(osfs.New(gitDir), cache.NewObjectLRUDefault())
+		repo, err = git.Open(storage, nil)
+		if err == nil {
+			if config, cerr := repo.Config(); cerr == nil && !config.Core.IsBare || os.Getenv("GIT_WORK_TREE") != "" {
+				workTree := os.Getenv("GIT_WORK_TREE")
+				if workTree == "" {
+					workTree, _ = os.Getwd()
+				}
+				repo, err = git.Open(storage, osfs.New(workTree))
+			}
+		}
+	} else {
+		repo, err = git.PlainOpenWithOptions(repoPath, &git.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true})
+		if err == git.ErrRepositoryNotExists {
+			// Discovery only looks for .git directories, a bare repository
+			// is the directory itself
+			repo, err = git.PlainOpen(repoPath)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	goGitRepos[repoPath] = repo
+	return repo, nil
+}
+
+// goGitWorkTree returns the work tree of the repository, or nil when it is
+// bare
+func goGitWorkTree(repo *git.Repository) billy.Filesystem {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil
+	}
+	return worktree.Filesystem
+}
+
+// goGitRoot returns the root of the repository's work tree, or its git
+// directory when it is bare
+func goGitRoot(repoPath string) (string, error) {
+	repo, err := openGoGit(repoPath)
+	if err != nil {
+		return "", err
+	}
+	if workTree := goGitWorkTree(repo); workTree != nil {
+		return workTree.Root(), nil
+	}
+	storage, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return "", fmt.Errorf("repository %s has no git directory", repoPath)
+	}
+	return filepath.Abs(storage.Filesystem().Root())
+}
+
+// goGitIsBare reports whether the repository has no work tree
+func goGitIsBare(repoPath string) bool {
+	repo, err := openGoGit(repoPath)
+	return err == nil && goGitWorkTree(repo) == nil
+}
+
+// goGitResolve returns the commit a revision such as HEAD~2, a branch or a
+// tag names, peeling annotated tags
+func goGitResolve(repo *git.Repository, rev string) (*object.Commit, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return nil, err
+	}
+	if tag, err := repo.TagObject(*hash); err == nil {
+		return tag.Commit()
+	}
+	return repo.CommitObject(*hash)
+}
+
+// goGitHasRevision reports whether rev names a commit in the repository
+func goGitHasRevision(repoPath string, rev string) bool {
+	repo, err := openGoGit(repoPath)
+	if err != nil {
+		return false
+	}
+	_, err = goGitResolve(repo, rev)
+	return err == nil
+}
+
+// goGitHasPath reports whether path exists in the tree of a revision
+func goGitHasPath(repoPath string, rev string, path string) bool {
+	repo, err := openGoGit(repoPath)
+	if err != nil {
+		return false
+	}
+	commit, err := goGitResolve(repo, rev)
+	if err != nil {
+		return false
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return false
+	}
+	_, err = tree.FindEntry(path)
+	return err == nil
+}
+
+// goGitPathInHistory reports whether a commit of any branch or tag touched
+// path or a file below it
+func goGitPathInHistory(repoPath string, path string) bool {
+	repo, err := openGoGit(repoPath)
+	if err != nil {
+		return false
+	}
+	iter, err := repo.Log(&git.LogOptions{
+		All: true,
+		PathFilter: func(name string) bool {
+			return name == path || strings.HasPrefix(name, path+"/")
+		},
+	})
+	if err != nil {
+		return false
+	}
+	defer iter.Close()
+	_, err = iter.Next()
+	return err == nil
+}
+
+// goGitRemoteURL returns the first URL of a remote of the repository
+func goGitRemoteURL(repoPath string, name string) (string, error) {
+	repo, err := openGoGit(repoPath)
+	if err != nil {
+		return "", err
+	}
+	remote, err := repo.Remote(name)
+	if err != nil {
+		return "", err
+	}
+	if urls := remote.Config().URLs; len(urls) > 0 {
+		return urls[0], nil
+	}
+	return "", fmt.Errorf("remote %s has no URL", name)
+}
+
+// goGitSubmodules returns the paths of the submodules in the index that
+// the pathspecs select, like findSubmodules does with git
+func goGitSubmodules(repoPath string, relPaths []string) ([]string, error) {
+	repo, err := openGoGit(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	pathspec, err := newGoGitPathspec(relPaths)
+	if err != nil {
+		return nil, err
+	}
+	index, err := repo.Storer.Index()
+	if err != nil {
+		return nil, err
+	}
+	var submodules []string
+	for _, entry := range index.Entries {
+		if entry.Mode == filemode.Submodule && pathspec.match(entry.Name) {
+			submodules = append(submodules, entry.Name)
+		}
+	}
+	return submodules, nil
+}
+
+// goGitShallow returns the commits whose parents were left out of a
+// shallow clone
+func goGitShallow(repoPath string) []plumbing.Hash {
+	repo, err := openGoGit(repoPath)
+	if err != nil {
+		return nil
+	}
+	shallow, _ := repo.Storer.Shallow()
+	return shallow
+}
+
+// goGitShallowCutoff returns the author date of the newest commit of a
+// shallow clone's boundary, like shallowCutoff does with git
+func goGitShallowCutoff(repoPath string) string {
+	repo, err := openGoGit(repoPath)
+	if err != nil {
+		return "its cut-off"
+	}
+	var newest *object.Commit
+	for _, hash := range goGitShallow(repoPath) {
+		commit, err := repo.CommitObject(hash)
+		if err == nil && (newest == nil || commit.Committer.When.After(newest.Committer.When)) {
+			newest = commit
+		}
+	}
+	if newest == nil {
+		return "its cut-off"
+	}
+	return newest.Author.When.Format("2006-01-02")
+}
+
+// goGitLog returns the commits the query selects, as gitwho.GitBackend
+// would return them. Like git log, merges are listed without changes
+// unless the query follows first parents. It stops when ctx is cancelled.
+func goGitLog(ctx context.Context, query gitwho.Query) ([]*Commit, error) {
+	repo, err := openGoGit(query.Repo)
+	if err != nil {
+		return nil, err
+	}
+	pathspec, err := newGoGitPathspec(query.Paths)
+	if err != nil {
+		return nil, err
+	}
+	mailmap, err := loadGoGitMailmap(repo, query.Mailmap)
+	if err != nil {
+		return nil, err
+	}
+
+	walked, err := goGitWalk(ctx, repo, query, pathspec)
+	if err != nil {
+		return nil, err
+	}
+
+	renameScore := uint(50)
+	if query.RenameSimilarity > 0 {
+		renameScore = uint(query.RenameSimilarity)
+	}
+	followed := ""
+	if query.Follow && len(query.Paths) == 1 {
+		followed = query.Paths[0]
+	}
+	shallow := goGitShallow(query.Repo)
+	since, until := query.Since, query.Until
+
+	var commits []*Commit
+	for _, c := range walked {
+		if ctx.Err() != nil {
+			return nil, interruption(ctx)
+		}
+		when := c.Committer.When
+		if !since.IsZero() && when.Before(since) || !until.IsZero() && !when.Before(until) {
+			continue
+		}
+
+		// The boundary commits of a shallow clone are root commits to git
+		var parents []*object.Commit
+		if !slices.Contains(shallow, c.Hash) {
+			err := c.Parents().ForEach(func(parent *object.Commit) error {
+				parents = append(parents, parent)
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		isMerge := len(parents) > 1
+		if isMerge && query.NoMerges {
+			continue
+		}
+
+		commit := goGitCommit(c, mailmap, query.Signed)
+		switch {
+		case isMerge && !query.FirstParent:
+			// git log lists merges that changed the paths compared to every
+			// parent, without a diff
+			if followed != "" {
+				continue
+			}
+			keep := true
+			for _, parent := range parents {
+				same, err := goGitTreesame(parent, c, pathspec)
+				if err != nil {
+					return nil, err
+				}
+				keep = keep && !same
+			}
+			if !keep {
+				continue
+			}
+		default:
+			var parent *object.Commit
+			if len(parents) > 0 {
+				parent = parents[0]
+			}
+			changes, err := goGitChanges(parent, c, pathspec, followed, renameScore)
+			if err != nil {
+				return nil, err
+			}
+			if len(changes) == 0 {
+				continue
+			}
+			for _, change := range changes {
+				file, err := goGitFileChange(change, query.IgnoreWhitespace)
+				if err != nil {
+					return nil, err
+				}
+				commit.Files = append(commit.Files, file)
+				// Older commits touched the file under its previous name
+				if followed != "" && change.From.Name != "" && change.From.Name != change.To.Name {
+					followed = change.From.Name
+				}
+			}
+		}
+		commits = append(commits, commit)
+	}
+	if query.Signed {
+		if err := verifySignatures(ctx, query.Repo, commits); err != nil {
+			return nil, err
+		}
+	}
+	return commits, nil
+}
+
+// goGitCommit converts a commit's metadata, resolving its identities
+// through the mailmap like %aN, %aE, %cN and %cE do, and telling whether
+// it is signed when signed is set
+func goGitCommit(c *object.Commit, mailmap *goGitMailmap, signed bool) *Commit {
+	commit := &Commit{Hash: c.Hash.String(), Date: c.Author.When, CommitDate: c.Committer.When}
+	commit.Name, commit.Email = mailmap.resolve(c.Author.Name, c.Author.Email)
+	commit.CommitterName, commit.CommitterEmail = mailmap.resolve(c.Committer.Name, c.Committer.Email)
+	commit.Subject = messageSubject(c.Message)
+
+	trailers := messageTrailers(c.Message)
+	commit.CoAuthors = trailers["co-authored-by"]
+	commit.Reviewers = trailers["reviewed-by"]
+	commit.SignOffs = trailers["signed-off-by"]
+	if signed {
+		// git verifies the signatures found, see verifySignatures
+		commit.Signed = c.PGPSignature != ""
+	}
+	return commit
+}
+
+// goGitWalk returns the commits reachable from the query's revisions, or
+// HEAD when there are none, newest first by commit date as git log lists
+// them. With FirstParent only the first parents of merges are followed.
+// Like git's history simplification, a merge that left the paths as one
+// of its parents had them only follows that parent.
+func goGitWalk(ctx context.Context, repo *git.Repository, query gitwho.Query, pathspec *goGitPathspec) ([]*object.Commit, error) {
+	var include, exclude []*object.Commit
+	resolve := func(rev string) (*object.Commit, error) {
+		commit, err := goGitResolve(repo, rev)
+		if err != nil {
+			return nil, fmt.Errorf("unknown revision %s", rev)
+		}
+		return commit, nil
+	}
+
+	revisions := query.Revisions
+	if len(revisions) == 0 {
+		revisions = []string{"HEAD"}
+	}
+	for _, rev := range revisions {
+		switch {
+		case rev == "--all":
+			refs, err := repo.References()
+			if err != nil {
+				return nil, err
+			}
+			refs.ForEach(func(ref *plumbing.Reference) error {
+				if commit, err := goGitResolve(repo, ref.Name().String()); err == nil {
+					include = append(include, commit)
+				}
+				return nil
+			})
+		case strings.Contains(rev, "..."):
+			return nil, fmt.Errorf("the gogit backend does not support symmetric ranges like %s", rev)
+		case strings.Contains(rev, ".."):
+			from, to, _ := strings.Cut(rev, "..")
+			if from == "" {
+				from = "HEAD"
+			}
+			if to == "" {
+				to = "HEAD"
+			}
+			excluded, err := resolve(from)
+			if err != nil {
+				return nil, err
+			}
+			included, err := resolve(to)
+			if err != nil {
+				return nil, err
+			}
+			exclude = append(exclude, excluded)
+			include = append(include, included)
+		default:
+			commit, err := resolve(rev)
+			if err != nil {
+				return nil, err
+			}
+			include = append(include, commit)
+		}
+	}
+
+	shallow := goGitShallow(query.Repo)
+	// reach returns the commits reachable from starts in the order they
+	// were found, children before their parents
+	reach := func(starts []*object.Commit, simplify bool, skip map[plumbing.Hash]bool) ([]*object.Commit, error) {
+		seen := make(map[plumbing.Hash]bool)
+		var found []*object.Commit
+		queue := slices.Clone(starts)
+		for len(queue) > 0 {
+			if ctx.Err() != nil {
+				return nil, interruption(ctx)
+			}
+			commit := queue[0]
+			queue = queue[1:]
+			if seen[commit.Hash] || skip[commit.Hash] {
+				continue
+			}
+			seen[commit.Hash] = true
+			found = append(found, commit)
+			if slices.Contains(shallow, commit.Hash) {
+				continue
+			}
+			var parents []*object.Commit
+			for _, hash := range commit.ParentHashes {
+				parent, err := repo.CommitObject(hash)
+				if err != nil {
+					return nil, err
+				}
+				parents = append(parents, parent)
+			}
+			if simplify && query.FirstParent && len(parents) > 1 {
+				parents = parents[:1]
+			}
+			if simplify && len(parents) > 1 {
+				for _, parent := range parents {
+					same, err := goGitTreesame(parent, commit, pathspec)
+					if err != nil {
+						return nil, err
+					}
+					if same {
+						parents = []*object.Commit{parent}
+						break
+					}
+				}
+			}
+			queue = append(queue, parents...)
+		}
+		return found, nil
+	}
+
+	excluded, err := reach(exclude, false, nil)
+	if err != nil {
+		return nil, err
+	}
+	skip := make(map[plumbing.Hash]bool)
+	for _, commit := range excluded {
+		skip[commit.Hash] = true
+	}
+	commits, err := reach(include, true, skip)
+	if err != nil {
+		return nil, err
+	}
+
+	// Like git log, commits made in the same second are listed in the
+	// order they were found, so children stay before their parents
+	slices.SortStableFunc(commits, func(a, b *object.Commit) int {
+		return b.Committer.When.Compare(a.Committer.When)
+	})
+	return commits, nil
+}
+
+// goGitTreesame reports whether a commit left the paths of the pathspec as
+// its parent had them
+func goGitTreesame(parent *object.Commit, commit *object.Commit, pathspec *goGitPathspec) (bool, error) {
+	changes, err := goGitDiff(parent, commit)
+	if err != nil {
+		return false, err
+	}
+	return !slices.ContainsFunc(changes, pathspec.matchChange), nil
+}
+
+// goGitChanges returns the changes between the trees of a commit and its
+// parent that match the pathspec or, when following a file, touch it.
+// Renames are detected among the matched changes, or the whole tree when
+// following a file.
+func goGitChanges(parent *object.Commit, commit *object.Commit, pathspec *goGitPathspec, followed string, renameScore uint) (object.Changes, error) {
+	changes, err := goGitDiff(parent, commit)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &object.DiffTreeOptions{DetectRenames: true, RenameScore: renameScore}
+	if followed != "" {
+		if changes, err = object.DetectRenames(changes, options); err != nil {
+			return nil, err
+		}
+		var touched object.Changes
+		for _, change := range changes {
+			if change.To.Name == followed || change.To.Name == "" && change.From.Name == followed {
+				touched = append(touched, change)
+			}
+		}
+		return touched, nil
+	}
+
+	var matched object.Changes
+	for _, change := range changes {
+		if pathspec.matchChange(change) {
+			matched = append(matched, change)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, nil
+	}
+	return object.DetectRenames(matched, options)
+}
+
+// goGitDiff returns the changes between the trees of a commit and its
+// parent, or all its files for a root commit
+func goGitDiff(parent *object.Commit, commit *object.Commit) (object.Changes, error) {
+	var from *object.Tree
+	if parent != nil {
+		var err error
+		if from, err = parent.Tree(); err != nil {
+			return nil, err
+		}
+	}
+	to, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	return object.DiffTree(from, to)
+}
+
+// goGitFileChange counts the lines a change added and deleted, like git's
+// numstat. Binary files, detected by a NUL byte in their first 8000 bytes
+// as git does, have no line counts, and a submodule's commit pointer counts
+// as one line.
+func goGitFileChange(change *object.Change, ignoreWhitespace bool) (FileChange, error) {
+	file := FileChange{Path: change.To.Name}
+	if file.Path == "" {
+		file.Path = change.From.Name
+	}
+
+	if change.From.TreeEntry.Mode == filemode.Submodule || change.To.TreeEntry.Mode == filemode.Submodule {
+		if change.From.Name != "" {
+			file.Deletions = 1
+		}
+		if change.To.Name != "" {
+			file.Additions = 1
+		}
+		return file, nil
+	}
+
+	from, to, err := change.Files()
+	if err != nil {
+		return file, err
+	}
+	var contents [2]string
+	for i, f := range []*object.File{from, to} {
+		if f == nil {
+			continue
+		}
+		binary, err := f.IsBinary()
+		if err != nil {
+			return file, err
+		}
+		if binary {
+			file.Binary = true
+			return file, nil
+		}
+		if contents[i], err = f.Contents(); err != nil {
+			return file, err
+		}
+	}
+
+	file.Additions, file.Deletions = lineDiff(contents[0], contents[1], ignoreWhitespace)
+	return file, nil
+}
+
+// goGitPathspec matches paths like the pathspecs git log is given: literal
+// paths and :(literal) patterns match themselves and everything below
+// them, wildcards match whole paths with "*" crossing directories, :(glob)
+// patterns match like gitignore globs anchored at the root, and :! or
+// :(exclude) patterns exclude paths.
+type goGitPathspec struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// newGoGitPathspec compiles pathspecs relative to the repository root
+func newGoGitPathspec(specs []string) (*goGitPathspec, error) {
+	pathspec := &goGitPathspec{}
+	for _, spec := range specs {
+		exclude, glob, literal := false, false, false
+		if magic, rest, ok := strings.Cut(strings.TrimPrefix(spec, ":("), ")"); ok && strings.HasPrefix(spec, ":(") {
+			for _, word := range strings.Split(magic, ",") {
+				switch word {
+				case "glob":
+					glob = true
+				case "exclude":
+					exclude = true
+				case "literal":
+					literal = true
+				case "top":
+				default:
+					return nil, fmt.Errorf("the gogit backend does not support the pathspec magic %s", word)
+				}
+			}
+			spec = rest
+		} else if strings.HasPrefix(spec, ":") {
+			spec = spec[1:]
+			for len(spec) > 0 && strings.ContainsRune("!^/", rune(spec[0])) {
+				exclude = exclude || spec[0] != '/'
+				spec = spec[1:]
+			}
+			spec = strings.TrimPrefix(spec, ":")
+		}
+
+		spec = strings.TrimSuffix(filepath.ToSlash(filepath.Clean(spec)), "/")
+		var pattern string
+		switch {
+		case spec == "." || spec == "":
+			pattern = ".*"
+		case literal || !strings.ContainsAny(spec, "*?["):
+			pattern = "^" + regexp.QuoteMeta(spec) + "(/|$)"
+		case glob:
+			pattern = gitwho.GlobRegexp("/" + spec)
+		default:
+			pattern = "^" + wildcardRegexp(spec) + "$"
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", spec, err)
+		}
+		if exclude {
+			pathspec.exclude = append(pathspec.exclude, re)
+		} else {
+			pathspec.include = append(pathspec.include, re)
+		}
+	}
+	// Only exclusions match everything else, like git does
+	if len(pathspec.include) == 0 {
+		pathspec.include = append(pathspec.include, regexp.MustCompile(".*"))
+	}
+	return pathspec, nil
+}
+
+// match reports whether path is selected by the pathspec
+func (p *goGitPathspec) match(path string) bool {
+	if path == "" {
+		return false
+	}
+	matches := func(re *regexp.Regexp) bool { return re.MatchString(path) }
+	return slices.ContainsFunc(p.include, matches) && !slices.ContainsFunc(p.exclude, matches)
+}
+
+// matchChange reports whether the change's old or new path is selected
+func (p *goGitPathspec) matchChange(change *object.Change) bool {
+	return p.match(change.From.Name) || p.match(change.To.Name)
+}
+
+// wildcardRegexp converts a pathspec wildcard to a regular expression.
+// Unlike in globs, "*" and "?" match "/" too.
+func wildcardRegexp(pattern string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// messageSubject returns the subject of a commit message like %s: its
+// first paragraph joined into one line
+func messageSubject(message string) string {
+	paragraph, _, _ := strings.Cut(strings.TrimLeft(message, "\n"), "\n\n")
+	var lines []string
+	for _, line := range strings.Split(paragraph, "\n") {
+		if line = strings.TrimRight(line, " \t"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, " ")
+}
+
+// trailerPattern matches a "Key: value" trailer line
+var trailerPattern = regexp.MustCompile(`^([A-Za-z0-9-]+)\s*:\s*(.*)$`)
+
+// messageTrailers returns the values of the trailers of a commit message
+// by lowercased key. Like git, the trailers are the last paragraph, after
+// the subject, when it consists of trailers only, or of at least 25%
+// trailers including a Signed-off-by. Indented lines continue the value of
+// the previous trailer.
+func messageTrailers(message string) map[string][]string {
+	trailers := make(map[string][]string)
+	paragraphs := strings.Split(strings.TrimSpace(message), "\n\n")
+	if len(paragraphs) < 2 {
+		return trailers
+	}
+	last := strings.Trim(paragraphs[len(paragraphs)-1], "\n")
+
+	type trailer struct{ key, value string }
+	var found []trailer
+	lines, signedOff := 0, false
+	for _, line := range strings.Split(last, "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines++
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(found) > 0 {
+			found[len(found)-1].value += " " + strings.TrimSpace(line)
+			lines--
+			continue
+		}
+		if match := trailerPattern.FindStringSubmatch(line); match != nil {
+			found = append(found, trailer{strings.ToLower(match[1]), strings.TrimSpace(match[2])})
+			signedOff = signedOff || strings.EqualFold(match[1], "Signed-off-by")
+		}
+	}
+	if len(found) == 0 || len(found) < lines && !(signedOff && len(found)*4 >= lines) {
+		return trailers
+	}
+	for _, t := range found {
+		trailers[t.key] = append(trailers[t.key], t.value)
+	}
+	return trailers
+}
+
+// goGitMailmap maps identities like git's .mailmap: by email, or by name
+// and email, to a proper name, email or both. Names and emails are matched
+// case-insensitively.
+type goGitMailmap struct {
+	entries map[string]*mailmapEntry
+}
+
+// mailmapEntry is the proper name and email of a mapped identity. Empty
+// fields keep the commit's value.
+type mailmapEntry struct {
+	Name  string
+	Email string
+}
+
+// loadGoGitMailmap reads the .mailmap at the root of the work tree, or in
+// the HEAD commit of a bare repository, as git does by default, followed by
+// the mailmap file if there is one
+func loadGoGitMailmap(repo *git.Repository, file string) (*goGitMailmap, error) {
+	var data string
+	if workTree := goGitWorkTree(repo); workTree != nil {
+		if f, err := workTree.Open(".mailmap"); err == nil {
+			content, _ := io.ReadAll(f)
+			f.Close()
+			data = string(content)
+		}
+	} else if head, err := goGitResolve(repo, "HEAD"); err == nil {
+		if file, err := head.File(".mailmap"); err == nil {
+			data, _ = file.Contents()
+		}
+	}
+	extra, err := readMailmapFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return parseMailmap(data + "\n" + extra), nil
+}
+
+// readMailmapFile reads the mailmap file of a query, which maps identities
+// like mailmap.file does: its entries override those of the repository's
+// .mailmap, and git ignores the file if it does not exist.
+func readMailmapFile(file string) (string, error) {
+	if file == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("Error reading mailmap: %v", err)
+	}
+	return string(data), nil
+}
+
+// parseMailmap parses the lines of a .mailmap file
+func parseMailmap(data string) *goGitMailmap {
+	mailmap := &goGitMailmap{entries: make(map[string]*mailmapEntry)}
+	for _, line := range strings.Split(data, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		properName, rest, ok := strings.Cut(line, "<")
+		if !ok {
+			continue
+		}
+		properEmail, rest, ok := strings.Cut(rest, ">")
+		if !ok {
+			continue
+		}
+		entry := mailmapEntry{Name: strings.TrimSpace(properName), Email: strings.TrimSpace(properEmail)}
+		commitName, commitEmail := "", entry.Email
+		if name, rest, ok := strings.Cut(rest, "<"); ok {
+			if email, _, ok := strings.Cut(rest, ">"); ok {
+				commitName, commitEmail = strings.TrimSpace(name), strings.TrimSpace(email)
+			}
+		} else {
+			// "Proper Name <commit@email>" only maps the name
+			entry.Email = ""
+		}
+
+		key := mailmapKey(commitName, commitEmail)
+		existing, ok := mailmap.entries[key]
+		if !ok {
+			mailmap.entries[key] = &entry
+			continue
+		}
+		if entry.Name != "" {
+			existing.Name = entry.Name
+		}
+		if entry.Email != "" {
+			existing.Email = entry.Email
+		}
+	}
+	return mailmap
+}
+
+// mailmapKey is the lookup key of a commit identity
+func mailmapKey(name string, email string) string {
+	return strings.ToLower(name) + "\x00" + strings.ToLower(email)
+}
+
+// resolve returns the proper name and email of an identity
+func (m *goGitMailmap) resolve(name string, email string) (string, string) {
+	entry, ok := m.entries[mailmapKey(name, email)]
+	if !ok {
+		entry, ok = m.entries[mailmapKey("", email)]
+	}
+	if !ok {
+		return name, email
+	}
+	if entry.Name != "" {
+		name = entry.Name
+	}
+	if entry.Email != "" {
+		email = entry.Email
+	}
+	return name, email
+}
+
+// goGitResolveTrailers maps the identities in the trailers of the commits
+// through the repository's .mailmap, like resolveTrailers does with git
+func goGitResolveTrailers(commits []*Commit, repoPath string) error {
+	repo, err := openGoGit(repoPath)
+	if err != nil {
+		return fmt.Errorf("Error resolving trailer identities: %v", err)
+	}
+	mailmap, err := loadGoGitMailmap(repo, "")
+	if err != nil {
+		return fmt.Errorf("Error resolving trailer identities: %v", err)
+	}
+	for _, commit := range commits {
+		for _, trailers := range [][]string{commit.CoAuthors, commit.Reviewers, commit.SignOffs} {
+			for i, value := range trailers {
+				if name, email, ok := parseIdentity(value); ok {
+					name, email = mailmap.resolve(name, email)
+					trailers[i] = name + " <" + email + ">"
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// goGitVendoredPaths returns which of the paths have a linguist-vendored
+// or linguist-generated attribute set, like vendoredPaths does with git.
+// The .gitattributes files of the directories of the paths are read from
+// the work tree, followed by info/attributes in the git directory, which
+// takes precedence.
+func goGitVendoredPaths(root string, paths []string) (map[string]bool, error) {
+	vendored := make(map[string]bool)
+	repo, err := openGoGit(root)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading .gitattributes: %v", err)
+	}
+
+	var patterns []gitattributes.MatchAttribute
+	if workTree := goGitWorkTree(repo); workTree != nil {
+		dirs := []string{""}
+		for _, path := range paths {
+			for dir := filepath.ToSlash(filepath.Dir(path)); dir != "." && !slices.Contains(dirs, dir); dir = filepath.ToSlash(filepath.Dir(dir)) {
+				dirs = append(dirs, dir)
+			}
+		}
+		// Deeper files take precedence, and only the root may define macros
+		depth := func(dir string) int {
+			if dir == "" {
+				return -1
+			}
+			return strings.Count(dir, "/")
+		}
+		slices.SortStableFunc(dirs, func(a, b string) int { return depth(a) - depth(b) })
+		for _, dir := range dirs {
+			var domain []string
+			if dir != "" {
+				domain = strings.Split(dir, "/")
+			}
+			attributes, err := gitattributes.ReadAttributesFile(workTree, domain, ".gitattributes", dir == "")
+			if err != nil {
+				return nil, fmt.Errorf("Error reading .gitattributes: %v", err)
+			}
+			patterns = append(patterns, attributes...)
+		}
+	}
+	if storage, ok := repo.Storer.(*filesystem.Storage); ok {
+		attributes, err := gitattributes.ReadAttributesFile(storage.Filesystem(), nil, "info/attributes", true)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading info/attributes: %v", err)
+		}
+		patterns = append(patterns, attributes...)
+	}
+	if len(patterns) == 0 {
+		return vendored, nil
+	}
+
+	matcher := gitattributes.NewMatcher(patterns)
+	for _, path := range paths {
+		// The matcher returns the first match of each attribute asked for
+		// when asked for one at a time
+		for _, name := range vendoredAttributes {
+			attributes, _ := matcher.Match(strings.Split(path, "/"), []string{name})
+			if attribute, ok := attributes[name]; ok && (attribute.IsSet() || attribute.IsValueSet() && attribute.Value() == "true") {
+				vendored[path] = true
+			}
+		}
+	}
+	return vendored, nil
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGoGitPathspec(t *testing.T) {
+	tests := []struct {
+		spec  string
+		path  string
+		match bool
+	}{
+		{"src", "src/main.go", true},
+		{"src", "srcs/main.go", false},
+		{"*.go", "src/main.go", true},
+		{":(glob)**/*.go", "src/main.go", true},
+		{":!vendor", "vendor/lib.go", false},
+		{":!vendor", "main.go", true},
+		// Literal pathspecs take wildcards as they are
+		{":(top,literal)a[1].txt", "a[1].txt", true},
+		{":(top,literal)a[1].txt", "a1.txt", false},
+		{":(top,literal)docs", "docs/removed.md", true},
+	}
+	for _, tt := range tests {
+		pathspec, err := newGoGitPathspec([]string{tt.spec})
+		if err != nil {
+			t.Fatalf("newGoGitPathspec(%q): %v", tt.spec, err)
+		}
+		if got := pathspec.match(tt.path); got != tt.match {
+			t.Errorf("pathspec %q matching %q = %v, want %v", tt.spec, tt.path, got, tt.match)
+		}
+	}
+}
+
+func TestGoGitSubmodules(t *testing.T) {
+	lib := newTestRepo(t)
+	lib.commit("alice", map[string]string{"lib.go": "package lib\n"})
+	repo := newTestRepo(t)
+	repo.commit("bob", map[string]string{"main.go": "package main\n"})
+	repo.git("-c", "protocol.file.allow=always", "submodule", "add", "-q", lib.dir, "vendor/lib")
+	repo.git("remote", "add", "origin", "https://github.com/acme/app.git")
+
+	for _, tt := range []struct {
+		spec string
+		want []string
+	}{
+		{".", []string{"vendor/lib"}},
+		{"vendor", []string{"vendor/lib"}},
+		{"src", nil},
+	} {
+		got, err := goGitSubmodules(repo.dir, []string{tt.spec})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("submodules below %q = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+
+	if url, err := goGitRemoteURL(repo.dir, "origin"); err != nil || url != "https://github.com/acme/app.git" {
+		t.Errorf("origin = %q, %v, want the GitHub URL", url, err)
+	}
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"cmp"
+	"encoding/csv"
+	"io"
+	"slices"
+	"strconv"
+)
+
+// Edge represents a co-contribution between two contributors who changed
+// the same files
+type Edge struct {
+	Source      string
+	Target      string
+	SharedFiles int
+	Weight      int
+}
+
+// contributorLabel returns the label used for a contributor in graph output
+func contributorLabel(contributor *Contributor) string {
+	if contributor.Email != "" {
+		return contributor.Email
+	}
+	return contributor.Name
+}
+
+// buildEdges builds the co-contribution graph from the files each
+// contributor changed. Two contributors are connected when they changed at
+// least one common file. The weight is the sum over shared files of the
+// smaller of the two contributors' line changes to that file. Contributors
+// with the same label, like two names sharing one email, are one node.
+func buildEdges(contributors []*Contributor) []Edge {
+	// Merge the files of the contributors by label
+	nodes := make(map[string]map[string]int)
+	for _, contributor := range contributors {
+		label := contributorLabel(contributor)
+		if nodes[label] == nil {
+			nodes[label] = make(map[string]int)
+		}
+		for file, changes := range contributor.Files {
+			nodes[label][file] += changes
+		}
+	}
+
+	// Collect the set of authors for every file
+	authors := make(map[string][]string)
+	for label, files := range nodes {
+		for file := range files {
+			authors[file] = append(authors[file], label)
+		}
+	}
+
+	type pair struct{ source, target string }
+	edges := make(map[pair]*Edge)
+
+	for file, fileAuthors := range authors {
+		for i := 0; i < len(fileAuthors); i++ {
+			for j := i + 1; j < len(fileAuthors); j++ {
+				source, target := fileAuthors[i], fileAuthors[j]
+				if source > target {
+					source, target = target, source
+				}
+
+				key := pair{source, target}
+				edge, exists := edges[key]
+				if !exists {
+					edge = &Edge{Source: source, Target: target}
+					edges[key] = edge
+				}
+				edge.SharedFiles++
+				edge.Weight += min(nodes[source][file], nodes[target][file])
+			}
+		}
+	}
+
+	result := make([]Edge, 0, len(edges))
+	for _, edge := range edges {
+		result = append(result, *edge)
+	}
+
+	// Strongest connections first, then alphabetically for stable output
+	slices.SortFunc(result, func(a, b Edge) int {
+		return cmp.Or(
+			cmp.Compare(b.Weight, a.Weight),
+			cmp.Compare(a.Source, b.Source),
+			cmp.Compare(a.Target, b.Target),
+		)
+	})
+
+	return result
+}
+
+// displayEdges writes the co-contribution graph as a CSV edge list
+func displayEdges(w io.Writer, report *Report, options renderOptions) error {
+	return writeEdges(w, []*Report{report}, options, false)
+}
+
+// displayEdgesReports writes the co-contribution graphs of several reports
+// as one CSV edge list, with the path of each edge's report in a leading
+// path column
+func displayEdgesReports(w io.Writer, reports []*Report, options renderOptions) error {
+	return writeEdges(w, reports, options, true)
+}
+
+// writeEdges writes the edges of the reports' graphs under one header
+// row, leading with the report's path when pathColumn is set. The row
+// merging the contributors after --top is no node of the graph.
+func writeEdges(w io.Writer, reports []*Report, options renderOptions, pathColumn bool) error {
+	cw := csv.NewWriter(w)
+	if !options.NoHeader {
+		header := []string{"source", "target", "sharedFiles", "weight"}
+		if pathColumn {
+			header = append([]string{"path"}, header...)
+		}
+		cw.Write(header)
+	}
+
+	for _, report := range reports {
+		contributors := slices.DeleteFunc(slices.Clone(report.Contributors), isOthersRow)
+		for _, edge := range buildEdges(contributors) {
+			row := []string{
+				edge.Source,
+				edge.Target,
+				strconv.Itoa(edge.SharedFiles),
+				strconv.Itoa(edge.Weight),
+			}
+			if pathColumn {
+				row = append([]string{report.Path}, row...)
+			}
+			cw.Write(row)
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestBuildEdgesSharedFiles(t *testing.T) {
+	contributors := []*Contributor{
+		{Name: "Alice", Email: "alice@example.com", Files: map[string]int{"a.go": 10, "b.go": 4, "c.go": 7}},
+		{Name: "Bob", Email: "bob@example.com", Files: map[string]int{"a.go": 3, "b.go": 8}},
+		{Name: "Carol", Email: "carol@example.com", Files: map[string]int{"d.go": 5}},
+	}
+
+	edges := buildEdges(contributors)
+	if len(edges) != 1 {
+		t.Fatalf("got %d edges, want 1: %+v", len(edges), edges)
+	}
+	want := Edge{Source: "alice@example.com", Target: "bob@example.com", SharedFiles: 2, Weight: 3 + 4}
+	if edges[0] != want {
+		t.Errorf("got edge %+v, want %+v", edges[0], want)
+	}
+}
+
+func TestBuildEdgesSharedEmail(t *testing.T) {
+	// Contributors are keyed by name and email, so one email can be two
+	// rows, but it is one node of the graph
+	contributors := []*Contributor{
+		{Name: "Alice", Email: "alice@example.com", Files: map[string]int{"a.go": 2, "b.go": 1}},
+		{Name: "Alice Anders", Email: "alice@example.com", Files: map[string]int{"a.go": 3}},
+		{Name: "Bob", Email: "bob@example.com", Files: map[string]int{"a.go": 4, "b.go": 6}},
+	}
+
+	edges := buildEdges(contributors)
+	want := []Edge{{Source: "alice@example.com", Target: "bob@example.com", SharedFiles: 2, Weight: 4 + 1}}
+	if !slices.Equal(edges, want) {
+		t.Errorf("got edges %+v, want %+v", edges, want)
+	}
+}
+
+func TestDisplayEdges(t *testing.T) {
+	report := &Report{Contributors: []*Contributor{
+		{Name: "Bob", Email: "bob@example.com", Files: map[string]int{"a.go": 3}},
+		{Name: "Alice", Email: "alice@example.com", Files: map[string]int{"a.go": 10}},
+		{Name: "Build", Files: map[string]int{"a.go": 1}},
+	}}
+
+	var out bytes.Buffer
+	if err := displayEdges(&out, report, renderOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	want := "source,target,sharedFiles,weight\n" +
+		"alice@example.com,bob@example.com,1,3\n" +
+		"Build,alice@example.com,1,1\n" +
+		"Build,bob@example.com,1,1\n"
+	if out.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestDisplayEdgesNoHeader(t *testing.T) {
+	report := &Report{Contributors: []*Contributor{
+		{Name: "Bob", Email: "bob@example.com", Files: map[string]int{"a.go": 3}},
+		{Name: "Alice", Email: "alice@example.com", Files: map[string]int{"a.go": 10}},
+	}}
+
+	var out bytes.Buffer
+	if err := displayEdges(&out, report, renderOptions{NoHeader: true}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "alice@example.com,bob@example.com,1,3\n"; out.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestDisplayEdgesOthers(t *testing.T) {
+	// --top 2 --others merges Carol into a row that is nobody to connect
+	contributors := limitContributors([]*Contributor{
+		{Name: "Alice", Email: "alice@example.com", Files: map[string]int{"a.go": 10}},
+		{Name: "Bob", Email: "bob@example.com", Files: map[string]int{"a.go": 3}},
+		{Name: "Carol", Email: "carol@example.com", Files: map[string]int{"a.go": 5}},
+	}, 2, true)
+
+	var out bytes.Buffer
+	if err := displayEdges(&out, &Report{Contributors: contributors}, renderOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	want := "source,target,sharedFiles,weight\n" +
+		"alice@example.com,bob@example.com,1,3\n"
+	if out.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestDisplayEdgesOthersNamedPerson(t *testing.T) {
+	// A person without an email named like the others row is still a person
+	contributors := limitContributors([]*Contributor{
+		{Name: "Alice", Email: "alice@example.com", Files: map[string]int{"a.go": 10}},
+		{Name: "(3 others)", Files: map[string]int{"a.go": 4}},
+		{Name: "Carol", Email: "carol@example.com", Files: map[string]int{"b.go": 5}},
+	}, 2, true)
+	if isOthersRow(contributors[1]) || !isOthersRow(contributors[2]) {
+		t.Fatalf("got others rows %v and %v, want only the merged row", contributors[1].Others, contributors[2].Others)
+	}
+
+	var out bytes.Buffer
+	if err := displayEdges(&out, &Report{Contributors: contributors}, renderOptions{NoHeader: true}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "(3 others),alice@example.com,1,4\n"; out.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/PerArneng/gitwho/pkg/gitwho"
+	"github.com/spf13/cobra"
+)
+
+// heatmapDays is the order of the weekday rows, starting on Monday
+var heatmapDays = []time.Weekday{
+	time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday,
+}
+
+var heatmapPerAuthor bool
+var heatmapFormat string
+
+// heatmapCmd represents the heatmap command
+var heatmapCmd = &cobra.Command{
+	Use:   "heatmap [file/directory]",
+	Short: "Show when commits happen by weekday and hour",
+	Long: `Heatmap counts the commits to a file or directory in a grid of weekdays
+and hours of the day, so you can see when changes actually happen. Hours
+are in the author's own timezone. With --per-author a grid is shown for
+every contributor instead of one for everybody.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+		if !isValidTableFormat(heatmapFormat) {
+			fmt.Printf("Error: Invalid format %s. Supported formats: table, csv, json\n", heatmapFormat)
+			os.Exit(1)
+		}
+
+		commits, err := collectCommits([]string{path}, lastTimeRange, repoPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		heatmaps := buildHeatmaps(commits, heatmapPerAuthor)
+		if heatmapFormat != "table" {
+			table := heatmapTable(heatmaps, heatmapPerAuthor, false)
+			if err := displayTable(os.Stdout, heatmapFormat, table, heatmaps); err != nil {
+				fmt.Printf("Error writing output: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		// Print the grids one below the other, with zeros shown as dots
+		for i, h := range heatmaps {
+			if i > 0 {
+				fmt.Println()
+			}
+			if heatmapPerAuthor {
+				fmt.Printf("%s <%s>\n\n", h.Name, h.Email)
+			}
+			if err := displayTable(os.Stdout, "table", heatmapTable([]heatmap{h}, false, true), nil); err != nil {
+				fmt.Printf("Error writing output: %v\n", err)
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+func init() {
+	heatmapCmd.Flags().BoolVar(&heatmapPerAuthor, "per-author", false, "Show a heatmap for every contributor")
+	heatmapCmd.Flags().StringVar(&heatmapFormat, "format", "table", "Output format (table, csv, json)")
+	rootCmd.AddCommand(heatmapCmd)
+}
+
+// heatmap counts commits per weekday (Monday first) and hour of the day
+type heatmap struct {
+	Name  string     `json:"name,omitempty"`
+	Email string     `json:"email,omitempty"`
+	Grid  [7][24]int `json:"grid"`
+}
+
+// buildHeatmaps counts the commits in one grid, or in one grid per
+// contributor in the order of their number of commits
+func buildHeatmaps(commits []*Commit, perAuthor bool) []heatmap {
+	if !perAuthor {
+		var overall heatmap
+		for _, commit := range commits {
+			overall.add(commit.Date)
+		}
+		return []heatmap{overall}
+	}
+
+	contributors := gitwho.Aggregate(commits)
+	sortContributorsBy(contributors, "commits", false)
+	heatmaps := make([]heatmap, len(contributors))
+	for i, contributor := range contributors {
+		heatmaps[i] = heatmap{Name: contributor.Name, Email: contributor.Email}
+		for _, date := range contributor.CommitDates {
+			heatmaps[i].add(date)
+		}
+	}
+	return heatmaps
+}
+
+// add counts a commit made at date
+func (h *heatmap) add(date time.Time) {
+	if date.IsZero() {
+		return
+	}
+	day := (int(date.Weekday()) + 6) % 7
+	h.Grid[day][date.Hour()]++
+}
+
+// heatmapTable lays out one row per weekday with a column per hour. With
+// withAuthor, the rows start with the contributor. With dots, hours without
+// commits are shown as "." to make the busy hours stand out.
+func heatmapTable(heatmaps []heatmap, withAuthor bool, dots bool) textTable {
+	var table textTable
+	if withAuthor {
+		table.Headers = append(table.Headers, "NAME", "EMAIL")
+		table.Numeric = append(table.Numeric, false, false)
+	}
+	table.Headers = append(table.Headers, "DAY")
+	table.Numeric = append(table.Numeric, false)
+	for hour := 0; hour < 24; hour++ {
+		table.Headers = append(table.Headers, fmt.Sprintf("%02d", hour))
+		table.Numeric = append(table.Numeric, true)
+	}
+
+	for _, h := range heatmaps {
+		for i, day := range heatmapDays {
+			var row []string
+			if withAuthor {
+				row = append(row, h.Name, h.Email)
+			}
+			row = append(row, day.String()[:3])
+			for _, count := range h.Grid[i] {
+				cell := strconv.Itoa(count)
+				if count == 0 && dots {
+					cell = "."
+				}
+				row = append(row, cell)
+			}
+			table.Rows = append(table.Rows, row)
+		}
+	}
+	return table
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var hotspotsTop int
+var hotspotsFormat string
+
+// hotspotsCmd represents the hotspots command
+var hotspotsCmd = &cobra.Command{
+	Use:   "hotspots [file/directory]",
+	Short: "Rank files by churn and number of contributors",
+	Long: `Hotspots ranks the files changed within the time range by their churn
+(added plus deleted lines) multiplied by the number of distinct
+contributors. Files that change a lot and are shared by many people are
+the riskiest to work on and come first.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+		if !isValidTableFormat(hotspotsFormat) {
+			fmt.Printf("Error: Invalid format %s. Supported formats: table, csv, json\n", hotspotsFormat)
+			os.Exit(1)
+		}
+
+		commits, err := collectCommits([]string{path}, lastTimeRange, repoPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		hotspots := buildHotspots(buildFileStats(commits))
+		if hotspotsTop > 0 && len(hotspots) > hotspotsTop {
+			hotspots = hotspots[:hotspotsTop]
+		}
+		if err := displayTable(os.Stdout, hotspotsFormat, hotspotTable(hotspots), hotspots); err != nil {
+			fmt.Printf("Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	hotspotsCmd.Flags().IntVar(&hotspotsTop, "top", 20, "Number of files to show (0 for all)")
+	hotspotsCmd.Flags().StringVar(&hotspotsFormat, "format", "table", "Output format (table, csv, json)")
+	rootCmd.AddCommand(hotspotsCmd)
+}
+
+// hotspot is a file ranked by its churn times its number of contributors
+type hotspot struct {
+	ownershipStats
+	Score int `json:"score"`
+}
+
+// buildHotspots scores the files and ranks them, highest score first
+func buildHotspots(files []ownershipStats) []hotspot {
+	hotspots := make([]hotspot, len(files))
+	for i, file := range files {
+		hotspots[i] = hotspot{file, file.Churn * file.Contributors}
+	}
+
+	slices.SortFunc(hotspots, func(a, b hotspot) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.Path, b.Path))
+	})
+	return hotspots
+}
+
+// hotspotTable lays out one row per file with its score
+func hotspotTable(hotspots []hotspot) textTable {
+	table := textTable{
+		Headers: []string{"FILE", "SCORE", "CHURN", "AUTHORS", "COMMITS", "TOP CONTRIBUTOR"},
+		Numeric: []bool{false, true, true, true, true, false},
+	}
+	for _, h := range hotspots {
+		table.Rows = append(table.Rows, []string{
+			h.Path,
+			strconv.Itoa(h.Score),
+			strconv.Itoa(h.Churn),
+			strconv.Itoa(h.Contributors),
+			strconv.Itoa(h.Commits),
+			h.Owner,
+		})
+	}
+	return table
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"strings"
+)
+
+// noreplyDomain is the domain of the private commit emails GitHub hands out
+const noreplyDomain = "@users.noreply.github.com"
+
+var normalizeEmails bool
+var mergeNoreply bool
+
+// normalizeEmail lowercases an email and strips a +tag from its local part,
+// so jane+work@Example.com becomes jane@example.com. GitHub noreply
+// addresses are only lowercased, their + separates the user id.
+func normalizeEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if strings.HasSuffix(email, noreplyDomain) {
+		return email
+	}
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return email
+	}
+	if i := strings.Index(local, "+"); i > 0 {
+		local = local[:i]
+	}
+	return local + "@" + domain
+}
+
+// noreplyUser returns the GitHub user name of a noreply address, which is
+// either user@ or id+user@users.noreply.github.com
+func noreplyUser(email string) (string, bool) {
+	local, ok := strings.CutSuffix(strings.ToLower(email), noreplyDomain)
+	if !ok {
+		return "", false
+	}
+	if _, user, found := strings.Cut(local, "+"); found {
+		return user, true
+	}
+	return local, true
+}
+
+// normalizeIdentities applies --normalize-emails and --merge-noreply to the
+// authors of the commits
+func normalizeIdentities(commits []*Commit) []*Commit {
+	if normalizeEmails {
+		for _, commit := range commits {
+			commit.Email = normalizeEmail(commit.Email)
+		}
+	}
+	if mergeNoreply {
+		mergeNoreplyEmails(commits)
+	}
+	return commits
+}
+
+// mergeNoreplyEmails replaces GitHub noreply addresses with the primary
+// address of the same person: the address most used in other commits by
+// the same author name or by an address whose local part is the GitHub
+// user name
+func mergeNoreplyEmails(commits []*Commit) {
+	byName := make(map[string]map[string]int)
+	byLocal := make(map[string]map[string]int)
+	count := func(index map[string]map[string]int, key string, email string) {
+		if index[key] == nil {
+			index[key] = make(map[string]int)
+		}
+		index[key][email]++
+	}
+	for _, commit := range commits {
+		if _, ok := noreplyUser(commit.Email); ok || commit.Email == "" {
+			continue
+		}
+		local, _, _ := strings.Cut(strings.ToLower(commit.Email), "@")
+		count(byName, commit.Name, commit.Email)
+		count(byLocal, local, commit.Email)
+	}
+
+	for _, commit := range commits {
+		user, ok := noreplyUser(commit.Email)
+		if !ok {
+			continue
+		}
+		if primary := mostUsed(byName[commit.Name]); primary != "" {
+			commit.Email = primary
+		} else if primary := mostUsed(byLocal[user]); primary != "" {
+			commit.Email = primary
+		}
+	}
+}
+
+// mostUsed returns the email with the highest count, preferring the
+// alphabetically first one on ties
+func mostUsed(counts map[string]int) string {
+	best := ""
+	for email, n := range counts {
+		if best == "" || n > counts[best] || (n == counts[best] && email < best) {
+			best = email
+		}
+	}
+	return best
+}
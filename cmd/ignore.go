@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/PerArneng/gitwho/pkg/gitwho"
+)
+
+// ignoreFileName is the file at the repository root listing paths to leave
+// out of the statistics
+const ignoreFileName = ".gitwhoignore"
+
+// newPathMatcher compiles gitignore-style patterns. Empty patterns and
+// patterns starting with "#" are ignored.
+func newPathMatcher(patterns []string) (*gitwho.PathMatcher, error) {
+	matcher, err := gitwho.NewPathMatcher(patterns)
+	if err != nil {
+		return nil, fmt.Errorf("Error: %v", err)
+	}
+	return matcher, nil
+}
+
+// vendoredAttributes are the gitattributes that mark files as third-party
+// or generated code
+var vendoredAttributes = []string{"linguist-vendored", "linguist-generated"}
+
+// loadIgnoreFile reads the .gitwhoignore file at the repository root. It
+// returns nil when the repository has none.
+func loadIgnoreFile(root string) (*gitwho.PathMatcher, error) {
+	data, err := os.ReadFile(filepath.Join(root, ignoreFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error reading %s: %v", ignoreFileName, err)
+	}
+
+	matcher, err := newPathMatcher(strings.Split(string(data), "\n"))
+	if err != nil {
+		return nil, err
+	}
+	debugf("Loaded %d patterns from %s\n", matcher.Len(), ignoreFileName)
+	return matcher, nil
+}
+
+// vendoredPaths asks git which of the paths have a linguist-vendored or
+// linguist-generated attribute set in .gitattributes
+func vendoredPaths(root string, paths []string) (map[string]bool, error) {
+	vendored := make(map[string]bool)
+	if len(paths) == 0 {
+		return vendored, nil
+	}
+	if usesGoGit() {
+		return goGitVendoredPaths(root, paths)
+	}
+
+	args := append([]string{"-C", root, "check-attr", "-z", "--stdin"}, vendoredAttributes...)
+	output, err := runGitInput(strings.Join(paths, "\x00"), nil, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading .gitattributes: %v", err)
+	}
+
+	// The output is a sequence of NUL separated path, attribute, value triples
+	fields := strings.Split(output, "\x00")
+	for i := 0; i+2 < len(fields); i += 3 {
+		if value := fields[i+2]; value == "set" || value == "true" {
+			vendored[fields[i]] = true
+		}
+	}
+	return vendored, nil
+}
+
+// excludeVendored drops the file changes git reports as vendored or
+// generated through .gitattributes
+func excludeVendored(commits []*Commit, root string) ([]*Commit, error) {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, commit := range commits {
+		for _, change := range commit.Files {
+			if !seen[change.Path] {
+				seen[change.Path] = true
+				paths = append(paths, change.Path)
+			}
+		}
+	}
+
+	vendored, err := vendoredPaths(root, paths)
+	if err != nil {
+		return nil, err
+	}
+	if len(vendored) == 0 {
+		return commits, nil
+	}
+
+	return dropFiles(commits, func(path string) bool { return vendored[path] }), nil
+}
+
+// excludeFiles drops the file changes whose paths match the matcher
+func excludeFiles(commits []*Commit, matcher *gitwho.PathMatcher) []*Commit {
+	return dropFiles(commits, matcher.Match)
+}
+
+// dropFiles removes the file changes for which drop returns true
+func dropFiles(commits []*Commit, drop func(path string) bool) []*Commit {
+	for _, commit := range commits {
+		var kept []FileChange
+		for _, change := range commit.Files {
+			if !drop(change.Path) {
+				kept = append(kept, change)
+			}
+		}
+		commit.Files = kept
+	}
+	return commits
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/PerArneng/gitwho/pkg/gitwho"
+	"github.com/spf13/cobra"
+)
+
+// languageNames maps file extensions to language names
+var languageNames = map[string]string{
+	".c":      "C",
+	".h":      "C",
+	".cc":     "C++",
+	".cpp":    "C++",
+	".hpp":    "C++",
+	".cs":     "C#",
+	".css":    "CSS",
+	".scss":   "CSS",
+	".go":     "Go",
+	".html":   "HTML",
+	".java":   "Java",
+	".js":     "JavaScript",
+	".jsx":    "JavaScript",
+	".mjs":    "JavaScript",
+	".json":   "JSON",
+	".kt":     "Kotlin",
+	".lua":    "Lua",
+	".md":     "Markdown",
+	".php":    "PHP",
+	".proto":  "Protocol Buffers",
+	".py":     "Python",
+	".rb":     "Ruby",
+	".rs":     "Rust",
+	".rst":    "reStructuredText",
+	".scala":  "Scala",
+	".sh":     "Shell",
+	".bash":   "Shell",
+	".sql":    "SQL",
+	".swift":  "Swift",
+	".tf":     "Terraform",
+	".tfvars": "Terraform",
+	".toml":   "TOML",
+	".ts":     "TypeScript",
+	".tsx":    "TypeScript",
+	".txt":    "Text",
+	".xml":    "XML",
+	".yaml":   "YAML",
+	".yml":    "YAML",
+}
+
+// fileNameLanguages maps well-known file names without a telling extension
+// to language names
+var fileNameLanguages = map[string]string{
+	"Dockerfile":  "Dockerfile",
+	"Makefile":    "Makefile",
+	"go.mod":      "Go Module",
+	"go.sum":      "Go Module",
+	"Jenkinsfile": "Groovy",
+}
+
+var languagesFormat string
+
+// languagesCmd represents the languages command
+var languagesCmd = &cobra.Command{
+	Use:   "languages [file/directory]",
+	Short: "Show contributor statistics per language",
+	Long: `Languages groups the additions and deletions in a file or directory by
+language, detected from the file extension, and lists the contributors to
+each language. Languages with the most changed lines come first.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+		if !isValidTableFormat(languagesFormat) {
+			fmt.Printf("Error: Invalid format %s. Supported formats: table, csv, json\n", languagesFormat)
+			os.Exit(1)
+		}
+
+		commits, err := collectCommits([]string{path}, lastTimeRange, repoPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		languages := buildLanguageStats(commits)
+		if err := displayTable(os.Stdout, languagesFormat, languageTable(languages), languages); err != nil {
+			fmt.Printf("Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	languagesCmd.Flags().StringVar(&languagesFormat, "format", "table", "Output format (table, csv, json)")
+	rootCmd.AddCommand(languagesCmd)
+}
+
+// languageStats holds the changes to files of one language
+type languageStats struct {
+	Language     string              `json:"language"`
+	Additions    int                 `json:"additions"`
+	Deletions    int                 `json:"deletions"`
+	Contributors []contributorRecord `json:"contributors"`
+}
+
+// languageOf returns the language of a file from its name or extension
+func languageOf(file string) string {
+	base := path.Base(file)
+	if language, ok := fileNameLanguages[base]; ok {
+		return language
+	}
+	ext := strings.ToLower(path.Ext(base))
+	if language, ok := languageNames[ext]; ok {
+		return language
+	}
+	if ext != "" {
+		return ext
+	}
+	return "Other"
+}
+
+// buildLanguageStats splits the commits by the language of the changed
+// files and aggregates the contributors of each language
+func buildLanguageStats(commits []*Commit) []languageStats {
+	byLanguage := splitCommits(commits, languageOf)
+
+	var languages []languageStats
+	for language, languageCommits := range byLanguage {
+		contributors := gitwho.Aggregate(languageCommits)
+		gitwho.ComputeShares(contributors)
+		sortContributorsBy(contributors, sortField, reverseSort)
+
+		stats := languageStats{Language: language}
+		for _, contributor := range contributors {
+			stats.Additions += contributor.Additions
+			stats.Deletions += contributor.Deletions
+		}
+		stats.Contributors = buildReportDocument(&Report{Contributors: contributors}, newRenderOptions(false)).Contributors
+		languages = append(languages, stats)
+	}
+
+	// Languages with the most changes first
+	slices.SortFunc(languages, func(a, b languageStats) int {
+		if d := (b.Additions + b.Deletions) - (a.Additions + a.Deletions); d != 0 {
+			return d
+		}
+		return strings.Compare(a.Language, b.Language)
+	})
+	return languages
+}
+
+// languageTable lays out one row per language and contributor
+func languageTable(languages []languageStats) textTable {
+	table := textTable{
+		Headers: []string{"LANGUAGE", "NAME", "EMAIL", "COMMITS", "ADDED", "DELETED", "TOTAL", "SHARE"},
+		Numeric: []bool{false, false, false, true, true, true, true, true},
+	}
+	for _, language := range languages {
+		for _, record := range language.Contributors {
+			table.Rows = append(table.Rows, []string{
+				language.Language,
+				record.Name,
+				record.Email,
+				strconv.Itoa(record.Commits),
+				strconv.Itoa(record.Additions),
+				strconv.Itoa(record.Deletions),
+				strconv.Itoa(record.Total),
+				formatPercent(record.ChangeShare),
+			})
+		}
+	}
+	return table
+}
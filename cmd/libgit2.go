@@ -0,0 +1,412 @@
+//go:build libgit2
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/PerArneng/gitwho/pkg/gitwho"
+	git "github.com/libgit2/git2go/v34"
+)
+
+func init() {
+	commitSources["libgit2"] = func() CommitSource { return libgit2Source{} }
+}
+
+// libgit2Source reads the history in-process with libgit2
+type libgit2Source struct{}
+
+func (libgit2Source) Commits(ctx context.Context, query gitwho.Query) ([]*Commit, error) {
+	commits, err := libgit2Log(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading the history with libgit2: %v", err)
+	}
+	return commits, nil
+}
+
+// Unsupported returns --git-arg, the only flag libgit2 cannot honor
+func (libgit2Source) Unsupported() string {
+	if len(extraGitArgs) > 0 {
+		return "--git-arg"
+	}
+	return ""
+}
+
+// libgit2Log returns the commits the query selects, as gitwho.GitBackend
+// would return them. The commits are walked and diffed in-process with
+// libgit2, which saves spawning git and parsing its output on very large
+// repositories. Repository discovery, attributes and trailer identities
+// are still resolved with git. The walk stops when ctx is cancelled.
+func libgit2Log(ctx context.Context, query gitwho.Query) ([]*Commit, error) {
+	repo, err := git.OpenRepository(query.Repo)
+	if err != nil {
+		return nil, err
+	}
+	defer repo.Free()
+	// libgit2 before 1.7 fails on the parents missing from a shallow clone
+	if shallow, _ := repo.IsShallow(); shallow {
+		return nil, fmt.Errorf("the libgit2 backend cannot read shallow clones, pass --fetch-full-history to fetch their full history")
+	}
+
+	since, until := query.Since, query.Until
+	pathspec, err := newGoGitPathspec(query.Paths)
+	if err != nil {
+		return nil, err
+	}
+	mailmap, err := loadLibgit2Mailmap(repo, query.Mailmap)
+	if err != nil {
+		return nil, err
+	}
+
+	diffOptions, err := libgit2DiffOptions(query)
+	if err != nil {
+		return nil, err
+	}
+	findOptions, err := libgit2FindOptions(query)
+	if err != nil {
+		return nil, err
+	}
+
+	walk, err := libgit2Walk(repo, query)
+	if err != nil {
+		return nil, err
+	}
+	defer walk.Free()
+
+	followed := ""
+	if query.Follow && len(query.Paths) == 1 {
+		followed = query.Paths[0]
+	}
+
+	var commits []*Commit
+	var walkErr error
+	err = walk.Iterate(func(c *git.Commit) bool {
+		defer c.Free()
+		if ctx.Err() != nil {
+			walkErr = interruption(ctx)
+			return false
+		}
+		when := c.Committer().When
+		if !since.IsZero() && when.Before(since) || !until.IsZero() && !when.Before(until) {
+			return true
+		}
+
+		var parents []*git.Commit
+		for i := uint(0); i < c.ParentCount(); i++ {
+			if parent := c.Parent(i); parent != nil {
+				parents = append(parents, parent)
+			}
+		}
+		defer func() {
+			for _, parent := range parents {
+				parent.Free()
+			}
+		}()
+		isMerge := len(parents) > 1
+		if isMerge && query.NoMerges {
+			return true
+		}
+
+		commit, err := libgit2Commit(c, mailmap, query.Signed)
+		if err != nil {
+			walkErr = err
+			return false
+		}
+		if isMerge && !query.FirstParent {
+			// git log lists merges that changed the paths compared to every
+			// parent, without a diff
+			if followed != "" {
+				return true
+			}
+			for _, parent := range parents {
+				same, err := libgit2Treesame(repo, parent, c, pathspec, diffOptions)
+				if err != nil {
+					walkErr = err
+					return false
+				}
+				if same {
+					return true
+				}
+			}
+			commits = append(commits, commit)
+			return true
+		}
+
+		var parent *git.Commit
+		if len(parents) > 0 {
+			parent = parents[0]
+		}
+		files, renamedFrom, err := libgit2Changes(repo, parent, c, pathspec, followed, diffOptions, findOptions)
+		if err != nil {
+			walkErr = err
+			return false
+		}
+		if len(files) == 0 {
+			return true
+		}
+		commit.Files = files
+		// Older commits touched the file under its previous name
+		if followed != "" && renamedFrom != "" {
+			followed = renamedFrom
+		}
+		commits = append(commits, commit)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if walkErr != nil {
+		return nil, walkErr
+	}
+	if query.Signed {
+		if err := verifySignatures(ctx, query.Repo, commits); err != nil {
+			return nil, err
+		}
+	}
+	return commits, nil
+}
+
+// libgit2Walk returns a walk over the commits of the query's revisions, or
+// HEAD when there are none, newest first by commit date. With FirstParent
+// only the first parents of merges are followed.
+func libgit2Walk(repo *git.Repository, query gitwho.Query) (*git.RevWalk, error) {
+	walk, err := repo.Walk()
+	if err != nil {
+		return nil, err
+	}
+	walk.Sorting(git.SortTime)
+	if query.FirstParent {
+		walk.SimplifyFirstParent()
+	}
+
+	revisions := query.Revisions
+	if len(revisions) == 0 {
+		revisions = []string{"HEAD"}
+	}
+	for _, rev := range revisions {
+		switch {
+		case rev == "--all":
+			err = walk.PushGlob("*")
+			if err == nil {
+				// A detached HEAD is not on any ref
+				walk.PushHead()
+			}
+		case strings.Contains(rev, "..."):
+			err = fmt.Errorf("the libgit2 backend does not support symmetric ranges like %s", rev)
+		case strings.Contains(rev, ".."):
+			err = walk.PushRange(rev)
+		default:
+			var object *git.Object
+			if object, err = repo.RevparseSingle(rev); err == nil {
+				var commit *git.Object
+				if commit, err = object.Peel(git.ObjectCommit); err == nil {
+					err = walk.Push(commit.Id())
+				}
+			}
+		}
+		if err != nil {
+			walk.Free()
+			return nil, fmt.Errorf("unknown revision %s: %v", rev, err)
+		}
+	}
+	return walk, nil
+}
+
+// libgit2Commit converts a commit's metadata, resolving its identities
+// through the mailmap like %aN, %aE, %cN and %cE do, and telling whether
+// it is signed when signed is set
+func libgit2Commit(c *git.Commit, mailmap *goGitMailmap, signed bool) (*Commit, error) {
+	author, committer := c.Author(), c.Committer()
+	commit := &Commit{Hash: c.Id().String(), Date: author.When, CommitDate: committer.When, Subject: c.Summary()}
+	commit.Name, commit.Email = mailmap.resolve(author.Name, author.Email)
+	commit.CommitterName, commit.CommitterEmail = mailmap.resolve(committer.Name, committer.Email)
+
+	trailers, err := git.MessageTrailers(c.Message())
+	if err != nil {
+		return nil, err
+	}
+	for _, trailer := range trailers {
+		value := strings.Join(strings.Fields(trailer.Value), " ")
+		switch strings.ToLower(trailer.Key) {
+		case "co-authored-by":
+			commit.CoAuthors = append(commit.CoAuthors, value)
+		case "reviewed-by":
+			commit.Reviewers = append(commit.Reviewers, value)
+		case "signed-off-by":
+			commit.SignOffs = append(commit.SignOffs, value)
+		}
+	}
+	if signed {
+		// git verifies the signatures found, see verifySignatures
+		_, _, err := c.ExtractSignature()
+		commit.Signed = err == nil
+	}
+	return commit, nil
+}
+
+// loadLibgit2Mailmap reads the .mailmap at the root of the work tree, or in
+// HEAD of a bare repository, followed by the mailmap file if there is one.
+// git2go has no bindings for libgit2's mailmap, so it is parsed like the
+// go-git backend does.
+func loadLibgit2Mailmap(repo *git.Repository, file string) (*goGitMailmap, error) {
+	var data []byte
+	if !repo.IsBare() {
+		data, _ = os.ReadFile(filepath.Join(repo.Workdir(), ".mailmap"))
+	} else if object, err := repo.RevparseSingle("HEAD:.mailmap"); err == nil {
+		defer object.Free()
+		if blob, err := object.AsBlob(); err == nil {
+			data = blob.Contents()
+		}
+	}
+	extra, err := readMailmapFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return parseMailmap(string(data) + "\n" + extra), nil
+}
+
+// libgit2DiffOptions returns the options for diffing commits, ignoring
+// whitespace when the query does and including unchanged files to copy
+// from when it detects copies, like --find-copies-harder
+func libgit2DiffOptions(query gitwho.Query) (git.DiffOptions, error) {
+	options, err := git.DefaultDiffOptions()
+	if err != nil {
+		return options, err
+	}
+	if query.IgnoreWhitespace {
+		options.Flags |= git.DiffIgnoreWhitespace
+	}
+	if query.CopySimilarity > 0 {
+		options.Flags |= git.DiffIncludeUnmodified
+	}
+	return options, nil
+}
+
+// libgit2FindOptions returns the rename and copy detection of git log:
+// renames that are 50% similar, or as similar as the query asks, and
+// copies when the query asks for them
+func libgit2FindOptions(query gitwho.Query) (git.DiffFindOptions, error) {
+	options, err := git.DefaultDiffFindOptions()
+	if err != nil {
+		return options, err
+	}
+	options.Flags = git.DiffFindRenames
+	options.RenameThreshold = 50
+	if query.RenameSimilarity > 0 {
+		options.RenameThreshold = uint16(query.RenameSimilarity)
+	}
+	if query.CopySimilarity > 0 {
+		options.Flags |= git.DiffFindCopies | git.DiffFindCopiesFromUnmodified
+		options.CopyThreshold = uint16(query.CopySimilarity)
+	}
+	return options, nil
+}
+
+// libgit2Diff diffs the trees of a commit and its parent, or all files of
+// a root commit, keeping only the files for which keep returns true
+func libgit2Diff(repo *git.Repository, parent *git.Commit, commit *git.Commit, options git.DiffOptions, keep func(path string) bool) (*git.Diff, error) {
+	var from *git.Tree
+	if parent != nil {
+		var err error
+		if from, err = parent.Tree(); err != nil {
+			return nil, err
+		}
+		defer from.Free()
+	}
+	to, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	defer to.Free()
+
+	options.NotifyCallback = func(_ *git.Diff, delta git.DiffDelta, _ string) error {
+		if keep(delta.OldFile.Path) || keep(delta.NewFile.Path) {
+			return nil
+		}
+		return git.ErrDeltaSkip
+	}
+	return repo.DiffTreeToTree(from, to, &options)
+}
+
+// libgit2Treesame reports whether a commit left the paths of the pathspec
+// as its parent had them
+func libgit2Treesame(repo *git.Repository, parent *git.Commit, commit *git.Commit, pathspec *goGitPathspec, options git.DiffOptions) (bool, error) {
+	diff, err := libgit2Diff(repo, parent, commit, options, pathspec.match)
+	if err != nil {
+		return false, err
+	}
+	defer diff.Free()
+	deltas, err := diff.NumDeltas()
+	return deltas == 0, err
+}
+
+// libgit2Changes counts the lines added and deleted in the files of a
+// commit that match the pathspec or, when following a file, in that file,
+// like git's numstat. Renames are detected among the matched files, or the
+// whole tree when following a file. renamedFrom is the previous name of a
+// followed file that the commit renamed.
+func libgit2Changes(repo *git.Repository, parent *git.Commit, commit *git.Commit, pathspec *goGitPathspec, followed string, options git.DiffOptions, find git.DiffFindOptions) (files []FileChange, renamedFrom string, err error) {
+	keep := pathspec.match
+	if followed != "" {
+		keep = func(string) bool { return true }
+	}
+	diff, err := libgit2Diff(repo, parent, commit, options, keep)
+	if err != nil {
+		return nil, "", err
+	}
+	defer diff.Free()
+	if err := diff.FindSimilar(&find); err != nil {
+		return nil, "", err
+	}
+
+	// The indexes of the deltas in files, -1 for skipped ones
+	var indexes []int
+	err = diff.ForEach(func(delta git.DiffDelta, _ float64) (git.DiffForEachHunkCallback, error) {
+		if delta.Status == git.DeltaUnmodified || followed != "" && delta.NewFile.Path != followed {
+			indexes = append(indexes, -1)
+			return func(git.DiffHunk) (git.DiffForEachLineCallback, error) {
+				return func(git.DiffLine) error { return nil }, nil
+			}, nil
+		}
+		if followed != "" && delta.Status == git.DeltaRenamed {
+			renamedFrom = delta.OldFile.Path
+		}
+
+		i := len(files)
+		indexes = append(indexes, i)
+		files = append(files, FileChange{Path: delta.NewFile.Path})
+		return func(git.DiffHunk) (git.DiffForEachLineCallback, error) {
+			return func(line git.DiffLine) error {
+				switch line.Origin {
+				case git.DiffLineAddition:
+					files[i].Additions++
+				case git.DiffLineDeletion:
+					files[i].Deletions++
+				}
+				return nil
+			}, nil
+		}, nil
+	}, git.DiffDetailLines)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Binary files are known once their content was loaded
+	for n, i := range indexes {
+		if i < 0 {
+			continue
+		}
+		delta, err := diff.Delta(n)
+		if err != nil {
+			return nil, "", err
+		}
+		if delta.Flags&git.DiffFlagBinary != 0 {
+			files[i] = FileChange{Path: files[i].Path, Binary: true}
+		}
+	}
+	return files, renamedFrom, nil
+}
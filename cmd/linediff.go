@@ -0,0 +1,433 @@
+package cmd
+
+import "strings"
+
+// The tuning of git's diff, from its xdiff library
+const (
+	xdlMaxCostMin  = 256                // edit cost from which a split gives up on a minimal path
+	xdlHeurMinCost = 256                // edit cost from which a split looks for a good snake
+	xdlSnakeCount  = 20                 // length of a good snake
+	xdlKHeur       = 4                  // how far a good snake must have got per edit
+	xdlMaxEqLimit  = 1024               // cap on the matches making a line a multimatch
+	xdlSimScan     = 100                // lines scanned around a multimatch line
+	xdlKeepRun     = 4                  // ratio of multimatch lines to discard them in a run
+	xdlLineMax     = int(^uint(0) >> 1) // beyond any line
+	trimBlockSize  = 1024               // block size of trimming the common tail
+)
+
+// lineDiff counts the lines added and deleted between two versions of a
+// file exactly like git diff --numstat does with its default algorithm.
+// That is Myers' diff as git's xdiff implements it, including the steps
+// that make it differ from a minimal diff: the common tail is trimmed in
+// blocks, lines that match too often are discarded in runs of unmatched
+// lines, and long searches settle for a good enough split. With
+// ignoreWhitespace lines are compared without their whitespace, like
+// --ignore-all-space.
+func lineDiff(old string, new string, ignoreWhitespace bool) (additions int, deletions int) {
+	old, new = trimCommonTail(old, new)
+
+	classes := make(map[string]int)
+	var counts [][2]int
+	classify := func(text string, side int) []int {
+		lines := textLines(text)
+		ids := make([]int, len(lines))
+		for i, line := range lines {
+			if ignoreWhitespace {
+				line = gitSpaceRemover.Replace(line)
+			}
+			id, ok := classes[line]
+			if !ok {
+				id = len(counts)
+				classes[line] = id
+				counts = append(counts, [2]int{})
+			}
+			counts[id][side]++
+			ids[i] = id
+		}
+		return ids
+	}
+	a := &xdFile{recs: classify(old, 0)}
+	b := &xdFile{recs: classify(new, 1)}
+	a.rchg = make([]bool, len(a.recs))
+	b.rchg = make([]bool, len(b.recs))
+
+	trimEnds(a, b)
+	a.cleanup(func(id int) int { return counts[id][1] })
+	b.cleanup(func(id int) int { return counts[id][0] })
+
+	ndiags := len(a.ha) + len(b.ha) + 3
+	d := &xdDiff{
+		a:      a,
+		b:      b,
+		kvdf:   make([]int, ndiags),
+		kvdb:   make([]int, ndiags),
+		offset: len(b.ha) + 1,
+		mxcost: max(bogoSqrt(ndiags), xdlMaxCostMin),
+	}
+	d.compare(0, len(a.ha), 0, len(b.ha), false)
+
+	for _, changed := range b.rchg {
+		if changed {
+			additions++
+		}
+	}
+	for _, changed := range a.rchg {
+		if changed {
+			deletions++
+		}
+	}
+	return additions, deletions
+}
+
+// gitSpaceRemover removes the characters git takes as whitespace. Git's
+// isspace is not C's: \v and \f are no whitespace to git log -w.
+var gitSpaceRemover = strings.NewReplacer(" ", "", "\t", "", "\n", "", "\r", "")
+
+// trimCommonTail drops the tail both versions share in whole blocks, up
+// to the first line end in it, as git does before diffing without context
+func trimCommonTail(a string, b string) (string, string) {
+	trimmed := 0
+	for trimmed+trimBlockSize <= min(len(a), len(b)) &&
+		a[len(a)-trimmed-trimBlockSize:len(a)-trimmed] == b[len(b)-trimmed-trimBlockSize:len(b)-trimmed] {
+		trimmed += trimBlockSize
+	}
+	recovered := 0
+	for recovered < trimmed {
+		recovered++
+		if a[len(a)-trimmed+recovered-1] == '\n' {
+			break
+		}
+	}
+	return a[:len(a)-trimmed+recovered], b[:len(b)-trimmed+recovered]
+}
+
+// textLines splits text into its lines, each with its line end. A last
+// line without one differs from the same line with one.
+func textLines(text string) []string {
+	var lines []string
+	for text != "" {
+		end := strings.IndexByte(text, '\n') + 1
+		if end == 0 {
+			end = len(text)
+		}
+		lines = append(lines, text[:end])
+		text = text[end:]
+	}
+	return lines
+}
+
+// xdFile is one side of a diff: the classes of its lines, which lines
+// changed, and the lines left to compare after the preparation
+type xdFile struct {
+	recs         []int  // class of each line, equal for equal lines
+	rchg         []bool // whether each line was added or deleted
+	dstart, dend int    // lines between the common head and tail
+
+	ha     []int // classes of the lines left to compare
+	rindex []int // line of each of them
+}
+
+// trimEnds skips the lines at the start and the end both sides share
+func trimEnds(a *xdFile, b *xdFile) {
+	limit := min(len(a.recs), len(b.recs))
+	i := 0
+	for i < limit && a.recs[i] == b.recs[i] {
+		i++
+	}
+	a.dstart, b.dstart = i, i
+
+	limit -= i
+	i = 0
+	for i < limit && a.recs[len(a.recs)-1-i] == b.recs[len(b.recs)-1-i] {
+		i++
+	}
+	a.dend = len(a.recs) - i - 1
+	b.dend = len(b.recs) - i - 1
+}
+
+// cleanup marks the lines that have no match on the other side as
+// changed, and the lines that match too often when they are surrounded by
+// unmatched ones, and leaves the rest to compare. matches returns the
+// number of lines of a class on the other side.
+func (f *xdFile) cleanup(matches func(id int) int) {
+	limit := min(bogoSqrt(len(f.recs)), xdlMaxEqLimit)
+	dis := make([]byte, len(f.recs)+1)
+	for i := f.dstart; i <= f.dend; i++ {
+		switch n := matches(f.recs[i]); {
+		case n == 0:
+			dis[i] = 0
+		case n >= limit:
+			dis[i] = 2
+		default:
+			dis[i] = 1
+		}
+	}
+	for i := f.dstart; i <= f.dend; i++ {
+		if dis[i] == 1 || dis[i] == 2 && !discardMultimatch(dis, i, f.dstart, f.dend) {
+			f.rindex = append(f.rindex, i)
+			f.ha = append(f.ha, f.recs[i])
+		} else {
+			f.rchg[i] = true
+		}
+	}
+}
+
+// discardMultimatch reports whether the multimatch line i sits in a run
+// of mostly unmatched lines, within the lines s to e
+func discardMultimatch(dis []byte, i int, s int, e int) bool {
+	s = max(s, i-xdlSimScan)
+	e = min(e, i+xdlSimScan)
+
+	unmatched, multi := 0, 1
+	for r := 1; i-r >= s; r++ {
+		if dis[i-r] == 0 {
+			unmatched++
+		} else if dis[i-r] == 2 {
+			multi++
+		} else {
+			break
+		}
+	}
+	if unmatched == 0 {
+		return false
+	}
+	unmatchedAfter, multiAfter := 0, 1
+	for r := 1; i+r <= e; r++ {
+		if dis[i+r] == 0 {
+			unmatchedAfter++
+		} else if dis[i+r] == 2 {
+			multiAfter++
+		} else {
+			break
+		}
+	}
+	if unmatchedAfter == 0 {
+		return false
+	}
+	unmatched += unmatchedAfter
+	multi += multiAfter
+	return multi*xdlKeepRun < multi+unmatched
+}
+
+// bogoSqrt approximates the square root of n with a power of two, like
+// git's xdl_bogosqrt
+func bogoSqrt(n int) int {
+	i := 1
+	for ; n > 0; n >>= 2 {
+		i <<= 1
+	}
+	return i
+}
+
+// xdDiff runs Myers' divide and conquer over the lines left to compare
+type xdDiff struct {
+	a, b       *xdFile
+	kvdf, kvdb []int // furthest reaching paths by diagonal, forward and backward
+	offset     int   // index of diagonal 0 in kvdf and kvdb
+	mxcost     int   // edit cost after which a split takes the furthest path
+}
+
+// compare marks the changed lines between the lines off1 to lim1 of a and
+// off2 to lim2 of b. needMin asks for a minimal diff of the box.
+func (d *xdDiff) compare(off1 int, lim1 int, off2 int, lim2 int, needMin bool) {
+	ha1, ha2 := d.a.ha, d.b.ha
+	for off1 < lim1 && off2 < lim2 && ha1[off1] == ha2[off2] {
+		off1++
+		off2++
+	}
+	for off1 < lim1 && off2 < lim2 && ha1[lim1-1] == ha2[lim2-1] {
+		lim1--
+		lim2--
+	}
+
+	switch {
+	case off1 == lim1:
+		for ; off2 < lim2; off2++ {
+			d.b.rchg[d.b.rindex[off2]] = true
+		}
+	case off2 == lim2:
+		for ; off1 < lim1; off1++ {
+			d.a.rchg[d.a.rindex[off1]] = true
+		}
+	default:
+		i1, i2, minLo, minHi := d.split(off1, lim1, off2, lim2, needMin)
+		d.compare(off1, i1, off2, i2, minLo)
+		d.compare(i1, lim1, i2, lim2, minHi)
+	}
+}
+
+// split returns the point where to divide the box, and whether each half
+// must be diffed minimally, like git's xdl_split
+func (d *xdDiff) split(off1 int, lim1 int, off2 int, lim2 int, needMin bool) (int, int, bool, bool) {
+	ha1, ha2 := d.a.ha, d.b.ha
+	kvdf := func(k int) *int { return &d.kvdf[k+d.offset] }
+	kvdb := func(k int) *int { return &d.kvdb[k+d.offset] }
+
+	dmin, dmax := off1-lim2, lim1-off2
+	fmid, bmid := off1-off2, lim1-lim2
+	odd := (fmid-bmid)&1 != 0
+	fmin, fmax := fmid, fmid
+	bmin, bmax := bmid, bmid
+
+	*kvdf(fmid) = off1
+	*kvdb(bmid) = lim1
+
+	for ec := 1; ; ec++ {
+		gotSnake := false
+
+		if fmin > dmin {
+			fmin--
+			*kvdf(fmin - 1) = -1
+		} else {
+			fmin++
+		}
+		if fmax < dmax {
+			fmax++
+			*kvdf(fmax + 1) = -1
+		} else {
+			fmax--
+		}
+		for k := fmax; k >= fmin; k -= 2 {
+			var i1 int
+			if *kvdf(k - 1) >= *kvdf(k + 1) {
+				i1 = *kvdf(k - 1) + 1
+			} else {
+				i1 = *kvdf(k + 1)
+			}
+			prev1 := i1
+			i2 := i1 - k
+			for i1 < lim1 && i2 < lim2 && ha1[i1] == ha2[i2] {
+				i1++
+				i2++
+			}
+			if i1-prev1 > xdlSnakeCount {
+				gotSnake = true
+			}
+			*kvdf(k) = i1
+			if odd && bmin <= k && k <= bmax && *kvdb(k) <= i1 {
+				return i1, i2, true, true
+			}
+		}
+
+		if bmin > dmin {
+			bmin--
+			*kvdb(bmin - 1) = xdlLineMax
+		} else {
+			bmin++
+		}
+		if bmax < dmax {
+			bmax++
+			*kvdb(bmax + 1) = xdlLineMax
+		} else {
+			bmax--
+		}
+		for k := bmax; k >= bmin; k -= 2 {
+			var i1 int
+			if *kvdb(k - 1) < *kvdb(k + 1) {
+				i1 = *kvdb(k - 1)
+			} else {
+				i1 = *kvdb(k + 1) - 1
+			}
+			prev1 := i1
+			i2 := i1 - k
+			for i1 > off1 && i2 > off2 && ha1[i1-1] == ha2[i2-1] {
+				i1--
+				i2--
+			}
+			if prev1-i1 > xdlSnakeCount {
+				gotSnake = true
+			}
+			*kvdb(k) = i1
+			if !odd && fmin <= k && k <= fmax && i1 <= *kvdf(k) {
+				return i1, i2, true, true
+			}
+		}
+
+		if needMin {
+			continue
+		}
+
+		// Past some cost, settle for a diagonal that got far and ends in
+		// a good snake
+		if gotSnake && ec > xdlHeurMinCost {
+			best, s1, s2 := 0, 0, 0
+			for k := fmax; k >= fmin; k -= 2 {
+				dd := abs(k - fmid)
+				i1 := *kvdf(k)
+				i2 := i1 - k
+				v := (i1 - off1) + (i2 - off2) - dd
+				if v > xdlKHeur*ec && v > best &&
+					off1+xdlSnakeCount <= i1 && i1 < lim1 &&
+					off2+xdlSnakeCount <= i2 && i2 < lim2 {
+					for n := 1; ha1[i1-n] == ha2[i2-n]; n++ {
+						if n == xdlSnakeCount {
+							best, s1, s2 = v, i1, i2
+							break
+						}
+					}
+				}
+			}
+			if best > 0 {
+				return s1, s2, true, false
+			}
+
+			for k := bmax; k >= bmin; k -= 2 {
+				dd := abs(k - bmid)
+				i1 := *kvdb(k)
+				i2 := i1 - k
+				v := (lim1 - i1) + (lim2 - i2) - dd
+				if v > xdlKHeur*ec && v > best &&
+					off1 < i1 && i1 <= lim1-xdlSnakeCount &&
+					off2 < i2 && i2 <= lim2-xdlSnakeCount {
+					for n := 0; ha1[i1+n] == ha2[i2+n]; n++ {
+						if n == xdlSnakeCount-1 {
+							best, s1, s2 = v, i1, i2
+							break
+						}
+					}
+				}
+			}
+			if best > 0 {
+				return s1, s2, false, true
+			}
+		}
+
+		// Enough is enough: take the path that got furthest
+		if ec >= d.mxcost {
+			fbest, fbest1 := -1, -1
+			for k := fmax; k >= fmin; k -= 2 {
+				i1 := min(*kvdf(k), lim1)
+				i2 := i1 - k
+				if lim2 < i2 {
+					i1, i2 = lim2+k, lim2
+				}
+				if fbest < i1+i2 {
+					fbest, fbest1 = i1+i2, i1
+				}
+			}
+			bbest, bbest1 := xdlLineMax, xdlLineMax
+			for k := bmax; k >= bmin; k -= 2 {
+				i1 := max(off1, *kvdb(k))
+				i2 := i1 - k
+				if i2 < off2 {
+					i1, i2 = off2+k, off2
+				}
+				if i1+i2 < bbest {
+					bbest, bbest1 = i1+i2, i1
+				}
+			}
+			if (lim1+lim2)-bbest < fbest-(off1+off2) {
+				return fbest1, fbest - fbest1, true, false
+			}
+			return bbest1, bbest - bbest1, false, true
+		}
+	}
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import "testing"
+
+func TestLineDiff(t *testing.T) {
+	tests := []struct {
+		name             string
+		old, new         string
+		ignoreWhitespace bool
+		additions        int
+		deletions        int
+	}{
+		{"unchanged", "a\nb\n", "a\nb\n", false, 0, 0},
+		{"added file", "", "a\nb\n", false, 2, 0},
+		{"removed file", "a\nb\n", "", false, 0, 2},
+		{"changed line", "a\nb\nc\n", "a\nx\nc\n", false, 1, 1},
+		{"missing newline", "a\n", "a", false, 1, 1},
+		// git discards lines that match too often and reports more than
+		// the shortest edit, as does the port
+		{"not minimal", "b\nb\nd\nd\na\na\na\nc\nb\na\na\n", "c\nc\nc\nc\n", false, 4, 11},
+		{"whitespace", "a b\nc\n", "a  b\nc\n", false, 1, 1},
+		{"whitespace ignored", "a b\nc\n", "a  b\r\nc\n", true, 0, 0},
+		{"whitespace ignored, text changed", "a b\nc\n", "a  c\nc\n", true, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			additions, deletions := lineDiff(tt.old, tt.new, tt.ignoreWhitespace)
+			if additions != tt.additions || deletions != tt.deletions {
+				t.Errorf("lineDiff = %d/%d, want %d/%d", additions, deletions, tt.additions, tt.deletions)
+			}
+		})
+	}
+}
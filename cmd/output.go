@@ -0,0 +1,329 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// contributorRecord is the serialized form of a contributor in
+// machine-readable output formats
+type contributorRecord struct {
+	Name      string `json:"name" yaml:"name"`
+	Email     string `json:"email" yaml:"email"`
+	Commits   int    `json:"commits" yaml:"commits"`
+	Files     int    `json:"files" yaml:"files"`
+	Binary    int    `json:"binaryFiles" yaml:"binaryFiles"`
+	Additions int    `json:"additions" yaml:"additions"`
+	Deletions int    `json:"deletions" yaml:"deletions"`
+	Total     int    `json:"total" yaml:"total"`
+
+	AvgCommitSize    float64 `json:"avgCommitSize" yaml:"avgCommitSize"`
+	MedianCommitSize float64 `json:"medianCommitSize" yaml:"medianCommitSize"`
+
+	FirstCommit time.Time `json:"firstCommit" yaml:"firstCommit"`
+	LastCommit  time.Time `json:"lastCommit" yaml:"lastCommit"`
+	ActiveDays  int       `json:"activeDays" yaml:"activeDays"`
+	TenureDays  int       `json:"tenureDays" yaml:"tenureDays"`
+
+	CommitShare float64 `json:"commitShare" yaml:"commitShare"`
+	ChangeShare float64 `json:"changeShare" yaml:"changeShare"`
+
+	GitHub    string `json:"github,omitempty" yaml:"github,omitempty"`       // GitHub login, set with --enrich github
+	AvatarURL string `json:"avatarUrl,omitempty" yaml:"avatarUrl,omitempty"` // GitHub avatar, set with --enrich github
+
+	SignedShare *float64 `json:"signedShare,omitempty" yaml:"signedShare,omitempty"` // percentage of signed commits, set with the signed column
+
+	Activity []int `json:"activity,omitempty" yaml:"activity,omitempty"` // commits per period, oldest first
+}
+
+// summaryRecord is the serialized form of the report totals
+type summaryRecord struct {
+	Contributors int `json:"contributors" yaml:"contributors"`
+	Commits      int `json:"commits" yaml:"commits"`
+	Files        int `json:"files" yaml:"files"`
+	BinaryFiles  int `json:"binaryFiles" yaml:"binaryFiles"`
+	ActiveDays   int `json:"activeDays" yaml:"activeDays"`
+	Additions    int `json:"additions" yaml:"additions"`
+	Deletions    int `json:"deletions" yaml:"deletions"`
+	Total        int `json:"total" yaml:"total"`
+
+	Gini float64 `json:"gini" yaml:"gini"`
+	HHI  float64 `json:"hhi" yaml:"hhi"`
+}
+
+// reportDocument is the top-level document of machine-readable output
+type reportDocument struct {
+	Path         string              `json:"path" yaml:"path"`
+	TimeRange    string              `json:"timeRange,omitempty" yaml:"timeRange,omitempty"`
+	Contributors []contributorRecord `json:"contributors" yaml:"contributors"`
+	Summary      summaryRecord       `json:"summary" yaml:"summary"`
+}
+
+// buildReportDocument converts contributor statistics to their serialized
+// form. The signed share and activity are filled in when their columns are
+// rendered.
+func buildReportDocument(report *Report, options renderOptions) reportDocument {
+	doc := reportDocument{
+		Path:         report.Path,
+		TimeRange:    report.TimeRange,
+		Contributors: make([]contributorRecord, 0, len(report.Contributors)),
+		Summary: summaryRecord{
+			Contributors: report.Summary.Contributors,
+			Commits:      report.Summary.Commits,
+			Files:        report.Summary.Files,
+			BinaryFiles:  report.Summary.BinaryFiles,
+			ActiveDays:   report.Summary.ActiveDays,
+			Additions:    report.Summary.Additions,
+			Deletions:    report.Summary.Deletions,
+			Total:        report.Summary.Additions + report.Summary.Deletions,
+			Gini:         report.Summary.Gini,
+			HHI:          report.Summary.HHI,
+		},
+	}
+
+	activity := options.hasColumn("activity")
+	signed := options.hasColumn("signed")
+	for _, contributor := range report.Contributors {
+		record := contributorRecord{
+			Name:      contributor.Name,
+			Email:     contributor.Email,
+			Commits:   contributor.Commits,
+			Files:     len(contributor.Files),
+			Binary:    len(contributor.BinaryFiles),
+			Additions: contributor.Additions,
+			Deletions: contributor.Deletions,
+			Total:     contributor.Additions + contributor.Deletions,
+
+			AvgCommitSize:    contributor.AverageCommitSize(),
+			MedianCommitSize: contributor.MedianCommitSize(),
+
+			FirstCommit: contributor.FirstCommit,
+			LastCommit:  contributor.LastCommit,
+			ActiveDays:  len(contributor.Days),
+			TenureDays:  tenureDays(contributor.FirstCommit, contributor.LastCommit),
+
+			CommitShare: contributor.CommitShare,
+			ChangeShare: contributor.ChangeShare,
+
+			GitHub:    contributor.GitHubLogin,
+			AvatarURL: contributor.AvatarURL,
+		}
+		if signed {
+			record.SignedShare = signedShare(contributor.SignedCommits, contributor.Commits)
+		}
+		if activity {
+			record.Activity = activityCounts(contributor.CommitDates, options.ActivityBucket, options.ActivityPeriods, time.Now())
+		}
+		doc.Contributors = append(doc.Contributors, record)
+	}
+
+	return doc
+}
+
+// tenureDays returns the number of whole days between two commit dates
+func tenureDays(first, last time.Time) int {
+	if first.IsZero() || last.IsZero() {
+		return 0
+	}
+	return int(last.Sub(first).Hours() / 24)
+}
+
+// totalsRecord returns the report totals as a row for the tabular formats
+func totalsRecord(report *Report, options renderOptions) contributorRecord {
+	record := contributorRecord{
+		Name:             "TOTAL",
+		Email:            pluralize(report.Summary.Contributors, "contributor"),
+		Commits:          report.Summary.Commits,
+		Files:            report.Summary.Files,
+		Binary:           report.Summary.BinaryFiles,
+		Additions:        report.Summary.Additions,
+		Deletions:        report.Summary.Deletions,
+		Total:            report.Summary.Additions + report.Summary.Deletions,
+		AvgCommitSize:    report.Summary.AvgCommitSize,
+		MedianCommitSize: report.Summary.MedianCommitSize,
+
+		FirstCommit: report.Summary.FirstCommit,
+		LastCommit:  report.Summary.LastCommit,
+		ActiveDays:  report.Summary.ActiveDays,
+		TenureDays:  tenureDays(report.Summary.FirstCommit, report.Summary.LastCommit),
+
+		CommitShare: report.Summary.CommitShare,
+		ChangeShare: report.Summary.ChangeShare,
+	}
+	if options.hasColumn("signed") {
+		record.SignedShare = signedShare(report.Summary.SignedCommits, report.Summary.Commits)
+	}
+	return record
+}
+
+// tableRecords returns the rows of the tabular formats, including the
+// totals row when it is asked for
+func tableRecords(report *Report, options renderOptions) []contributorRecord {
+	records := buildReportDocument(report, options).Contributors
+	if options.Totals {
+		records = append(records, totalsRecord(report, options))
+	}
+	return records
+}
+
+// displayJSON writes the contributor statistics as a JSON document
+func displayJSON(w io.Writer, report *Report, options renderOptions) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(buildReportDocument(report, options))
+}
+
+// displayJSONReports writes the statistics of several reports as a JSON
+// array of documents
+func displayJSONReports(w io.Writer, reports []*Report, options renderOptions) error {
+	docs := make([]reportDocument, len(reports))
+	for i, report := range reports {
+		docs[i] = buildReportDocument(report, options)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(docs)
+}
+
+// displayYAML writes the contributor statistics as a YAML document with the
+// same structure as the JSON output
+func displayYAML(w io.Writer, report *Report, options renderOptions) error {
+	return displayYAMLReports(w, []*Report{report}, options)
+}
+
+// displayYAMLReports writes the statistics of several reports as a stream
+// of YAML documents separated by ---
+func displayYAMLReports(w io.Writer, reports []*Report, options renderOptions) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	for _, report := range reports {
+		if err := encoder.Encode(buildReportDocument(report, options)); err != nil {
+			return err
+		}
+	}
+	return encoder.Close()
+}
+
+// displayCSV writes the contributor statistics as CSV with a header row
+func displayCSV(w io.Writer, report *Report, options renderOptions) error {
+	return writeCSV(w, []*Report{report}, options, false)
+}
+
+// displayCSVReports writes the statistics of several reports as one CSV
+// table, with the path of each row's report in a leading path column
+func displayCSVReports(w io.Writer, reports []*Report, options renderOptions) error {
+	return writeCSV(w, reports, options, true)
+}
+
+// writeCSV writes the rows of the reports as CSV under one header row,
+// leading with the report's path when pathColumn is set
+func writeCSV(w io.Writer, reports []*Report, options renderOptions, pathColumn bool) error {
+	cw := csv.NewWriter(w)
+	if !options.NoHeader {
+		cw.Write(headerRow(options.Columns, pathColumn))
+	}
+	for _, report := range reports {
+		for _, record := range tableRecords(report, options) {
+			cw.Write(recordRow(report, record, options.Columns, pathColumn, nil))
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// tsvEscaper replaces characters that would break a tab-separated row
+var tsvEscaper = strings.NewReplacer("\t", " ", "\n", " ")
+
+// displayTSV writes the contributor statistics as tab-separated values
+func displayTSV(w io.Writer, report *Report, options renderOptions) error {
+	return writeTSV(w, []*Report{report}, options, false)
+}
+
+// displayTSVReports writes the statistics of several reports as one
+// tab-separated table, with the path of each row's report in a leading
+// path column
+func displayTSVReports(w io.Writer, reports []*Report, options renderOptions) error {
+	return writeTSV(w, reports, options, true)
+}
+
+// writeTSV writes the rows of the reports as tab-separated values under
+// one header row, leading with the report's path when pathColumn is set
+func writeTSV(w io.Writer, reports []*Report, options renderOptions, pathColumn bool) error {
+	ew := &errWriter{w: w}
+	if !options.NoHeader {
+		fmt.Fprintln(ew, strings.Join(headerRow(options.Columns, pathColumn), "\t"))
+	}
+	for _, report := range reports {
+		for _, record := range tableRecords(report, options) {
+			fmt.Fprintln(ew, strings.Join(recordRow(report, record, options.Columns, pathColumn, tsvEscaper), "\t"))
+		}
+	}
+	return ew.err
+}
+
+// headerRow returns the column names of the CSV and TSV header
+func headerRow(columns []column, pathColumn bool) []string {
+	var header []string
+	if pathColumn {
+		header = append(header, "path")
+	}
+	for _, col := range columns {
+		header = append(header, col.Name)
+	}
+	return header
+}
+
+// recordRow returns the cells of a CSV or TSV row, escaped with escaper
+// when it is not nil
+func recordRow(report *Report, record contributorRecord, columns []column, pathColumn bool, escaper *strings.Replacer) []string {
+	var row []string
+	if pathColumn {
+		row = append(row, report.Path)
+	}
+	for _, col := range columns {
+		row = append(row, col.text(record, ""))
+	}
+	if escaper != nil {
+		for i := range row {
+			row[i] = escaper.Replace(row[i])
+		}
+	}
+	return row
+}
+
+// markdownEscaper escapes characters that would break a markdown table cell
+var markdownEscaper = strings.NewReplacer("|", "\\|", "\n", " ")
+
+// displayMarkdown writes the contributor statistics as a GitHub-flavored
+// markdown table with right-aligned numeric columns
+func displayMarkdown(w io.Writer, report *Report, options renderOptions) error {
+	columns := options.Columns
+	ew := &errWriter{w: w}
+
+	titles := make([]string, len(columns))
+	aligns := make([]string, len(columns))
+	for i, col := range columns {
+		titles[i] = col.Title
+		aligns[i] = strings.Repeat("-", len(col.Title)+2)
+		if col.Numeric {
+			aligns[i] = aligns[i][1:] + ":"
+		}
+	}
+	fmt.Fprintf(ew, "| %s |\n", strings.Join(titles, " | "))
+	fmt.Fprintf(ew, "|%s|\n", strings.Join(aligns, "|"))
+
+	for _, record := range tableRecords(report, options) {
+		cells := make([]string, len(columns))
+		for i, col := range columns {
+			cells[i] = markdownEscaper.Replace(col.text(record, options.Humanize))
+		}
+		fmt.Fprintf(ew, "| %s |\n", strings.Join(cells, " | "))
+	}
+	return ew.err
+}
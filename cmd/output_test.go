@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/PerArneng/gitwho/pkg/gitwho"
+)
+
+// testReport is a report of two contributors
+func testReport() *Report {
+	contributors := []*Contributor{
+		{Name: "Jane Doe", Email: "jane@example.com", Commits: 3, Additions: 1500, Deletions: 20, Files: map[string]int{"a.go": 3}},
+		{Name: "John | Smith", Email: "john@example.com", Commits: 1, Additions: 10, Deletions: 5, Files: map[string]int{"b.go": 1}},
+	}
+	gitwho.ComputeShares(contributors)
+	return &Report{Path: "src/", Contributors: contributors, Summary: gitwho.Summarize(contributors)}
+}
+
+// testOptions renders the name, commits and total columns
+func testOptions() renderOptions {
+	return renderOptions{Columns: []column{nameColumn, commitsColumn, totalColumn}}
+}
+
+func TestDisplayCSV(t *testing.T) {
+	options := testOptions()
+	options.Totals = true
+
+	var out bytes.Buffer
+	if err := displayCSV(&out, testReport(), options); err != nil {
+		t.Fatal(err)
+	}
+	want := "name,commits,total\n" +
+		"Jane Doe,3,1520\n" +
+		"John | Smith,1,15\n" +
+		"TOTAL,4,1535\n"
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestDisplayTSVNoHeader(t *testing.T) {
+	options := testOptions()
+	options.NoHeader = true
+
+	var out bytes.Buffer
+	if err := displayTSV(&out, testReport(), options); err != nil {
+		t.Fatal(err)
+	}
+	want := "Jane Doe\t3\t1520\nJohn | Smith\t1\t15\n"
+	if out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
+
+// testReports are the reports of two paths, as with --per-path
+func testReports() []*Report {
+	other := testReport()
+	other.Path = "docs/"
+	return []*Report{testReport(), other}
+}
+
+func TestPerPathJSONIsOneArray(t *testing.T) {
+	var out bytes.Buffer
+	if err := renderReports(formatters["json"](testOptions()), &out, testReports()); err != nil {
+		t.Fatal(err)
+	}
+	var docs []reportDocument
+	if err := json.Unmarshal(out.Bytes(), &docs); err != nil {
+		t.Fatalf("output is not one JSON array: %v\n%s", err, out.String())
+	}
+	if len(docs) != 2 || docs[0].Path != "src/" || docs[1].Path != "docs/" {
+		t.Errorf("got documents %+v", docs)
+	}
+}
+
+func TestPerPathYAMLIsDocumentStream(t *testing.T) {
+	var out bytes.Buffer
+	if err := renderReports(formatters["yaml"](testOptions()), &out, testReports()); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(out.String(), "\n---\n"); got != 1 {
+		t.Errorf("got %d document separators, want 1:\n%s", got, out.String())
+	}
+}
+
+func TestPerPathCSVHasPathColumn(t *testing.T) {
+	var out bytes.Buffer
+	if err := renderReports(formatters["csv"](testOptions()), &out, testReports()); err != nil {
+		t.Fatal(err)
+	}
+	want := "path,name,commits,total\n" +
+		"src/,Jane Doe,3,1520\n" +
+		"src/,John | Smith,1,15\n" +
+		"docs/,Jane Doe,3,1520\n" +
+		"docs/,John | Smith,1,15\n"
+	if out.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestDisplayMarkdownHumanized(t *testing.T) {
+	options := testOptions()
+	options.Humanize = "separators"
+
+	var out bytes.Buffer
+	if err := displayMarkdown(&out, testReport(), options); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"| Name | Commits | Total |", "| Jane Doe | 3 | 1,520 |", `| John \| Smith | 1 | 15 |`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("%q does not contain %q", out.String(), want)
+		}
+	}
+}
+
+func TestDisplayResultsColor(t *testing.T) {
+	options := testOptions()
+	options.Qualifiers = []string{"since 2024-01-01"}
+
+	var plain bytes.Buffer
+	if err := displayResults(&plain, testReport(), options); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(plain.String(), "\x1b[") {
+		t.Errorf("uncolored table has escape sequences:\n%s", plain.String())
+	}
+	if !strings.Contains(plain.String(), "Contributor Statistics for src/ (since 2024-01-01)") {
+		t.Errorf("table has no title with the qualifier:\n%s", plain.String())
+	}
+
+	options.Color = true
+	var colored bytes.Buffer
+	if err := displayResults(&colored, testReport(), options); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(colored.String(), ansiBold+ansiYellow+"Jane Doe") {
+		t.Errorf("colored table does not highlight the top contributor:\n%s", colored.String())
+	}
+}
+
+func TestQualifiersInReportHeadings(t *testing.T) {
+	// The HTML report and the summary text name the same period as the
+	// table's title
+	options := testOptions()
+	options.Qualifiers = []string{"since 2024-01-01", "until 2024-06-30"}
+	report := testReport()
+	report.TimeRange = "year"
+
+	var html bytes.Buffer
+	if err := displayHTML(&html, report, options); err != nil {
+		t.Fatal(err)
+	}
+	if want := "<h1>Contributor Statistics for src/ (last year) (since 2024-01-01) (until 2024-06-30)</h1>"; !strings.Contains(html.String(), want) {
+		t.Errorf("report does not contain %q:\n%s", want, html.String())
+	}
+
+	var text bytes.Buffer
+	if err := displaySummaryText(&text, report, options); err != nil {
+		t.Fatal(err)
+	}
+	if want := "Over the last year since 2024-01-01 until 2024-06-30, "; !strings.HasPrefix(text.String(), want) {
+		t.Errorf("got %q, want it to start with %q", text.String(), want)
+	}
+}
+
+// failingWriter fails every write, like a closed pipe
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func TestFormattersReturnWriteErrors(t *testing.T) {
+	for name, newFormatter := range formatters {
+		err := newFormatter(testOptions()).Render(failingWriter{}, testReport())
+		if err == nil {
+			t.Errorf("%s formatter ignored a write error", name)
+		}
+	}
+}
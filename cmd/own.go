@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var ownFormat string
+
+// ownCmd represents the own command
+var ownCmd = &cobra.Command{
+	Use:   "own [file/directory]",
+	Short: "Show who owns the lines that exist today",
+	Long: `Own runs git blame on every text file in a file or directory at HEAD and
+reports who wrote the lines that exist today. Unlike the churn statistics,
+it does not credit code that was later deleted or rewritten.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+		if !isValidTableFormat(ownFormat) {
+			fmt.Printf("Error: Invalid format %s. Supported formats: table, csv, json\n", ownFormat)
+			os.Exit(1)
+		}
+
+		root, files, err := collectBlameFiles(path, repoPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		owners, err := blameOwners(root, files)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		if err := displayTable(os.Stdout, ownFormat, ownerTable(owners), owners); err != nil {
+			fmt.Printf("Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	ownCmd.Flags().StringVar(&ownFormat, "format", "table", "Output format (table, csv, json)")
+	rootCmd.AddCommand(ownCmd)
+}
+
+// lineOwner counts the lines at HEAD last changed by a contributor
+type lineOwner struct {
+	Name  string  `json:"name"`
+	Email string  `json:"email"`
+	Lines int     `json:"lines"`
+	Files int     `json:"files"`
+	Share float64 `json:"share"`
+}
+
+// collectBlameFiles returns the repository root and the text files at HEAD
+// under path, relative to the root. Files excluded by .gitwhoignore or
+// marked as vendored are left out.
+func collectBlameFiles(path string, repoPath string) (string, []string, error) {
+	repo, err := resolveRepo([]string{path}, repoPath)
+	if err != nil {
+		return "", nil, err
+	}
+	if bare, err := isBareRepo(repo); err != nil {
+		return "", nil, fmt.Errorf("Error: %v", err)
+	} else if bare {
+		return "", nil, fmt.Errorf("Error: blaming files needs a work tree, %s is a bare repository", repo)
+	}
+	root, err := findGitRoot(repo)
+	if err != nil {
+		return "", nil, fmt.Errorf("Error finding git root: %v", err)
+	}
+	relPath, err := getRelativePath(path, repo)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Blame runs against HEAD, so the files are listed from HEAD rather
+	// than the index. Diffing HEAD against the empty tree lists them with
+	// "-" line counts for the files git considers binary.
+	emptyTree, err := runGit(nil, "-C", root, "hash-object", "-t", "tree", "--stdin")
+	if err != nil {
+		return "", nil, fmt.Errorf("Error listing files: %v", err)
+	}
+	output, err := runGit(nil, "-C", root, "diff-tree", "-r", "-z", "--numstat", strings.TrimSpace(emptyTree), "HEAD", "--", relPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("Error listing files: %v", err)
+	}
+	ignore, err := loadIgnoreFile(root)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var files []string
+	for _, entry := range strings.Split(output, "\x00") {
+		counts, file, ok := strings.Cut(entry, "\t")
+		if !ok {
+			continue
+		}
+		if _, file, ok = strings.Cut(file, "\t"); !ok || counts == "-" || ignore.Match(file) {
+			continue
+		}
+		files = append(files, file)
+	}
+
+	if !includeVendored {
+		vendored, err := vendoredPaths(root, files)
+		if err != nil {
+			return "", nil, err
+		}
+		files = slices.DeleteFunc(files, func(file string) bool { return vendored[file] })
+	}
+	return root, files, nil
+}
+
+// blameFile counts the lines of file at HEAD per commit that last changed
+// them. It returns the line counts and the author of each commit, as name
+// and email separated by "|".
+func blameFile(root string, file string) (map[string]int, map[string]string, error) {
+	output, err := runGit(nil, "-C", root, "blame", "--porcelain", "HEAD", "--", file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error running git blame on %s: %v", file, err)
+	}
+
+	// Each line is preceded by a header naming its commit. The author of a
+	// commit is only included the first time the commit appears.
+	lines := make(map[string]int)
+	authors := make(map[string]string)
+	names := make(map[string]string)
+	hash := ""
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "\t"):
+			lines[hash]++
+		case strings.HasPrefix(line, "author "):
+			names[hash] = strings.TrimPrefix(line, "author ")
+		case strings.HasPrefix(line, "author-mail "):
+			email := strings.Trim(strings.TrimPrefix(line, "author-mail "), "<>")
+			authors[hash] = names[hash] + "|" + email
+		default:
+			if fields := strings.Fields(line); len(fields) >= 3 && len(fields[0]) >= 40 {
+				hash = fields[0]
+			}
+		}
+	}
+	return lines, authors, nil
+}
+
+// blameOwners blames the files and returns their line owners, the owner of
+// the most lines first
+func blameOwners(root string, files []string) ([]*lineOwner, error) {
+	config, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	owners := make(map[string]*lineOwner)
+	total := 0
+	for _, file := range files {
+		lines, authors, err := blameFile(root, file)
+		if err != nil {
+			return nil, err
+		}
+
+		seen := make(map[string]bool)
+		for hash, count := range lines {
+			name, email, _ := strings.Cut(authors[hash], "|")
+			name, email = canonicalIdentity(name, email, config.Aliases)
+			key := name + "|" + email
+			owner, exists := owners[key]
+			if !exists {
+				owner = &lineOwner{Name: name, Email: email}
+				owners[key] = owner
+			}
+			if !seen[key] {
+				seen[key] = true
+				owner.Files++
+			}
+			owner.Lines += count
+			total += count
+		}
+	}
+
+	result := make([]*lineOwner, 0, len(owners))
+	for _, owner := range owners {
+		if total > 0 {
+			owner.Share = float64(owner.Lines) * 100 / float64(total)
+		}
+		result = append(result, owner)
+	}
+	slices.SortFunc(result, func(a, b *lineOwner) int {
+		return cmp.Or(cmp.Compare(b.Lines, a.Lines), cmp.Compare(a.Name, b.Name), cmp.Compare(a.Email, b.Email))
+	})
+	return result, nil
+}
+
+// ownerTable lays out one row per line owner
+func ownerTable(owners []*lineOwner) textTable {
+	table := textTable{
+		Headers: []string{"NAME", "EMAIL", "LINES", "FILES", "SHARE"},
+		Numeric: []bool{false, false, true, true, true},
+	}
+	for _, owner := range owners {
+		table.Rows = append(table.Rows, []string{
+			owner.Name,
+			owner.Email,
+			strconv.Itoa(owner.Lines),
+			strconv.Itoa(owner.Files),
+			formatPercent(owner.Share),
+		})
+	}
+	return table
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+var noPager bool
+
+// pagerBuffer collects output destined for a terminal so it can be sent
+// through a pager. It reports the terminal's file descriptor, so color and
+// width detection behave as if writing to the terminal directly.
+type pagerBuffer struct {
+	bytes.Buffer
+	terminal *os.File
+}
+
+// Fd returns the file descriptor of the terminal the output is meant for
+func (p *pagerBuffer) Fd() uintptr {
+	return p.terminal.Fd()
+}
+
+// Flush writes the buffered output to the terminal. When it has more lines
+// than fit on the screen it is piped through $PAGER (less by default).
+func (p *pagerBuffer) Flush() error {
+	_, height, err := term.GetSize(int(p.terminal.Fd()))
+	if err != nil || bytes.Count(p.Bytes(), []byte("\n")) < height {
+		_, err := p.terminal.Write(p.Bytes())
+		return err
+	}
+
+	pager := os.Getenv("PAGER")
+	if pager == "" {
+		pager = "less"
+	}
+	if pager == "cat" {
+		_, err := p.terminal.Write(p.Bytes())
+		return err
+	}
+
+	return page(pager, p.Bytes(), p.terminal, os.Stderr)
+}
+
+// page pipes output through the pager command to terminal. Like git, it
+// writes the output to terminal directly when the pager cannot be started.
+func page(pager string, output []byte, terminal io.Writer, stderr io.Writer) error {
+	// Like git, let less pass colors through and quit on short output
+	cmd := exec.Command("sh", "-c", pager)
+	cmd.Stdin = bytes.NewReader(output)
+	cmd.Stdout = terminal
+	cmd.Stderr = stderr
+	if os.Getenv("LESS") == "" {
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+
+	debugf("+ %s\n", strings.TrimSpace(pager))
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if err == nil || errors.As(err, &exitErr) && !pagerNotStarted(exitErr) {
+		return err
+	}
+	debugf("Could not start the pager %s: %v\n", strings.TrimSpace(pager), err)
+	_, err = terminal.Write(output)
+	return err
+}
+
+// pagerNotStarted reports whether the shell exited because it could not
+// run the pager: 127 when it was not found, 126 when it is no executable
+func pagerNotStarted(err *exec.ExitError) bool {
+	return err.ExitCode() == 126 || err.ExitCode() == 127
+}
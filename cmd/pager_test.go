@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestPage(t *testing.T) {
+	tests := []struct {
+		pager string
+		want  string
+	}{
+		{"tr a-z A-Z", "HELLO\n"},
+		// A pager that cannot be started leaves the output unpaged
+		{"no-such-pager-gitwho", "hello\n"},
+		{"/dev/null", "hello\n"},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if err := page(tt.pager, []byte("hello\n"), &out, io.Discard); err != nil {
+			t.Errorf("page(%s): %v", tt.pager, err)
+		} else if out.String() != tt.want {
+			t.Errorf("page(%s) wrote %q, want %q", tt.pager, out.String(), tt.want)
+		}
+	}
+
+	// A pager that ran and failed reports it
+	var out bytes.Buffer
+	if err := page("cat >/dev/null; exit 3", []byte("hello\n"), &out, io.Discard); err == nil {
+		t.Errorf("page with a failing pager succeeded, want its error")
+	}
+}
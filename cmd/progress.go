@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/PerArneng/gitwho/pkg/gitwho"
+)
+
+const (
+	progressDelay      = time.Second            // reading that is over sooner shows no progress
+	progressInterval   = 100 * time.Millisecond // how often the line is redrawn at most
+	progressMaxCommits = 100000                 // commits counted at most for the total
+)
+
+// progress draws how much of the history git log has written so far on
+// one line of stderr. Once reading takes longer than progressDelay, the
+// commits the same git log selects are counted in the background, and
+// the line shows the share read and the estimated time left. Histories
+// with more than progressMaxCommits commits are not counted to the end,
+// and only show the commits read. A nil progress draws nothing.
+type progress struct {
+	w       io.Writer
+	args    []string // arguments of the git log being read
+	start   time.Time
+	drawn   time.Time // when the line was last drawn
+	done    int       // commits read
+	total   int       // commits the history has, or 0 while unknown
+	counted chan int  // delivers the total once the commits are counted
+	cancel  context.CancelFunc
+}
+
+// newProgress returns the progress of reading the output of git log run
+// with args, or nil when it is not shown: not with --quiet, nor with
+// --verbose, whose log it would break up. Only the table is read on a
+// terminal, and only a terminal shows it.
+func newProgress(args []string) *progress {
+	if quiet || verbose || outputFormat != "table" || !isTerminal(os.Stderr) {
+		return nil
+	}
+	start := time.Now()
+	return &progress{w: os.Stderr, args: args, start: start, drawn: start}
+}
+
+// advance counts a commit as read and redraws the line when it is due
+func (p *progress) advance() {
+	if p == nil {
+		return
+	}
+	p.done++
+	now := time.Now()
+	if now.Sub(p.start) < progressDelay || now.Sub(p.drawn) < progressInterval {
+		return
+	}
+	if p.counted == nil {
+		p.startCounting()
+	}
+	select {
+	case total := <-p.counted:
+		p.total = total
+	default:
+	}
+	p.drawn = now
+	fmt.Fprintf(p.w, "\r\033[K%s", progressLine(p.done, p.total, now.Sub(p.start)))
+}
+
+// startCounting counts the commits in the background
+func (p *progress) startCounting() {
+	ctx, cancel := context.WithCancel(runCtx)
+	p.cancel = cancel
+	p.counted = make(chan int, 1)
+	go func() {
+		if total, err := countCommits(ctx, p.args); err == nil && total < progressMaxCommits {
+			p.counted <- total
+		}
+	}()
+}
+
+// finish stops counting and clears the line once the history was read
+func (p *progress) finish() {
+	if p == nil {
+		return
+	}
+	if p.cancel != nil {
+		p.cancel()
+	}
+	if !p.drawn.Equal(p.start) {
+		fmt.Fprint(p.w, "\r\033[K")
+	}
+}
+
+// countCommits counts the commits git log run with args selects, up to
+// progressMaxCommits. It runs the same git log with the same filters,
+// without the diffs and with nothing but a marker per commit.
+func countCommits(ctx context.Context, args []string) (int, error) {
+	output, err := runGitContext(ctx, nil, "", nil, countArgs(args)...)
+	if err != nil {
+		return 0, err
+	}
+	return strings.Count(output, gitwho.CommitMarker), nil
+}
+
+// countArgs turns the arguments of git log into ones that print only a
+// marker per commit, for at most progressMaxCommits commits. The limit
+// comes first, so a --max-count among the arguments takes precedence.
+func countArgs(args []string) []string {
+	var count []string
+	command := false
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case !command && (arg == "-C" || arg == "-c"):
+			// Options of git itself, before the command, take a value
+			count = append(count, arg, args[i+1])
+			i++
+		case !command:
+			command = true
+			count = append(count, arg, fmt.Sprintf("--max-count=%d", progressMaxCommits))
+		case arg == "--":
+			return append(count, args[i:]...)
+		case arg == "--numstat":
+		case strings.HasPrefix(arg, "--format="):
+			count = append(count, "--format=tformat:"+gitwho.CommitMarker)
+		default:
+			count = append(count, arg)
+		}
+	}
+	return count
+}
+
+// progressLine describes reading done commits of total in elapsed time,
+// e.g. "Reading history: 42% (420/1000 commits), about 3s left". A total
+// of 0 is unknown, and the line only tells the commits read.
+func progressLine(done int, total int, elapsed time.Duration) string {
+	if total == 0 {
+		return fmt.Sprintf("Reading history: %d commits", done)
+	}
+	done = min(done, total)
+	line := fmt.Sprintf("Reading history: %d%% (%d/%d commits)", done*100/total, done, total)
+	if remaining := estimateRemaining(done, total, elapsed).Round(time.Second); remaining > 0 {
+		line += fmt.Sprintf(", about %s left", remaining)
+	}
+	return line
+}
+
+// estimateRemaining estimates the time reading the rest of total commits
+// takes when done of them took elapsed, assuming the rest go as fast. It
+// is 0 when nothing was read yet or everything was.
+func estimateRemaining(done int, total int, elapsed time.Duration) time.Duration {
+	if done <= 0 || done >= total {
+		return 0
+	}
+	return time.Duration(float64(elapsed) * float64(total-done) / float64(done))
+}
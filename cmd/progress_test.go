@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/PerArneng/gitwho/pkg/gitwho"
+)
+
+func TestEstimateRemaining(t *testing.T) {
+	tests := []struct {
+		done, total int
+		elapsed     time.Duration
+		want        time.Duration
+	}{
+		{250, 1000, 10 * time.Second, 30 * time.Second},
+		{500, 1000, 10 * time.Second, 10 * time.Second},
+		{999, 1000, 999 * time.Millisecond, time.Millisecond},
+		{1, 3, time.Second, 2 * time.Second},
+		// Nothing read yet gives no estimate, everything read none left
+		{0, 1000, time.Second, 0},
+		{1000, 1000, 10 * time.Second, 0},
+		{1200, 1000, 10 * time.Second, 0},
+	}
+	for _, tt := range tests {
+		if got := estimateRemaining(tt.done, tt.total, tt.elapsed); got != tt.want {
+			t.Errorf("estimateRemaining(%d, %d, %s) = %s, want %s", tt.done, tt.total, tt.elapsed, got, tt.want)
+		}
+	}
+}
+
+func TestProgressLine(t *testing.T) {
+	tests := []struct {
+		done, total int
+		elapsed     time.Duration
+		want        string
+	}{
+		{420, 1000, 2 * time.Second, "Reading history: 42% (420/1000 commits), about 3s left"},
+		{900, 1000, time.Second, "Reading history: 90% (900/1000 commits)"},
+		{1000, 1000, 5 * time.Second, "Reading history: 100% (1000/1000 commits)"},
+		{1001, 1000, 5 * time.Second, "Reading history: 100% (1000/1000 commits)"},
+		// The total is not known yet, or the history is too long to count
+		{420, 0, 2 * time.Second, "Reading history: 420 commits"},
+	}
+	for _, tt := range tests {
+		if got := progressLine(tt.done, tt.total, tt.elapsed); got != tt.want {
+			t.Errorf("progressLine(%d, %d, %s) = %q, want %q", tt.done, tt.total, tt.elapsed, got, tt.want)
+		}
+	}
+}
+
+func TestCountArgs(t *testing.T) {
+	args := []string{"-C", "log", "-c", "mailmap.file=m", "log", "--format=" + gitwho.CommitMarker + "%an|%ae", "--numstat", "--since=2024-01-01", "-n", "5", "--", "log", "--numstat"}
+	want := []string{"-C", "log", "-c", "mailmap.file=m", "log", "--max-count=100000", "--format=tformat:" + gitwho.CommitMarker, "--since=2024-01-01", "-n", "5", "--", "log", "--numstat"}
+	if got := countArgs(args); !slices.Equal(got, want) {
+		t.Errorf("countArgs = %q, want %q", got, want)
+	}
+}
+
+func TestCountCommits(t *testing.T) {
+	// The count honors the filters of the git log, such as --git-arg ones
+	repo := newTestRepo(t)
+	repo.commit("alice", map[string]string{"main.go": "a\n"})
+	repo.commit("bob", map[string]string{"main.go": "b\n"})
+	repo.commit("alice", map[string]string{"README.md": "c\n"})
+	args, err := gitwho.LogArgs(gitwho.Query{Repo: repo.dir, GitArgs: []string{"--author=alice"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := countCommits(context.Background(), args); err != nil || got != 2 {
+		t.Errorf("countCommits = %d, %v, want 2", got, err)
+	}
+}
@@ -0,0 +1,360 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// remoteSchemes are the URL schemes git can clone from
+var remoteSchemes = []string{"https://", "http://", "ssh://", "git://", "file://"}
+
+// scpLikePattern matches scp-like git URLs such as git@github.com:org/repo.git
+var scpLikePattern = regexp.MustCompile(`^[\w.-]+@[\w.-]+:[^/]`)
+
+// builtinForges are the base URLs of the forge shorthands such as
+// gh:org/repo
+var builtinForges = map[string]string{
+	"gh": "https://github.com",
+	"gl": "https://gitlab.com",
+	"bb": "https://bitbucket.org",
+}
+
+// shorthandPattern matches a forge shorthand such as gl:group/project
+var shorthandPattern = regexp.MustCompile(`^([a-z][a-z0-9-]+):([^/:][^:]*)$`)
+
+// tokenEnv is the environment variable holding the access token for
+// private repositories
+const tokenEnv = "GITWHO_TOKEN"
+
+// tokenUsers are the user names forges expect with an access token over
+// HTTPS; other hosts get x-access-token
+var tokenUsers = map[string]string{
+	"gitlab.com":    "oauth2",
+	"bitbucket.org": "x-token-auth",
+}
+
+var cloneDepth int
+var accessToken string
+var cacheTTL string
+
+// expandShorthand resolves a forge shorthand such as gh:org/repo to its
+// clone URL. Forges beyond gh, gl and bb, and self-hosted base URLs for
+// them, come from the forges section of the config file.
+func expandShorthand(value string, forges map[string]string) (string, bool) {
+	match := shorthandPattern.FindStringSubmatch(value)
+	if match == nil {
+		return "", false
+	}
+	if _, err := os.Stat(value); err == nil {
+		return "", false
+	}
+	base, ok := forges[match[1]]
+	if !ok {
+		base, ok = builtinForges[match[1]]
+	}
+	if !ok {
+		return "", false
+	}
+	if !strings.HasSuffix(base, ":") {
+		// scp-like bases such as git@host: take the path directly
+		base = strings.TrimSuffix(base, "/") + "/"
+	}
+	return base + match[2], true
+}
+
+// isRepoShorthand reports whether a value is a forge shorthand
+func isRepoShorthand(value string) bool {
+	config, err := loadConfig()
+	if err != nil {
+		return false
+	}
+	_, ok := expandShorthand(value, config.Forges)
+	return ok
+}
+
+// resolveRemote returns the local clone of a repository given as a URL or
+// forge shorthand, cloning or updating it as needed. Local paths are
+// returned unchanged.
+func resolveRemote(value string) (string, error) {
+	config, err := loadConfig()
+	if err != nil {
+		return "", err
+	}
+	if url, ok := expandShorthand(value, config.Forges); ok {
+		value = url
+	}
+	if !isRemoteURL(value) {
+		return value, nil
+	}
+	return cloneRemote(value)
+}
+
+// isRemoteURL reports whether a --repo value is a git URL to clone rather
+// than a local path
+func isRemoteURL(value string) bool {
+	for _, scheme := range remoteSchemes {
+		if strings.HasPrefix(value, scheme) {
+			return true
+		}
+	}
+	if _, err := os.Stat(value); err == nil {
+		return false
+	}
+	return scpLikePattern.MatchString(value)
+}
+
+// cacheDirEnv overrides the directory remote repositories are cloned into
+const cacheDirEnv = "GITWHO_CACHE_DIR"
+
+// fetchedMarker is the file in a clone's .git directory whose modification
+// time records when the clone was last fetched
+const fetchedMarker = "gitwho-fetched"
+
+// cacheDir returns the directory remote repositories are cloned into
+func cacheDir() (string, error) {
+	if dir := os.Getenv(cacheDirEnv); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("Error finding the cache directory: %v", err)
+	}
+	return filepath.Join(dir, "gitwho", "repos"), nil
+}
+
+// cacheKey turns a git URL into a relative directory name such as
+// github.com/org/repo
+func cacheKey(url string) string {
+	for _, scheme := range remoteSchemes {
+		url = strings.TrimPrefix(url, scheme)
+	}
+	if at := strings.Index(url, "@"); at >= 0 && at < strings.IndexAny(url+"/", "/:") {
+		url = url[at+1:]
+	}
+	url = strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".git")
+
+	var parts []string
+	for _, part := range strings.FieldsFunc(url, func(r rune) bool { return r == '/' || r == ':' }) {
+		if part != "." && part != ".." {
+			parts = append(parts, part)
+		}
+	}
+	return filepath.Join(parts...)
+}
+
+// resolveToken returns the access token from --token or GITWHO_TOKEN
+func resolveToken() string {
+	if accessToken != "" {
+		return accessToken
+	}
+	return os.Getenv(tokenEnv)
+}
+
+// credentialEnv returns the environment that makes git send the access
+// token from --token or GITWHO_TOKEN with HTTPS requests to the host of
+// url. The token is passed as configuration through the environment, so it
+// neither shows up in the process list nor is stored in the clone's
+// config. It is never sent over plain HTTP, and the header is scoped to
+// the host with redirects turned off so it cannot follow a redirect to
+// another host.
+func credentialEnv(url string) []string {
+	token := resolveToken()
+	if token == "" {
+		return nil
+	}
+	rest, ok := strings.CutPrefix(url, "https://")
+	if !ok {
+		if strings.HasPrefix(url, "http://") {
+			infof("Not sending the access token to %s over plain HTTP\n", url)
+		}
+		return nil
+	}
+
+	host, _, _ := strings.Cut(rest, "/")
+	if at := strings.LastIndex(host, "@"); at >= 0 {
+		host = host[at+1:]
+	}
+	user, ok := tokenUsers[host]
+	if !ok {
+		user = "x-access-token"
+	}
+	auth := base64.StdEncoding.EncodeToString([]byte(user + ":" + token))
+	scope := "http.https://" + host + "/"
+	return []string{
+		"GIT_CONFIG_COUNT=2",
+		"GIT_CONFIG_KEY_0=" + scope + ".extraHeader",
+		"GIT_CONFIG_VALUE_0=Authorization: Basic " + auth,
+		"GIT_CONFIG_KEY_1=" + scope + ".followRedirects",
+		"GIT_CONFIG_VALUE_1=false",
+		// Fail instead of prompting for a password when the token is rejected
+		"GIT_TERMINAL_PROMPT=0",
+	}
+}
+
+// cloneRemote clones a remote repository into the cache, or updates the
+// cached clone, and returns its path
+func cloneRemote(url string) (string, error) {
+	cache, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(cache, cacheKey(url))
+
+	var stderr io.Writer = os.Stderr
+	if quiet {
+		stderr = nil
+	}
+
+	cached, err := isCachedClone(dir)
+	if err != nil {
+		return "", fmt.Errorf("Error: %v", err)
+	}
+	if cached {
+		fresh, err := isFresh(dir, cacheTTL)
+		if err != nil {
+			return "", err
+		}
+		if fresh {
+			debugf("Using %s, fetched within %s\n", dir, cacheTTL)
+			return dir, nil
+		}
+		infof("Updating %s in %s\n", url, dir)
+		return dir, updateClone(dir, url, stderr)
+	}
+
+	// Anything else at the path, such as the parent directory of another
+	// clone, is left alone
+	empty, err := isEmptyDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("Error reading the cache: %v", err)
+	}
+	if !empty {
+		return "", fmt.Errorf("Error: cannot clone %s into %s, which is not empty and not a clone made by gitwho", url, dir)
+	}
+
+	infof("Cloning %s into %s\n", url, dir)
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return "", fmt.Errorf("Error creating the cache directory: %v", err)
+	}
+	args := []string{"clone", "--quiet"}
+	if cloneDepth > 0 {
+		args = append(args, "--depth", strconv.Itoa(cloneDepth))
+	}
+	args = append(args, "--", url, dir)
+	if _, err := runGitEnv(credentialEnv(url), "", stderr, args...); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("Error cloning %s: %v", url, err)
+	}
+	return dir, markFetched(dir)
+}
+
+// isCachedClone reports whether dir is a clone gitwho made: it carries the
+// marker gitwho leaves when it fetches, and it is the top of its own work
+// tree. A directory inside another repository, such as a cache below a
+// work tree, is not, and must never be fetched or reset.
+func isCachedClone(dir string) (bool, error) {
+	if !isRegularFile(filepath.Join(dir, ".git", fetchedMarker)) {
+		return false, nil
+	}
+	top, err := runGit(nil, "-C", dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		if runCtx.Err() != nil {
+			return false, err
+		}
+		return false, nil
+	}
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(top) == resolved, nil
+}
+
+// isEmptyDir reports whether dir is an empty directory or does not exist
+func isEmptyDir(dir string) (bool, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return true, nil
+	}
+	return len(entries) == 0, err
+}
+
+// updateClone fetches a cached clone and moves its checked-out branch to
+// the fetched upstream. Anything but a clone gitwho made is left alone.
+func updateClone(dir string, url string, stderr io.Writer) error {
+	cached, err := isCachedClone(dir)
+	if err != nil {
+		return fmt.Errorf("Error: %v", err)
+	}
+	if !cached {
+		return fmt.Errorf("Error: %s is not a clone made by gitwho, not updating it", dir)
+	}
+	fetch := []string{"-C", dir, "fetch", "--quiet", "--prune"}
+	if cloneDepth > 0 {
+		fetch = append(fetch, "--depth", strconv.Itoa(cloneDepth))
+	}
+	if _, err := runGitEnv(credentialEnv(url), "", stderr, append(fetch, "origin")...); err != nil {
+		return fmt.Errorf("Error fetching %s: %v", url, err)
+	}
+	if _, err := runGit(stderr, "-C", dir, "reset", "--quiet", "--hard", "@{upstream}"); err != nil {
+		return fmt.Errorf("Error updating %s: %v", url, err)
+	}
+	return markFetched(dir)
+}
+
+// markFetched records that a clone was fetched just now
+func markFetched(dir string) error {
+	marker := filepath.Join(dir, ".git", fetchedMarker)
+	if err := os.WriteFile(marker, nil, 0644); err != nil {
+		return fmt.Errorf("Error updating the cache: %v", err)
+	}
+	now := time.Now()
+	return os.Chtimes(marker, now, now)
+}
+
+// lastFetched returns when a clone was last fetched, or the zero time when
+// it is unknown
+func lastFetched(dir string) time.Time {
+	info, err := os.Stat(filepath.Join(dir, ".git", fetchedMarker))
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// isFresh reports whether a clone was fetched within ttl, a duration such
+// as 30min or 1d. An empty or zero ttl fetches on every use.
+func isFresh(dir string, ttl string) (bool, error) {
+	if ttl == "" || ttl == "0" {
+		return false, nil
+	}
+	now := time.Now()
+	cutoff, err := cacheCutoff(ttl, now)
+	if err != nil {
+		return false, err
+	}
+	return lastFetched(dir).After(cutoff), nil
+}
+
+// cacheCutoff returns the time a duration such as 30min, 12h, 7d or 3m
+// before now. As with --last, m means months.
+func cacheCutoff(ttl string, now time.Time) (time.Time, error) {
+	if match := durationPattern.FindStringSubmatch(strings.ToLower(ttl)); match != nil {
+		switch match[2] {
+		case "min", "mins", "minute", "minutes":
+			n, _ := strconv.Atoi(match[1])
+			return now.Add(-time.Duration(n) * time.Minute), nil
+		}
+	}
+	if cutoff, err := timeRangeStart(ttl, now); err == nil {
+		return cutoff, nil
+	}
+	return time.Time{}, fmt.Errorf("Error: invalid cache TTL %q, expected a duration like 30min, 12h, 7d or 3m (months)", ttl)
+}
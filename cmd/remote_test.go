@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestCredentialEnv(t *testing.T) {
+	defer func(previous string) { accessToken = previous }(accessToken)
+	accessToken = "secret"
+
+	env := credentialEnv("https://user@gitlab.com/group/project.git")
+	auth := base64.StdEncoding.EncodeToString([]byte("oauth2:secret"))
+	want := []string{
+		"GIT_CONFIG_COUNT=2",
+		"GIT_CONFIG_KEY_0=http.https://gitlab.com/.extraHeader",
+		"GIT_CONFIG_VALUE_0=Authorization: Basic " + auth,
+		"GIT_CONFIG_KEY_1=http.https://gitlab.com/.followRedirects",
+		"GIT_CONFIG_VALUE_1=false",
+		"GIT_TERMINAL_PROMPT=0",
+	}
+	if !slices.Equal(env, want) {
+		t.Errorf("credentialEnv = %q, want %q", env, want)
+	}
+
+	for _, url := range []string{"http://example.com/repo.git", "git@github.com:org/repo.git", "ssh://github.com/org/repo"} {
+		if env := credentialEnv(url); env != nil {
+			t.Errorf("credentialEnv(%s) = %q, want no token", url, env)
+		}
+	}
+}
+
+func TestCacheCutoff(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		ttl  string
+		want time.Time
+	}{
+		{"30min", now.Add(-30 * time.Minute)},
+		{"12h", now.Add(-12 * time.Hour)},
+		{"7d", now.AddDate(0, 0, -7)},
+		// m means months, as with --last
+		{"3m", now.AddDate(0, -3, 0)},
+	}
+	for _, tt := range tests {
+		got, err := cacheCutoff(tt.ttl, now)
+		if err != nil {
+			t.Errorf("cacheCutoff(%s): %v", tt.ttl, err)
+		} else if !got.Equal(tt.want) {
+			t.Errorf("cacheCutoff(%s) = %v, want %v", tt.ttl, got, tt.want)
+		}
+	}
+	if _, err := cacheCutoff("1h30m", now); err == nil {
+		t.Errorf("cacheCutoff(1h30m) succeeded, want an error")
+	}
+}
+
+func TestCachedClonesNeedMarker(t *testing.T) {
+	// A cache directory that is itself a repository and holds another
+	// repository gitwho did not clone
+	cache := newTestRepo(t)
+	other := filepath.Join(cache.dir, "other", "repo", ".git")
+	clone := filepath.Join(cache.dir, "github.com", "org", "repo", ".git")
+	for _, dir := range []string{other, clone} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(clone, fetchedMarker), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(cacheDirEnv, cache.dir)
+
+	clones, err := cachedClones(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clones) != 1 || clones[0].Path != filepath.Join("github.com", "org", "repo") {
+		t.Errorf("cachedClones = %+v, want only github.com/org/repo", clones)
+	}
+}
+
+func TestCleanCacheInvalidOlderThan(t *testing.T) {
+	// The duration is rejected whether or not there are clones to compare
+	// it to, and before any is removed
+	cache := t.TempDir()
+	t.Setenv(cacheDirEnv, cache)
+	if _, err := cleanCache(nil, "1h30m", time.Now()); err == nil {
+		t.Errorf("cleaning an empty cache older than 1h30m succeeded, want an error")
+	}
+
+	clone := filepath.Join(cache, "github.com", "org", "repo", ".git")
+	if err := os.MkdirAll(clone, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(clone, fetchedMarker), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cleanCache(nil, "1h30m", time.Now()); err == nil {
+		t.Errorf("cleaning clones older than 1h30m succeeded, want an error")
+	}
+	if _, err := os.Stat(clone); err != nil {
+		t.Errorf("the clone was removed: %v", err)
+	}
+}
+
+func TestCloneRemoteInsideRepo(t *testing.T) {
+	// The cache is below the work tree of a repository with uncommitted
+	// changes, and the clone goes where another clone's parent directory is
+	outer := newTestRepo(t)
+	outer.commit("alice", map[string]string{"notes.txt": "committed\n"})
+	notes := filepath.Join(outer.dir, "notes.txt")
+	if err := os.WriteFile(notes, []byte("uncommitted\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cache := filepath.Join(outer.dir, "cache")
+	t.Setenv(cacheDirEnv, cache)
+
+	src := newTestRepo(t)
+	src.commit("bob", map[string]string{"main.go": "package main\n"})
+	url := "file://" + src.dir
+	dir := filepath.Join(cache, cacheKey(url))
+	if err := os.MkdirAll(filepath.Join(dir, "other"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := cloneRemote(url); err == nil {
+		t.Error("cloning into a directory that is no clone succeeded, want an error")
+	}
+	if contents, err := os.ReadFile(notes); err != nil || string(contents) != "uncommitted\n" {
+		t.Fatalf("the enclosing repository's changes are gone: %q, %v", contents, err)
+	}
+
+	// An empty directory is cloned into, and the clone is updated next time
+	if err := os.Remove(filepath.Join(dir, "other")); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		got, err := cloneRemote(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cached, err := isCachedClone(got); got != dir || !cached || err != nil {
+			t.Errorf("cloneRemote = %s, a clone %v, %v, want the clone in %s", got, cached, err, dir)
+		}
+	}
+	if contents, err := os.ReadFile(notes); err != nil || string(contents) != "uncommitted\n" {
+		t.Errorf("the enclosing repository's changes are gone: %q, %v", contents, err)
+	}
+}
+
+func TestUpdateCloneInsideRepo(t *testing.T) {
+	// The cache is a clone with uncommitted changes, and holds a directory
+	// that looks like a cached clone but is not a repository of its own
+	src := newTestRepo(t)
+	src.commit("alice", map[string]string{"notes.txt": "committed\n"})
+	cache := filepath.Join(t.TempDir(), "cache")
+	if _, err := runGit(nil, "clone", "--quiet", src.dir, cache); err != nil {
+		t.Fatal(err)
+	}
+	notes := filepath.Join(cache, "notes.txt")
+	if err := os.WriteFile(notes, []byte("uncommitted\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(cache, "github.com", "org", "repo")
+	if err := os.MkdirAll(filepath.Join(dir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".git", fetchedMarker), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(cacheDirEnv, cache)
+
+	if err := updateClone(dir, "https://github.com/org/repo", nil); err == nil {
+		t.Error("updating a directory inside another repository succeeded, want an error")
+	}
+	if contents, err := os.ReadFile(notes); err != nil || string(contents) != "uncommitted\n" {
+		t.Errorf("the enclosing repository's changes are gone: %q, %v", contents, err)
+	}
+}
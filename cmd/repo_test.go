@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testRepo is a git repository in a temporary directory that tests commit
+// files to
+type testRepo struct {
+	t       *testing.T
+	dir     string
+	commits int
+}
+
+// newTestRepo creates an empty repository that ignores the user's and the
+// system's git configuration
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := &testRepo{t: t, dir: dir}
+	repo.git("init", "-q", "-b", "main")
+	return repo
+}
+
+// git runs git in the repository and returns its output
+func (r *testRepo) git(args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", r.dir}, args...)...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %v: %v\n%s", args, err, output)
+	}
+	return string(output)
+}
+
+// commit writes the files, removes those with empty contents, and commits
+// them as author, an hour after the previous commit
+func (r *testRepo) commit(author string, files map[string]string) {
+	r.t.Helper()
+	for name, contents := range files {
+		path := filepath.Join(r.dir, name)
+		if contents == "" {
+			if err := os.Remove(path); err != nil {
+				r.t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			r.t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			r.t.Fatal(err)
+		}
+	}
+	r.commits++
+	// One hour apart, in order however many commits a test makes
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(r.commits) * time.Hour).Format(time.RFC3339)
+	r.git("add", "-A")
+	r.git("-c", "user.name="+author, "-c", "user.email="+author+"@example.com",
+		"commit", "-q", "--date", date, "-m", fmt.Sprintf("Commit %d", r.commits))
+}
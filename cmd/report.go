@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"github.com/PerArneng/gitwho/pkg/gitwho"
+	"github.com/spf13/cobra"
+)
+
+// Chart dimensions and limits for the HTML report
+const (
+	reportBarHeight   = 22
+	reportBarWidth    = 480
+	reportLabelWidth  = 200
+	reportPieRadius   = 120
+	reportPieSlices   = 8
+	reportOthersLabel = "Others"
+)
+
+// reportPalette is the set of colors used for pie chart slices
+var reportPalette = []string{
+	"#4e79a7", "#f28e2b", "#e15759", "#76b7b2", "#59a14f",
+	"#edc948", "#b07aa1", "#ff9da7", "#9c755f",
+}
+
+var htmlOutput string
+
+// reportCmd represents the report command
+var reportCmd = &cobra.Command{
+	Use:   "report [file/directory]",
+	Short: "Generate a standalone HTML report",
+	Long: `Report generates a self-contained HTML page with the contributor table
+and charts of additions, deletions and share of changes per author. The
+page has no external dependencies, so it can be shared as a single file.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+
+		commits, err := collectCommits([]string{path}, lastTimeRange, repoPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		contributors := gitwho.Aggregate(commits)
+		gitwho.ComputeShares(contributors)
+		report := &Report{
+			Path:         path,
+			TimeRange:    lastTimeRange,
+			Contributors: contributors,
+			Summary:      gitwho.Summarize(contributors),
+		}
+
+		file, err := os.Create(htmlOutput)
+		if err != nil {
+			fmt.Printf("Error creating report: %v\n", err)
+			os.Exit(1)
+		}
+		if err := displayHTML(file, report, newRenderOptions(false)); err != nil {
+			fmt.Printf("Error writing report: %v\n", err)
+			os.Exit(1)
+		}
+		if err := file.Close(); err != nil {
+			fmt.Printf("Error writing report: %v\n", err)
+			os.Exit(1)
+		}
+		infof("Wrote report to %s\n", htmlOutput)
+	},
+}
+
+func init() {
+	reportCmd.Flags().StringVar(&htmlOutput, "html", "", "File to write the HTML report to")
+	reportCmd.MarkFlagRequired("html")
+	rootCmd.AddCommand(reportCmd)
+}
+
+// htmlReport holds the data rendered by the report template
+type htmlReport struct {
+	Path         string
+	TimeRange    string
+	Qualifiers   []string
+	Generated    string
+	Contributors []contributorRecord
+	Bars         []reportBar
+	Slices       []reportSlice
+	ChartWidth   int
+	ChartHeight  int
+	LabelWidth   int
+	PieRadius    int
+	PieSize      int
+}
+
+// reportBar is one row of the additions/deletions bar chart
+type reportBar struct {
+	Label        string
+	Y            int
+	AddedWidth   float64
+	DeletedX     float64
+	DeletedWidth float64
+	Additions    int
+	Deletions    int
+}
+
+// reportSlice is one slice of the share-of-changes pie chart
+type reportSlice struct {
+	Label   string
+	Color   string
+	Path    string
+	Circle  bool
+	Percent float64
+}
+
+// displayHTML writes the report as a standalone HTML page
+func displayHTML(w io.Writer, report *Report, options renderOptions) error {
+	return reportTemplate.Execute(w, buildHTMLReport(report, options))
+}
+
+// buildHTMLReport lays out the table and charts for the report template
+func buildHTMLReport(report *Report, options renderOptions) htmlReport {
+	doc := buildReportDocument(report, options)
+	html := htmlReport{
+		Path:         report.Path,
+		TimeRange:    report.TimeRange,
+		Qualifiers:   options.Qualifiers,
+		Generated:    time.Now().Format("2006-01-02 15:04"),
+		Contributors: doc.Contributors,
+		ChartWidth:   reportLabelWidth + reportBarWidth,
+		LabelWidth:   reportLabelWidth,
+		PieRadius:    reportPieRadius,
+		PieSize:      2 * reportPieRadius,
+	}
+
+	// Bars are scaled against the contributor with the most changes
+	maxTotal := 0
+	for _, record := range doc.Contributors {
+		maxTotal = max(maxTotal, record.Total)
+	}
+	for i, record := range doc.Contributors {
+		bar := reportBar{
+			Label:     truncateString(record.Name, 30),
+			Y:         i * reportBarHeight,
+			Additions: record.Additions,
+			Deletions: record.Deletions,
+		}
+		if maxTotal > 0 {
+			bar.AddedWidth = float64(record.Additions) * reportBarWidth / float64(maxTotal)
+			bar.DeletedWidth = float64(record.Deletions) * reportBarWidth / float64(maxTotal)
+		}
+		bar.DeletedX = reportLabelWidth + bar.AddedWidth
+		html.Bars = append(html.Bars, bar)
+	}
+	html.ChartHeight = len(html.Bars) * reportBarHeight
+
+	html.Slices = buildPieSlices(doc.Contributors)
+	return html
+}
+
+// buildPieSlices computes the pie chart slices for each contributor's share
+// of total changes. Contributors beyond the slice limit are merged.
+func buildPieSlices(records []contributorRecord) []reportSlice {
+	grandTotal := 0
+	for _, record := range records {
+		grandTotal += record.Total
+	}
+	if grandTotal == 0 {
+		return nil
+	}
+
+	type share struct {
+		label string
+		total int
+	}
+	var shares []share
+	for i, record := range records {
+		if i < reportPieSlices {
+			shares = append(shares, share{record.Name, record.Total})
+			continue
+		}
+		if len(shares) == reportPieSlices {
+			shares = append(shares, share{reportOthersLabel, 0})
+		}
+		shares[reportPieSlices].total += record.Total
+	}
+
+	var slices []reportSlice
+	r := float64(reportPieRadius)
+	angle := 0.0
+	for i, s := range shares {
+		if s.total == 0 {
+			continue
+		}
+		fraction := float64(s.total) / float64(grandTotal)
+		slice := reportSlice{
+			Label:   s.label,
+			Color:   reportPalette[i%len(reportPalette)],
+			Percent: fraction * 100,
+		}
+
+		if fraction >= 1 {
+			slice.Circle = true
+		} else {
+			end := angle + fraction*2*math.Pi
+			largeArc := 0
+			if fraction > 0.5 {
+				largeArc = 1
+			}
+			slice.Path = fmt.Sprintf("M %.2f %.2f L %.2f %.2f A %.2f %.2f 0 %d 1 %.2f %.2f Z",
+				r, r,
+				r+r*math.Sin(angle), r-r*math.Cos(angle),
+				r, r, largeArc,
+				r+r*math.Sin(end), r-r*math.Cos(end))
+			angle = end
+		}
+		slices = append(slices, slice)
+	}
+	return slices
+}
+
+// reportTemplate renders the standalone HTML report
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>GitWho report for {{.Path}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #24292f; }
+h1 { font-size: 1.5em; }
+.meta { color: #57606a; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { padding: 4px 12px; border-bottom: 1px solid #d0d7de; text-align: left; }
+td.num, th.num { text-align: right; }
+.charts { display: flex; flex-wrap: wrap; gap: 3em; align-items: flex-start; }
+.legend { list-style: none; padding: 0; }
+.legend span { display: inline-block; width: 12px; height: 12px; margin-right: 6px; }
+svg text { font-size: 12px; }
+</style>
+</head>
+<body>
+<h1>Contributor Statistics for {{.Path}}{{if .TimeRange}} (last {{.TimeRange}}){{end}}{{range .Qualifiers}} ({{.}}){{end}}</h1>
+<p class="meta">Generated by GitWho on {{.Generated}}</p>
+{{if not .Contributors}}
+<p>No changes found for the specified path and time range.</p>
+{{else}}
+<div class="charts">
+<div>
+<h2>Additions and deletions</h2>
+<svg width="{{.ChartWidth}}" height="{{.ChartHeight}}">
+{{range .Bars}}<g>
+<text x="0" y="{{.Y}}" dy="15">{{.Label}}</text>
+<rect x="{{$.LabelWidth}}" y="{{.Y}}" width="{{.AddedWidth}}" height="18" fill="#2da44e"><title>{{.Additions}} added</title></rect>
+<rect x="{{.DeletedX}}" y="{{.Y}}" width="{{.DeletedWidth}}" height="18" fill="#cf222e"><title>{{.Deletions}} deleted</title></rect>
+</g>
+{{end}}</svg>
+</div>
+{{if .Slices}}<div>
+<h2>Share of changes</h2>
+<svg width="{{.PieSize}}" height="{{.PieSize}}">
+{{range .Slices}}{{if .Circle}}<circle cx="{{$.PieRadius}}" cy="{{$.PieRadius}}" r="{{$.PieRadius}}" fill="{{.Color}}"><title>{{.Label}}</title></circle>{{else}}<path d="{{.Path}}" fill="{{.Color}}"><title>{{.Label}}</title></path>{{end}}
+{{end}}</svg>
+<ul class="legend">
+{{range .Slices}}<li><span style="background: {{.Color}}"></span>{{.Label}} ({{printf "%.1f" .Percent}}%)</li>
+{{end}}</ul>
+</div>{{end}}
+</div>
+<table>
+<thead><tr><th>Name</th><th>Email</th><th class="num">Commits</th><th class="num">Added</th><th class="num">Deleted</th><th class="num">Total</th></tr></thead>
+<tbody>
+{{range .Contributors}}<tr><td>{{.Name}}</td><td>{{.Email}}</td><td class="num">{{.Commits}}</td><td class="num">{{.Additions}}</td><td class="num">{{.Deletions}}</td><td class="num">{{.Total}}</td></tr>
+{{end}}</tbody>
+</table>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var reviewsFormat string
+
+// reviewsCmd represents the reviews command
+var reviewsCmd = &cobra.Command{
+	Use:   "reviews [file/directory]",
+	Short: "Count Reviewed-by and Signed-off-by trailers per person",
+	Long: `Reviews counts the Reviewed-by and Signed-off-by trailers of the commits
+that touched a file or directory, so you can see who reviews and signs off
+the code and not only who writes it. Trailer identities are resolved
+through .mailmap and the configured aliases like authors are.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+		if !isValidTableFormat(reviewsFormat) {
+			fmt.Printf("Error: Invalid format %s. Supported formats: table, csv, json\n", reviewsFormat)
+			os.Exit(1)
+		}
+
+		countReviews = true
+		commits, err := collectCommits([]string{path}, lastTimeRange, repoPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		config, err := loadConfig()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		reviewers := buildReviews(commits, config.Aliases)
+		if err := displayTable(os.Stdout, reviewsFormat, reviewsTable(reviewers), reviewers); err != nil {
+			fmt.Printf("Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	reviewsCmd.Flags().StringVar(&reviewsFormat, "format", "table", "Output format (table, csv, json)")
+	rootCmd.AddCommand(reviewsCmd)
+}
+
+// reviewer holds the review trailer counts of one person
+type reviewer struct {
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	Reviewed  int    `json:"reviewed"`
+	SignedOff int    `json:"signedOff"`
+}
+
+// buildReviews counts the Reviewed-by and Signed-off-by trailers per
+// person, most reviews first. Commits credited to several co-authors are
+// counted once.
+func buildReviews(commits []*Commit, identities []Identity) []*reviewer {
+	stats := make(map[string]*reviewer)
+	credit := func(value string, count func(r *reviewer)) {
+		name, email, ok := parseIdentity(value)
+		if !ok {
+			return
+		}
+		if normalizeEmails {
+			email = normalizeEmail(email)
+		}
+		name, email = canonicalIdentity(name, email, identities)
+		key := strings.ToLower(email)
+		if stats[key] == nil {
+			stats[key] = &reviewer{Name: name, Email: email}
+		}
+		count(stats[key])
+	}
+
+	seen := make(map[string]bool)
+	for _, commit := range commits {
+		if seen[commit.Hash] {
+			continue
+		}
+		seen[commit.Hash] = true
+		for _, value := range commit.Reviewers {
+			credit(value, func(r *reviewer) { r.Reviewed++ })
+		}
+		for _, value := range commit.SignOffs {
+			credit(value, func(r *reviewer) { r.SignedOff++ })
+		}
+	}
+
+	reviewers := make([]*reviewer, 0, len(stats))
+	for _, r := range stats {
+		reviewers = append(reviewers, r)
+	}
+	slices.SortFunc(reviewers, func(a, b *reviewer) int {
+		return cmp.Or(
+			cmp.Compare(b.Reviewed, a.Reviewed),
+			cmp.Compare(b.SignedOff, a.SignedOff),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+	return reviewers
+}
+
+// reviewsTable lays out the review trailer counts
+func reviewsTable(reviewers []*reviewer) textTable {
+	table := textTable{
+		Headers: []string{"NAME", "EMAIL", "REVIEWED", "SIGNED_OFF"},
+		Numeric: []bool{false, false, true, true},
+	}
+	for _, r := range reviewers {
+		table.Rows = append(table.Rows, []string{
+			r.Name,
+			r.Email,
+			strconv.Itoa(r.Reviewed),
+			strconv.Itoa(r.SignedOff),
+		})
+	}
+	return table
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+var sinceTag string
+var betweenTags []string
+var branchRef string
+var allRefs bool
+
+// revisionArgs returns the revisions git log should walk, as selected by
+// --range, --since-tag, --between-tags, --branch and --all. Tags and
+// branches are checked to exist in the repository.
+func revisionArgs(repoPath string) ([]string, error) {
+	if allRefs {
+		if revisionRange != "" || sinceTag != "" || len(betweenTags) > 0 || branchRef != "" {
+			return nil, fmt.Errorf("Error: --all cannot be combined with a revision range, tags or --branch")
+		}
+		return []string{"--all"}, nil
+	}
+
+	selected := 0
+	for _, set := range []bool{revisionRange != "", sinceTag != "", len(betweenTags) > 0} {
+		if set {
+			selected++
+		}
+	}
+	if selected > 1 {
+		return nil, fmt.Errorf("Error: only one of a revision range, --since-tag and --between-tags can be used")
+	}
+
+	// The branch takes the place of HEAD, so it only combines with --since-tag
+	head := "HEAD"
+	if branchRef != "" {
+		if revisionRange != "" || len(betweenTags) > 0 {
+			return nil, fmt.Errorf("Error: --branch cannot be combined with a revision range or --between-tags")
+		}
+		if err := checkRevision(repoPath, branchRef); err != nil {
+			return nil, err
+		}
+		head = branchRef
+	}
+
+	switch {
+	case revisionRange != "":
+		return []string{revisionRange}, nil
+	case sinceTag != "":
+		if err := checkTag(repoPath, sinceTag); err != nil {
+			return nil, err
+		}
+		return []string{sinceTag + ".." + head}, nil
+	case len(betweenTags) > 0:
+		if len(betweenTags) != 2 {
+			return nil, fmt.Errorf("Error: --between-tags needs exactly two tags, got %s", strings.Join(betweenTags, ", "))
+		}
+		for _, tag := range betweenTags {
+			if err := checkTag(repoPath, tag); err != nil {
+				return nil, err
+			}
+		}
+		return []string{betweenTags[0] + ".." + betweenTags[1]}, nil
+	case branchRef != "":
+		return []string{branchRef}, nil
+	}
+	return nil, nil
+}
+
+// revisionLabel describes the selected revisions for report headers
+func revisionLabel() string {
+	switch {
+	case revisionRange != "":
+		return revisionRange
+	case sinceTag != "" && branchRef != "":
+		return sinceTag + ".." + branchRef
+	case sinceTag != "":
+		return sinceTag + "..HEAD"
+	case len(betweenTags) == 2:
+		return betweenTags[0] + ".." + betweenTags[1]
+	case allRefs:
+		return "all branches"
+	}
+	return branchRef
+}
+
+// checkTag returns an error if the repository has no tag named tag
+func checkTag(repoPath string, tag string) error {
+	if usesGoGit() {
+		if !goGitHasRevision(repoPath, "refs/tags/"+tag) {
+			return fmt.Errorf("Error: unknown tag %s", tag)
+		}
+		return nil
+	}
+	if _, err := runGit(nil, "-C", repoPath, "rev-parse", "--verify", "--quiet", "refs/tags/"+tag); err != nil {
+		return fmt.Errorf("Error: unknown tag %s", tag)
+	}
+	return nil
+}
+
+// checkRevision returns an error if rev does not name a commit in the
+// repository
+func checkRevision(repoPath string, rev string) error {
+	if usesGoGit() {
+		if !goGitHasRevision(repoPath, rev) {
+			return fmt.Errorf("Error: unknown branch or revision %s", rev)
+		}
+		return nil
+	}
+	if _, err := runGit(nil, "-C", repoPath, "rev-parse", "--verify", "--quiet", rev+"^{commit}"); err != nil {
+		return fmt.Errorf("Error: unknown branch or revision %s", rev)
+	}
+	return nil
+}
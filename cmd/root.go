@@ -4,18 +4,30 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
-	"bytes"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"sort"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/PerArneng/gitwho/pkg/gitwho"
+	"github.com/mattn/go-runewidth"
 	"github.com/spf13/cobra"
 )
 
+// The statistics are computed by the gitwho library package
+type (
+	Contributor = gitwho.Contributor
+	Report      = gitwho.Report
+	Summary     = gitwho.Summary
+	Commit      = gitwho.Commit
+	FileChange  = gitwho.FileChange
+)
+
 // Version information set by GoReleaser at build time
 var (
 	version = "dev"
@@ -23,34 +35,152 @@ var (
 	date    = "unknown"
 )
 
-// Contributor represents a git contributor with their statistics
-type Contributor struct {
-	Name      string
-	Email     string
-	Commits   int
-	Additions int
-	Deletions int
-}
-
 var lastTimeRange string
+var sinceDate string
+var untilDate string
+var groupBy string
+var excludeBots bool
+var noMerges bool
+var followRenames bool
+var findRenames string
+var ignoreWhitespace bool
+var extraGitArgs []string
+var findCopies string
+var firstParent bool
+var authorPatterns []string
+var excludeAuthorPatterns []string
 var repoPath string
+var repoPaths []string
+var repoList string
+var outputFormat string
+var quiet bool
+var outputPath string
+var workHours string
+var onlyWorkHours bool
+var onlyOffHours bool
+var noHeader bool
+var sortField string
+var reverseSort bool
+var topN int
+var showOthers bool
+var minCommits int
+var minLines int
+var showPercent bool
+var showSummary bool
+var showExtended bool
+var wideOutput bool
+var humanizeMode string
+var columnList string
+var perPath bool
+var pathsFrom string
+var fixtureInput string
+var excludePatterns []string
+var includeVendored bool
+var recurseSubmodules bool
+var showActivity bool
+
+// revisionRange limits the analysis to a range of commits, e.g. main..topic
+var revisionRange string
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "gitwho [file/directory]",
+	Use:   "gitwho [file/directory...]",
 	Short: "Show git contributors statistics for a file or directory",
 	Long: `GitWho analyzes git history for a specific file or directory and 
 shows statistics about contributors who made changes to it.
 
 For directories, it recursively analyzes all files within that directory.
+Several paths can be given; their statistics are combined into one result,
+or shown one after the other with --per-path. JSON output is then an array
+of the results, YAML a stream of documents, and CSV, TSV and edges one
+table with a leading path column.
 Results are sorted with the contributors who made the most changes at the top.`,
-	Args: cobra.MaximumNArgs(1),
+	Args: cobra.ArbitraryArgs,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		startRun()
+		if err := absoluteGitEnv(); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if !isValidBackend(backend) {
+			fmt.Printf("Error: invalid --backend %s, expected %s\n", backend, strings.Join(backendNames(), " or "))
+			os.Exit(1)
+		}
+		if _, err := resolveColor(colorMode, os.Stdout); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if repoList != "" {
+			listed, err := readPathList(repoList)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			repoPaths = append(repoPaths, listed...)
+		}
+		if len(repoPaths) > 1 && cmd.HasParent() {
+			fmt.Printf("Error: %s analyzes one repository, got %d\n", cmd.CommandPath(), len(repoPaths))
+			os.Exit(1)
+		}
+
+		// Git URLs and forge shorthands given as --repo are cloned into the
+		// cache first. A repository given twice is analyzed once.
+		var dirs []string
+		for _, repo := range repoPaths {
+			dir, err := resolveRemote(repo)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			if !slices.ContainsFunc(dirs, func(d string) bool { return sameFile(d, dir) }) {
+				dirs = append(dirs, dir)
+			}
+		}
+		repoPaths = dirs
+		if len(repoPaths) > 0 {
+			repoPath = repoPaths[0]
+		}
+	},
 	Run: func(cmd *cobra.Command, args []string) {
-		path := "."
-		if len(args) == 1 {
-			path = args[0]
+		// A revision range like v1.2.0..HEAD may be given instead of --range
+		var paths []string
+		for _, arg := range args {
+			// A forge shorthand such as gh:org/repo may be given instead of --repo
+			if isRepoShorthand(arg) {
+				dir, err := resolveRemote(arg)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+				repoPaths = append(repoPaths, dir)
+				continue
+			}
+			if !isRevisionRange(arg) {
+				paths = append(paths, arg)
+				continue
+			}
+			if revisionRange != "" {
+				fmt.Printf("Error: only one revision range can be given, got %s and %s\n", revisionRange, arg)
+				os.Exit(1)
+			}
+			revisionRange = arg
+		}
+		if pathsFrom != "" {
+			listed, err := readPathList(pathsFrom)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			paths = append(paths, listed...)
 		}
-		runGitWho(path, lastTimeRange, repoPath)
+		if fixtureInput != "" && (len(paths) > 0 || len(repoPaths) > 0 || revisionRange != "") {
+			fmt.Println("Error: --from-fixture reports on the whole fixture and takes no paths, repositories or revisions")
+			os.Exit(1)
+		}
+		if len(paths) == 0 {
+			paths = []string{"."}
+		}
+		runGitWho(paths, lastTimeRange, repoPaths)
 	},
 }
 
@@ -58,6 +188,7 @@ Results are sorted with the contributors who made the most changes at the top.`,
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
 	err := rootCmd.Execute()
+	stopRun()
 	if err != nil {
 		os.Exit(1)
 	}
@@ -65,8 +196,82 @@ func Execute() {
 
 func init() {
 	// Define the --last/-l flag
-	rootCmd.Flags().StringVarP(&lastTimeRange, "last", "l", "", "Time range for statistics (day, week, month, year)")
-	rootCmd.Flags().StringVarP(&repoPath, "repo", "r", "", "Path to the git repository (defaults to current directory)")
+	rootCmd.PersistentFlags().StringVarP(&lastTimeRange, "last", "l", "", "Time range for statistics (day, week, month, quarter, half-year, year, calendar-quarter, or a duration like 90d, 2w, 3m, 1y, 18h)")
+	rootCmd.PersistentFlags().StringVar(&sinceDate, "since", "", "Only count commits on or after a date (YYYY-MM-DD or a git date like \"2 weeks ago\")")
+	rootCmd.PersistentFlags().StringVar(&untilDate, "until", "", "Only count commits on or before a date (YYYY-MM-DD or a git date like \"yesterday\")")
+	rootCmd.MarkFlagsMutuallyExclusive("last", "since")
+	rootCmd.MarkFlagsMutuallyExclusive("last", "until")
+	rootCmd.PersistentFlags().BoolVar(&normalizeEmails, "normalize-emails", false, "Lowercase emails and strip +tag suffixes before grouping contributors")
+	rootCmd.PersistentFlags().BoolVar(&mergeNoreply, "merge-noreply", false, "Merge GitHub noreply addresses with the author's primary email")
+	rootCmd.PersistentFlags().BoolVar(&excludeBots, "exclude-bots", false, "Skip commits by bots such as dependabot, renovate and *[bot] accounts")
+	rootCmd.PersistentFlags().StringArrayVar(&authorPatterns, "author", nil, "Only count commits whose author name or email matches a regex (repeatable)")
+	rootCmd.PersistentFlags().StringArrayVar(&excludeAuthorPatterns, "exclude-author", nil, "Skip commits whose author name or email matches a regex (repeatable)")
+	rootCmd.PersistentFlags().BoolVar(&followRenames, "follow", true, "When analyzing a single file, include its history before renames")
+	rootCmd.PersistentFlags().StringVarP(&findRenames, "find-renames", "M", "", "Detect renames whose content is at least this percent similar (default 50 when given without a value)")
+	rootCmd.PersistentFlags().Lookup("find-renames").NoOptDefVal = "50"
+	rootCmd.PersistentFlags().StringVarP(&findCopies, "find-copies", "C", "", "Detect copies whose content is at least this percent similar (default 50 when given without a value)")
+	rootCmd.PersistentFlags().Lookup("find-copies").NoOptDefVal = "50"
+	rootCmd.PersistentFlags().BoolVarP(&ignoreWhitespace, "ignore-whitespace", "w", false, "Ignore whitespace when counting changed lines, so reformatting commits do not count")
+	rootCmd.PersistentFlags().StringArrayVar(&extraGitArgs, "git-arg", nil, "Extra argument for git log, e.g. --git-arg=--diff-filter=A (repeatable)")
+	rootCmd.PersistentFlags().BoolVar(&noMerges, "no-merges", false, "Skip merge commits")
+	rootCmd.PersistentFlags().BoolVar(&firstParent, "first-parent", false, "Only follow the first parent of merges and credit each merge with the changes it brought in")
+	rootCmd.PersistentFlags().StringVar(&attributionMode, "use", "author", "Credit commits to their author, committer or both")
+	rootCmd.PersistentFlags().BoolVar(&squashPRs, "squash-prs", false, "Credit squash-merged GitHub pull requests to the authors of their commits")
+	rootCmd.PersistentFlags().StringVar(&coAuthorMode, "co-authors", "none", "Credit Co-authored-by trailers: none, full (all lines to every author) or split (lines divided between the authors)")
+	rootCmd.PersistentFlags().StringVar(&groupBy, "group-by", "author", "Group the statistics by author or team")
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Config file with identity aliases (defaults to gitwho/config.yaml in the user config directory)")
+	rootCmd.PersistentFlags().StringVar(&revisionRange, "range", "", "Revision range to analyze, e.g. v1.2.0..HEAD")
+	rootCmd.PersistentFlags().StringVar(&branchRef, "branch", "", "Branch or ref to analyze instead of the checked-out branch")
+	rootCmd.PersistentFlags().BoolVar(&allRefs, "all", false, "Include commits on all branches, not only the checked-out one")
+	rootCmd.PersistentFlags().StringVar(&sinceTag, "since-tag", "", "Analyze the commits since a tag, like --range <tag>..HEAD")
+	rootCmd.PersistentFlags().StringSliceVar(&betweenTags, "between-tags", nil, "Analyze the commits between two tags, e.g. v1.0,v2.0")
+	rootCmd.PersistentFlags().StringArrayVarP(&repoPaths, "repo", "r", nil, "Path or URL of the git repository (defaults to current directory); URLs are cloned into the cache. Repeat to combine several repositories")
+	rootCmd.PersistentFlags().StringVar(&repoList, "repo-list", "", "Read repositories to combine from a file, one path or URL per line (- for stdin)")
+	rootCmd.PersistentFlags().StringVar(&accessToken, "token", "", "Access token for cloning private remote repositories over HTTPS (defaults to $GITWHO_TOKEN)")
+	rootCmd.PersistentFlags().StringVar(&cacheTTL, "cache-ttl", "", "Reuse a cached clone fetched within this duration (e.g. 30min, 12h, 7d) instead of fetching on every use")
+	rootCmd.PersistentFlags().IntVar(&cloneDepth, "clone-depth", 0, "Only clone the last N commits of a remote repository (0 clones the full history)")
+	rootCmd.PersistentFlags().BoolVar(&fetchFullHistory, "fetch-full-history", false, "Fetch the missing history of a shallow clone before the analysis")
+	rootCmd.PersistentFlags().BoolVar(&recurseSubmodules, "recurse-submodules", false, "Include the history of submodules instead of the changes to their commit pointers")
+	rootCmd.PersistentFlags().BoolVar(&includeVendored, "include-vendored", false, "Include files marked linguist-vendored or linguist-generated in .gitattributes")
+	rootCmd.PersistentFlags().StringVar(&backend, "backend", "exec", "How repositories are read: exec runs the git binary, gogit reads them in-process and needs no git installed, libgit2 (builds with -tags libgit2) walks them in-process with libgit2")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Suppress informational messages")
+	rootCmd.PersistentFlags().BoolVar(&verbose, "verbose", false, "Log executed git commands, timing and output sizes to stderr")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "Stop the analysis when it takes longer than this (e.g. 30s, 10m; 0 for no limit)")
+	rootCmd.PersistentFlags().BoolVar(&verbose, "debug", false, "Alias for --verbose")
+	rootCmd.Flags().StringVar(&outputFormat, "format", "table", "Output format (table, json, yaml, csv, tsv, markdown, html, edges, summary-text)")
+	rootCmd.Flags().StringVar(&sortField, "sort", "total", "Sort by commits, additions, deletions, name, email or total")
+	rootCmd.Flags().BoolVar(&reverseSort, "reverse", false, "Reverse the sort order")
+	rootCmd.Flags().IntVar(&minCommits, "min-commits", 0, "Hide contributors with fewer commits")
+	rootCmd.Flags().IntVar(&minLines, "min-lines", 0, "Hide contributors with fewer changed lines")
+	rootCmd.Flags().IntVar(&topN, "top", 0, "Only show the top N contributors (0 shows all)")
+	rootCmd.Flags().BoolVar(&showOthers, "others", false, "With --top, add a row aggregating the remaining contributors")
+	rootCmd.Flags().StringVar(&columnList, "columns", "", "Comma-separated list of columns to show, in order (e.g. name,email,commits,total)")
+	rootCmd.Flags().BoolVar(&showPercent, "percent", false, "Show each contributor's share of commits and changed lines")
+	rootCmd.Flags().BoolVar(&showSigned, "signed", false, "Show the percentage of each contributor's commits that are GPG or SSH signed")
+	rootCmd.Flags().StringVar(&enrichMode, "enrich", "", "Look up contributors on a forge by email and show their handles (github)")
+	rootCmd.Flags().BoolVar(&showActivity, "activity", false, "Add a sparkline of each contributor's commits over recent periods")
+	rootCmd.Flags().IntVar(&activityPeriods, "activity-periods", 12, "Number of periods shown by the activity sparkline")
+	rootCmd.Flags().StringVar(&activityBucket, "activity-bucket", "month", "Period of the activity sparkline (week, month, quarter, year)")
+	rootCmd.Flags().BoolVar(&showSummary, "summary", false, "Append a row with the totals of all contributors")
+	rootCmd.Flags().BoolVar(&showExtended, "extended", false, "Show extended statistics such as average and median commit size")
+	rootCmd.Flags().BoolVar(&wideOutput, "wide", false, "Do not truncate names and emails in the table output")
+	rootCmd.Flags().StringVar(&humanizeMode, "humanize", "", "Format large counts in the table and markdown output (separators, compact)")
+	rootCmd.Flags().Lookup("humanize").NoOptDefVal = "separators"
+	rootCmd.PersistentFlags().StringVar(&colorMode, "color", "auto", "Color the table output (auto, always, never)")
+	rootCmd.Flags().StringVarP(&outputPath, "output", "o", "", "Write the results to a file instead of stdout")
+	rootCmd.Flags().BoolVar(&noPager, "no-pager", false, "Do not pipe long output through $PAGER")
+	rootCmd.Flags().BoolVar(&noHeader, "no-header", false, "Omit the header row from table, csv and tsv output")
+	rootCmd.Flags().StringVar(&fixtureInput, "from-fixture", "", "Report on the commits of a fixture written by export-fixture instead of a repository (- for stdin)")
+	rootCmd.Flags().StringVar(&pathsFrom, "paths-from", "", "Read paths to analyze from a file, one per line (- for stdin)")
+	rootCmd.Flags().StringArrayVar(&excludePatterns, "exclude", nil, "Exclude files matching a glob pattern (repeatable, e.g. 'vendor/**')")
+	rootCmd.Flags().BoolVar(&perPath, "per-path", false, "Show separate results for each path instead of combining them")
+	rootCmd.Flags().StringVar(&workHours, "work-hours", "9-17", "Working hours window in the commit's local time (start-end)")
+	rootCmd.Flags().BoolVar(&onlyWorkHours, "only-work-hours", false, "Only count commits authored within working hours")
+	rootCmd.Flags().BoolVar(&onlyOffHours, "only-off-hours", false, "Only count commits authored outside working hours")
+	rootCmd.MarkFlagsMutuallyExclusive("only-work-hours", "only-off-hours")
+	rootCmd.Flags().StringVar(&templateText, "template", "", "Go template used to render each contributor (e.g. '{{.Name}}: {{.Total}}')")
+	rootCmd.Flags().StringVar(&templateFile, "template-file", "", "File containing a Go template used to render each contributor")
+	rootCmd.MarkFlagsMutuallyExclusive("template", "template-file", "format")
 
 	// Add version flag
 	rootCmd.Flags().BoolP("version", "v", false, "Show version information")
@@ -74,21 +279,80 @@ func init() {
 	rootCmd.Version = version
 }
 
-// isGitRepo checks if the current directory is within a git repository
-func isGitRepo(repoPath string) bool {
-	cmd := exec.Command("git", "-C", repoPath, "rev-parse", "--is-inside-work-tree")
-	err := cmd.Run()
-	return err == nil
+// isGitRepo checks if the current directory is within a git repository.
+// The error is only set when the run was interrupted before git could
+// tell.
+func isGitRepo(repoPath string) (bool, error) {
+	if usesGoGit() {
+		_, err := openGoGit(repoPath)
+		return err == nil, nil
+	}
+	_, err := runGit(nil, "-C", repoPath, "rev-parse", "--is-inside-work-tree")
+	if err != nil && runCtx.Err() != nil {
+		return false, err
+	}
+	return err == nil, nil
 }
 
-// findGitRoot finds the root directory of the git repository
+// findGitRoot finds the root directory of the git repository, which is
+// the git directory itself for a bare repository
 func findGitRoot(repoPath string) (string, error) {
-	cmd := exec.Command("git", "-C", repoPath, "rev-parse", "--show-toplevel")
-	output, err := cmd.Output()
+	if usesGoGit() {
+		return goGitRoot(repoPath)
+	}
+	output, err := runGit(nil, "-C", repoPath, "rev-parse", "--show-toplevel")
 	if err != nil {
-		return "", err
+		if bare, bareErr := isBareRepo(repoPath); bareErr != nil || !bare {
+			return "", err
+		}
+		output, err = runGit(nil, "-C", repoPath, "rev-parse", "--absolute-git-dir")
+		if err != nil {
+			return "", err
+		}
 	}
-	return strings.TrimSpace(string(output)), nil
+	return strings.TrimSpace(output), nil
+}
+
+// isBareRepo reports whether the repository has no work tree, as on the
+// machines hosting repositories. Like with isGitRepo, the error is only
+// set when the run was interrupted.
+func isBareRepo(repoPath string) (bool, error) {
+	if usesGoGit() {
+		return goGitIsBare(repoPath), nil
+	}
+	output, err := runGit(nil, "-C", repoPath, "rev-parse", "--is-bare-repository")
+	if err != nil && runCtx.Err() != nil {
+		return false, err
+	}
+	return err == nil && strings.TrimSpace(output) == "true", nil
+}
+
+// bareRelativePath checks that a path relative to the root of a bare
+// repository exists in the analyzed branch, or HEAD, and returns it in
+// git's form
+func bareRelativePath(path string, repoPath string) (string, error) {
+	if filepath.IsAbs(path) {
+		return "", fmt.Errorf("Error: paths in the bare repository %s must be relative to its root, got %s", repoPath, path)
+	}
+	relPath := filepath.ToSlash(filepath.Clean(path))
+	if relPath == "." {
+		return relPath, nil
+	}
+
+	ref := "HEAD"
+	if branchRef != "" {
+		ref = branchRef
+	}
+	if usesGoGit() {
+		if !goGitHasPath(repoPath, ref, relPath) {
+			return "", fmt.Errorf("Error: Path %s does not exist in %s of %s", path, ref, repoPath)
+		}
+		return relPath, nil
+	}
+	if _, err := runGit(nil, "-C", repoPath, "cat-file", "-e", ref+":"+relPath); err != nil {
+		return "", fmt.Errorf("Error: Path %s does not exist in %s of %s", path, ref, repoPath)
+	}
+	return relPath, nil
 }
 
 // findRepoForPath determines which Git repository a file or directory belongs to
@@ -99,37 +363,55 @@ func findRepoForPath(path string) (string, error) {
 		return "", fmt.Errorf("Error resolving path %s: %v", path, err)
 	}
 
-	// Check if path exists
-	_, err = os.Stat(absPath)
-	if os.IsNotExist(err) {
-		return "", fmt.Errorf("Error: Path %s does not exist", path)
+	// A path that is gone from disk may still be in the history, so the
+	// search starts from the closest directory that exists. Whether the
+	// path exists in the repository is checked by getRelativePath.
+	existing := absPath
+	for {
+		if _, err := os.Stat(existing); !os.IsNotExist(err) {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return "", fmt.Errorf("Error: Path %s does not exist", path)
+		}
+		existing = parent
 	}
 
 	// If path is a file, use its directory
-	fileInfo, err := os.Stat(absPath)
+	fileInfo, err := os.Stat(existing)
 	if err != nil {
 		return "", fmt.Errorf("Error checking file info: %v", err)
 	}
 
-	dirPath := absPath
+	dirPath := existing
 	if !fileInfo.IsDir() {
-		dirPath = filepath.Dir(absPath)
+		dirPath = filepath.Dir(existing)
+	}
+
+	// GIT_DIR names the repository instead of discovery, as in git hooks
+	if os.Getenv("GIT_DIR") != "" {
+		return findGitRoot(dirPath)
 	}
 
-	// Walk up the directory tree until we find a .git directory
+	// Walk up the directory tree until we find a .git directory, or a .git
+	// file of a linked worktree or submodule
+	ceilings := ceilingDirectories()
 	currentDir := dirPath
 	for {
-		// Check if this directory contains a .git directory
-		gitDir := filepath.Join(currentDir, ".git")
-		if _, err := os.Stat(gitDir); err == nil {
+		if _, ok := gitDirOf(currentDir); ok {
 			return currentDir, nil
 		}
 
 		// Move up to parent directory
 		parentDir := filepath.Dir(currentDir)
 
-		// If we've reached the root directory and still haven't found a .git dir
-		if parentDir == currentDir {
+		// If we've reached the root directory, or a ceiling directory git
+		// would not move up into, and still haven't found a .git dir
+		if parentDir == currentDir || slices.Contains(ceilings, parentDir) {
+			if existing != absPath {
+				return "", fmt.Errorf("Error: Path %s does not exist", path)
+			}
 			return "", fmt.Errorf("Could not find a Git repository for path: %s", path)
 		}
 
@@ -137,79 +419,702 @@ func findRepoForPath(path string) (string, error) {
 	}
 }
 
-// getDateFilter returns a git date filter based on the timeRange
-func getDateFilter(timeRange string) string {
-	if timeRange == "" {
-		return ""
+// gitDirOf returns the git directory of a work tree root: its .git
+// directory, or the directory named by its .git file. Linked worktrees and
+// submodules have a .git file with a "gitdir: <path>" line, where a
+// relative path is relative to the work tree root. ok is false when dir has
+// no .git, or its .git file points to a directory that does not exist.
+func gitDirOf(dir string) (gitDir string, ok bool) {
+	gitPath := filepath.Join(dir, ".git")
+	info, err := os.Stat(gitPath)
+	if err != nil {
+		return "", false
+	}
+	if info.IsDir() {
+		return gitPath, true
+	}
+
+	data, err := os.ReadFile(gitPath)
+	if err != nil {
+		return "", false
+	}
+	target, found := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !found {
+		return "", false
+	}
+	target = strings.TrimSpace(target)
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(dir, target)
+	}
+	if info, err := os.Stat(target); err != nil || !info.IsDir() {
+		debugf("Ignoring %s: %s is not a git directory\n", gitPath, target)
+		return "", false
+	}
+	return target, true
+}
+
+// gitDate converts a YYYY-MM-DD date to a timestamp at the start of that
+// day, or at its end when endOfDay is set, so a whole --until day is
+// included. Other values, like "2 weeks ago", are left for git to parse.
+func gitDate(value string, endOfDay bool) string {
+	date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return value
+	}
+	if endOfDay {
+		date = date.AddDate(0, 0, 1).Add(-time.Second)
+	}
+	return date.Format(time.RFC3339)
+}
+
+// commitDateWindow returns the commit dates selected by the time range,
+// --since and --until. A zero time is unbounded. Like git, the last bound
+// given wins. Dates other than YYYY-MM-DD, like "2 weeks ago", are returned
+// as git arguments for the exec backend to parse, and rejected by the
+// in-process backends.
+func commitDateWindow(timeRange string) (since time.Time, until time.Time, gitArgs []string, err error) {
+	if timeRange != "" {
+		now := time.Now()
+		since, until, err = timeRangeWindow(timeRange, now)
+		if err != nil {
+			return
+		}
+		if !until.Before(now) {
+			until = time.Time{}
+		}
+	}
+	if sinceDate != "" {
+		if date, err := time.Parse(time.RFC3339, gitDate(sinceDate, false)); err == nil {
+			since = date
+		} else if backend == "exec" {
+			since, gitArgs = time.Time{}, append(gitArgs, "--since="+sinceDate)
+		} else {
+			return since, until, nil, fmt.Errorf("Error: the %s backend only understands --since dates like YYYY-MM-DD, got %s", backend, sinceDate)
+		}
+	}
+	if untilDate != "" {
+		if date, err := time.Parse(time.RFC3339, gitDate(untilDate, true)); err == nil {
+			// git's --until is inclusive
+			until = date.Add(time.Second)
+		} else if backend == "exec" {
+			until, gitArgs = time.Time{}, append(gitArgs, "--until="+untilDate)
+		} else {
+			return since, until, nil, fmt.Errorf("Error: the %s backend only understands --until dates like YYYY-MM-DD, got %s", backend, untilDate)
+		}
+	}
+	return since, until, gitArgs, nil
+}
+
+// durationPattern matches durations like 90d, 2w, 3m, 1y or 18h
+var durationPattern = regexp.MustCompile(`^(\d+)\s*([a-z]+)$`)
+
+// timeRangeStart returns the start of a time range ending at end. The range
+// is day, week, month, quarter, half-year or year, or a count of one of
+// those units or of hours, such as 90d, 2w, 3m, 2q, 1y or 18h.
+func timeRangeStart(timeRange string, end time.Time) (time.Time, error) {
+	n, unit := 1, timeRange
+	if match := durationPattern.FindStringSubmatch(strings.ToLower(timeRange)); match != nil {
+		n, _ = strconv.Atoi(match[1])
+		unit = match[2]
 	}
 
-	now := time.Now()
-	var since time.Time
+	switch unit {
+	case "h", "hour", "hours":
+		return end.Add(-time.Duration(n) * time.Hour), nil
+	case "d", "day", "days":
+		return end.AddDate(0, 0, -n), nil
+	case "w", "week", "weeks":
+		return end.AddDate(0, 0, -7*n), nil
+	case "m", "month", "months":
+		return end.AddDate(0, -n, 0), nil
+	case "q", "quarter", "quarters":
+		return end.AddDate(0, -3*n, 0), nil
+	case "half-year", "half-years":
+		return end.AddDate(0, -6*n, 0), nil
+	case "y", "year", "years":
+		return end.AddDate(-n, 0, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("Error: invalid time range %q, expected day, week, month, quarter, half-year, year, "+
+		"calendar-<week|month|quarter|half-year|year> or a duration like 90d, 2w, 3m, 1y or 18h", timeRange)
+}
 
-	switch timeRange {
-	case "day":
-		since = now.AddDate(0, 0, -1)
+// timeRangeWindow returns the start and end of a time range. Ranges
+// prefixed with calendar- cover the last complete calendar period before
+// end, e.g. calendar-quarter is the previous quarter from its first to its
+// last day. The other ranges end at end.
+func timeRangeWindow(timeRange string, end time.Time) (time.Time, time.Time, error) {
+	unit, ok := strings.CutPrefix(timeRange, "calendar-")
+	if !ok {
+		since, err := timeRangeStart(timeRange, end)
+		return since, end, err
+	}
+
+	until, ok := calendarStart(end, unit)
+	if !ok {
+		_, err := timeRangeStart(timeRange, end)
+		return time.Time{}, time.Time{}, err
+	}
+	since, _ := calendarStart(until.AddDate(0, 0, -1), unit)
+	return since, until, nil
+}
+
+// calendarStart returns the start of the calendar week (from Monday), month,
+// quarter, half-year or year containing t
+func calendarStart(t time.Time, unit string) (time.Time, bool) {
+	year, month, day := t.Date()
+	switch unit {
 	case "week":
-		since = now.AddDate(0, 0, -7)
+		day -= (int(t.Weekday()) + 6) % 7
 	case "month":
-		since = now.AddDate(0, -1, 0)
+		day = 1
+	case "quarter":
+		month, day = month-(month-1)%3, 1
+	case "half-year":
+		month, day = month-(month-1)%6, 1
 	case "year":
-		since = now.AddDate(-1, 0, 0)
+		month, day = time.January, 1
 	default:
-		fmt.Printf("Invalid time range: %s. Using all history.\n", timeRange)
-		return ""
+		return time.Time{}, false
 	}
-
-	return fmt.Sprintf("--since=%s", since.Format("2006-01-02"))
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location()), true
 }
 
-// runGitWho runs the git analysis for a file or directory
-func runGitWho(path string, timeRange string, repoPath string) {
-	var effectiveRepoPath string
-	var err error
+// runGitWho runs the git analysis for a file or directory. With several
+// repositories the paths are analyzed in each of them and the statistics
+// combined by identity.
+func runGitWho(paths []string, timeRange string, repos []string) {
+	if !isValidFormat(outputFormat) {
+		fmt.Printf("Error: unknown output format %s\n", outputFormat)
+		os.Exit(1)
+	}
+
+	if !isValidSortField(sortField) {
+		fmt.Printf("Error: unknown sort field %s\n", sortField)
+		os.Exit(1)
+	}
+
+	if columnList != "" {
+		if _, err := parseColumns(columnList); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
 
-	// If repo path is explicitly specified, use it
-	if repoPath != "" {
-		effectiveRepoPath = repoPath
+	hours, err := parseHourWindow(workHours)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	excludes, err := newPathMatcher(excludePatterns)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if !isValidBucket(activityBucket) {
+		fmt.Printf("Error: invalid activity bucket %s, expected week, month, quarter or year\n", activityBucket)
+		os.Exit(1)
+	}
+	if activityPeriods < 1 {
+		fmt.Println("Error: --activity-periods must be at least 1")
+		os.Exit(1)
+	}
+
+	if !isValidEnrichMode(enrichMode) {
+		fmt.Printf("Error: invalid enrich mode %s, expected github\n", enrichMode)
+		os.Exit(1)
+	}
+
+	if humanizeMode != "" && humanizeMode != "separators" && humanizeMode != "compact" {
+		fmt.Printf("Error: invalid humanize mode %s, expected separators or compact\n", humanizeMode)
+		os.Exit(1)
+	}
+
+	tmpl, err := loadTemplate()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if perPath && outputFormat == "html" && tmpl == nil {
+		fmt.Println("Error: --per-path cannot be combined with --format html, which writes one page per report")
+		os.Exit(1)
+	}
+
+	// Analyze the paths together, or each one on its own with --per-path.
+	// Only the reports of several paths may read the same history again.
+	var reports []*Report
+	cacheHistory = perPath
+	if perPath {
+		for _, path := range paths {
+			report, err := buildReport([]string{path}, timeRange, repos, hours, excludes)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			reports = append(reports, report)
+		}
 	} else {
-		// Otherwise, automatically detect the repository for the given path
-		effectiveRepoPath, err = findRepoForPath(path)
+		report, err := buildReport(paths, timeRange, repos, hours, excludes)
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(1)
 		}
-		fmt.Printf("Found Git repository: %s\n", effectiveRepoPath)
+		reports = append(reports, report)
 	}
 
-	// Check if it's a valid git repo
-	if !isGitRepo(effectiveRepoPath) {
-		fmt.Printf("Error: %s is not a git repository\n", effectiveRepoPath)
-		os.Exit(1)
+	// Write to the output file if one was given, or through the pager
+	var out io.Writer = os.Stdout
+	var file *os.File
+	var pager *pagerBuffer
+	if outputPath != "" {
+		file, err = os.Create(outputPath)
+		if err != nil {
+			fmt.Printf("Error creating output file: %v\n", err)
+			os.Exit(1)
+		}
+		out = file
+	} else if !noPager && isTerminal(os.Stdout) {
+		pager = &pagerBuffer{terminal: os.Stdout}
+		out = pager
 	}
 
-	// Get relative path from git root
-	relPath, err := getRelativePath(path, effectiveRepoPath)
+	color, err := resolveColor(colorMode, out)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
+	render := formatter(tmpl, newRenderOptions(color))
 
-	// Get git log data
-	output, err := executeGitLog(relPath, timeRange, effectiveRepoPath)
+	// Display results, as one document of all paths with --per-path
+	if perPath {
+		err = renderReports(render, out, reports)
+	} else {
+		err = render.Render(out, reports[0])
+	}
 	if err != nil {
-		fmt.Printf("Error executing git log: %v\n", err)
+		fmt.Printf("Error writing output: %v\n", err)
 		os.Exit(1)
 	}
 
+	if pager != nil {
+		if err := pager.Flush(); err != nil {
+			fmt.Printf("Error running pager: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if file != nil {
+		if err := file.Close(); err != nil {
+			fmt.Printf("Error writing output file: %v\n", err)
+			os.Exit(1)
+		}
+		infof("Wrote results to %s\n", outputPath)
+	}
+}
+
+// infof prints an informational message to stderr so it never mixes with
+// the results on stdout. Nothing is printed with --quiet.
+func infof(format string, args ...interface{}) {
+	if quiet {
+		return
+	}
+	fmt.Fprintf(os.Stderr, format, args...)
+}
+
+// buildReport analyzes the paths and applies the filters, sorting and
+// limits from the command line flags
+func buildReport(paths []string, timeRange string, repos []string, hours HourWindow, excludes *gitwho.PathMatcher) (*Report, error) {
 	// Parse the output and collect contributor statistics
-	contributors := parseGitOutput(output)
+	commits, err := collectRepoCommits(paths, timeRange, repos, excludes)
+	if err != nil {
+		return nil, err
+	}
+	if onlyWorkHours || onlyOffHours {
+		commits = filterByHours(commits, hours, onlyWorkHours)
+	}
+	contributors := gitwho.Aggregate(commits)
+	gitwho.ComputeShares(contributors)
+	contributors = filterByContribution(contributors, minCommits, minLines)
+	sortContributorsBy(contributors, sortField, reverseSort)
 
-	// Display results
-	displayResults(contributors, path, timeRange)
+	report := &Report{
+		Path:         reportPath(paths, repos),
+		TimeRange:    timeRange,
+		Contributors: limitContributors(contributors, topN, showOthers),
+		Summary:      gitwho.Summarize(contributors),
+	}
+	if enrichMode == "github" {
+		// A failed lookup leaves handles out rather than failing the report
+		if err := enrichGitHub(report.Contributors); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+	return report, nil
+}
+
+// sameFile reports whether two paths refer to the same file or directory
+func sameFile(a string, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return os.SameFile(infoA, infoB)
+}
+
+// collectRepoCommits collects the commits that touched the paths in each
+// of the repositories, or in the repository of the paths when none is
+// given, without the changes to files the excludes match. With several
+// repositories the file paths are prefixed with the names repoNames gives
+// the repositories, so files of different repositories are told apart.
+// The excludes are matched before, against the paths in each repository.
+// With --from-fixture the commits of the fixture are collected instead.
+func collectRepoCommits(paths []string, timeRange string, repos []string, excludes *gitwho.PathMatcher) ([]*Commit, error) {
+	if fixtureInput != "" {
+		commits, err := readFixture(fixtureInput)
+		if err != nil {
+			return nil, err
+		}
+		return excludeFiles(commits, excludes), nil
+	}
+	if len(repos) <= 1 {
+		repo := ""
+		if len(repos) == 1 {
+			repo = repos[0]
+		}
+		commits, err := collectCommits(paths, timeRange, repo)
+		if err != nil {
+			return nil, err
+		}
+		return excludeFiles(commits, excludes), nil
+	}
+
+	var commits []*Commit
+	names := repoNames(repos)
+	for i, repo := range repos {
+		repoCommits, err := collectCommits(paths, timeRange, repo)
+		if err != nil {
+			return nil, err
+		}
+		repoCommits = excludeFiles(repoCommits, excludes)
+		commits = append(commits, prefixPaths(repoCommits, names[i])...)
+	}
+	return commits, nil
+}
+
+// prefixPaths prepends dir to the paths of the files the commits changed
+func prefixPaths(commits []*Commit, dir string) []*Commit {
+	for _, commit := range commits {
+		for i := range commit.Files {
+			commit.Files[i].Path = dir + "/" + commit.Files[i].Path
+		}
+	}
+	return commits
+}
+
+// repoName returns the directory name of a repository
+func repoName(repo string) string {
+	return filepath.Base(filepath.Clean(repo))
+}
+
+// repoNames returns short distinct names for repositories: their
+// directory names, extended with parent directories for repositories whose
+// directory names are the same, e.g. team-a/app and team-b/app
+func repoNames(repos []string) []string {
+	parts := make([][]string, len(repos))
+	depths := make([]int, len(repos))
+	for i, repo := range repos {
+		abs, err := filepath.Abs(repo)
+		if err != nil {
+			abs = filepath.Clean(repo)
+		}
+		parts[i] = strings.FieldsFunc(filepath.ToSlash(abs), func(r rune) bool { return r == '/' })
+		depths[i] = 1
+	}
+	name := func(i int) string {
+		return strings.Join(parts[i][max(len(parts[i])-depths[i], 0):], "/")
+	}
+
+	for {
+		byName := make(map[string][]int)
+		for i := range repos {
+			byName[name(i)] = append(byName[name(i)], i)
+		}
+		grown := false
+		for _, same := range byName {
+			if len(same) < 2 {
+				continue
+			}
+			for _, i := range same {
+				if depths[i] < len(parts[i]) {
+					depths[i]++
+					grown = true
+				}
+			}
+		}
+		// The same repository given twice keeps its full path
+		if !grown {
+			break
+		}
+	}
+
+	names := make([]string, len(repos))
+	for i := range repos {
+		names[i] = name(i)
+	}
+	return names
+}
+
+// reportPath describes the analyzed paths, and the repositories when
+// several are combined
+func reportPath(paths []string, repos []string) string {
+	if fixtureInput != "" {
+		return fixtureInput
+	}
+	path := strings.Join(paths, ", ")
+	if len(repos) <= 1 {
+		return path
+	}
+	return path + " in " + strings.Join(repoNames(repos), ", ")
+}
+
+// collectCommits locates the repository for the paths and returns the
+// parsed commits that touched any of them within the time range. All paths
+// must belong to the same repository.
+func collectCommits(paths []string, timeRange string, repoPath string) ([]*Commit, error) {
+	effectiveRepoPath, err := resolveRepo(paths, repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get relative paths from git root
+	var relPaths []string
+	for _, path := range paths {
+		// Patterns are handed to git as pathspecs relative to the root
+		if isPathspecPattern(path) {
+			relPaths = append(relPaths, toPathspec(path))
+			continue
+		}
+
+		relPath, err := getRelativePath(path, effectiveRepoPath)
+		if err != nil {
+			return nil, err
+		}
+		if relPath == ".." || strings.HasPrefix(relPath, "../") {
+			return nil, fmt.Errorf("Error: %s is outside the repository %s", path, effectiveRepoPath)
+		}
+		relPaths = append(relPaths, relPath)
+	}
+
+	if groupBy != "author" && groupBy != "team" {
+		return nil, fmt.Errorf("Error: invalid --group-by %s, expected author or team", groupBy)
+	}
+	if !isValidSimilarity(findRenames) {
+		return nil, fmt.Errorf("Error: invalid --find-renames %s, expected a similarity between 1 and 100", findRenames)
+	}
+	if !isValidSimilarity(findCopies) {
+		return nil, fmt.Errorf("Error: invalid --find-copies %s, expected a similarity between 1 and 100", findCopies)
+	}
+	if !isValidAttribution(attributionMode) {
+		return nil, fmt.Errorf("Error: invalid --use %s, expected author, committer or both", attributionMode)
+	}
+	if squashPRs && attributionMode != "author" {
+		return nil, fmt.Errorf("Error: --squash-prs credits authors and cannot be combined with --use %s", attributionMode)
+	}
+	if !isValidCoAuthorMode(coAuthorMode) {
+		return nil, fmt.Errorf("Error: invalid --co-authors %s, expected none, full or split", coAuthorMode)
+	}
+	if flag := commitSource().Unsupported(); flag != "" {
+		return nil, fmt.Errorf("Error: %s cannot be used with --backend %s", flag, backend)
+	}
+
+	revisions, err := revisionArgs(effectiveRepoPath)
+	if err != nil {
+		return nil, err
+	}
+	if timeRange != "" {
+		if _, _, err := timeRangeWindow(timeRange, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	return collectRevisions(relPaths, revisions, timeRange, effectiveRepoPath)
+}
+
+// collectRevisions returns the parsed commits of the revisions that touched
+// the paths, relative to the repository root, within the time range. With
+// --recurse-submodules the commits of the submodules below the paths are
+// included.
+func collectRevisions(relPaths []string, revisions []string, timeRange string, effectiveRepoPath string) ([]*Commit, error) {
+	if err := checkShallow(effectiveRepoPath); err != nil {
+		return nil, err
+	}
+
+	// Read the history with the library, through the selected backend
+	options, err := logOptions(relPaths, revisions, timeRange, effectiveRepoPath)
+	if err != nil {
+		return nil, err
+	}
+	analyzer, err := gitwho.NewAnalyzer(options...)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := analyzer.Commits(runCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := findGitRoot(effectiveRepoPath)
+	if err != nil {
+		return nil, fmt.Errorf("Error finding git root: %v", err)
+	}
+
+	// Apply the paths the repository declares as ignored
+	ignore, err := loadIgnoreFile(root)
+	if err != nil {
+		return nil, err
+	}
+	commits := excludeFiles(parsed, ignore)
+	if readsTrailers() {
+		if err := resolveTrailers(commits, root); err != nil {
+			return nil, err
+		}
+	}
+	if squashPRs {
+		if commits, err = attributePullRequests(commits, root); err != nil {
+			return nil, err
+		}
+	}
+	commits = creditCoAuthors(attributeCommits(commits, attributionMode), coAuthorMode)
+
+	config, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	commits = applyAliases(normalizeIdentities(commits), config.Aliases)
+	if excludeBots {
+		bots, err := compilePatterns(append(builtinBotPatterns, config.Bots...))
+		if err != nil {
+			return nil, err
+		}
+		commits = excludeAuthors(commits, bots)
+	}
+	included, err := compilePatterns(authorPatterns)
+	if err != nil {
+		return nil, err
+	}
+	excluded, err := compilePatterns(excludeAuthorPatterns)
+	if err != nil {
+		return nil, err
+	}
+	commits = excludeAuthors(includeAuthors(commits, included), excluded)
+	if groupBy == "team" {
+		if len(config.Teams) == 0 {
+			return nil, fmt.Errorf("Error: --group-by team needs teams in the config file")
+		}
+		commits = groupByTeam(commits, config.Teams)
+	}
+
+	if !includeVendored {
+		commits, err = excludeVendored(commits, root)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if recurseSubmodules {
+		return withSubmodules(commits, relPaths, timeRange, root)
+	}
+	return commits, nil
+}
+
+// resolveRepo returns the repository to analyze: repoPath when it is set,
+// otherwise the repository of the first literal path, or of the current
+// directory if there are only patterns
+func resolveRepo(paths []string, repoPath string) (string, error) {
+	effectiveRepoPath := repoPath
+	if effectiveRepoPath == "" {
+		lookupPath := "."
+		if i := slices.IndexFunc(paths, func(p string) bool { return !isPathspecPattern(p) }); i >= 0 {
+			lookupPath = paths[i]
+		}
+		var err error
+		effectiveRepoPath, err = findRepoForPath(lookupPath)
+		if err != nil {
+			return "", err
+		}
+		infof("Found Git repository: %s\n", effectiveRepoPath)
+	}
+
+	// Check if it's a valid git repo
+	if ok, err := isGitRepo(effectiveRepoPath); err != nil {
+		return "", fmt.Errorf("Error: %v", err)
+	} else if !ok {
+		return "", fmt.Errorf("Error: %s is not a git repository", effectiveRepoPath)
+	}
+	return effectiveRepoPath, nil
+}
+
+// isRevisionRange reports whether arg is a revision range such as
+// v1.2.0..HEAD or main...topic rather than a path
+func isRevisionRange(arg string) bool {
+	if !strings.Contains(arg, "..") || strings.HasPrefix(arg, "../") || arg == ".." {
+		return false
+	}
+	_, err := os.Stat(arg)
+	return os.IsNotExist(err)
+}
+
+// readPathList reads paths from a file, or from stdin when name is "-".
+// Each non-empty line is one path.
+func readPathList(name string) ([]string, error) {
+	var data []byte
+	var err error
+	if name == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error reading paths: %v", err)
+	}
+
+	var paths []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			paths = append(paths, line)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("Error: no paths found in %s", name)
+	}
+	return paths, nil
+}
+
+// isPathspecPattern reports whether arg is a git pathspec with magic (such
+// as ":!vendor") or a glob pattern rather than a literal path
+func isPathspecPattern(arg string) bool {
+	return strings.HasPrefix(arg, ":") || strings.ContainsAny(arg, "*?[")
+}
+
+// toPathspec converts a pattern argument to a git pathspec. Globs using
+// "**" get the glob magic so "**/" matches any number of directories like
+// in .gitignore; other patterns are passed through unchanged.
+func toPathspec(pattern string) string {
+	if !strings.HasPrefix(pattern, ":") && strings.Contains(pattern, "**") {
+		return ":(glob)" + pattern
+	}
+	return pattern
 }
 
 // getRelativePath gets the relative path from git root for the given path
 func getRelativePath(path string, repoPath string) (string, error) {
+	// A bare repository has no files on disk to resolve paths against
+	if bare, err := isBareRepo(repoPath); err != nil {
+		return "", fmt.Errorf("Error: %v", err)
+	} else if bare {
+		return bareRelativePath(path, repoPath)
+	}
+
 	gitRoot, err := findGitRoot(repoPath)
 	if err != nil {
 		return "", fmt.Errorf("Error finding git root: %v", err)
@@ -235,160 +1140,207 @@ func getRelativePath(path string, repoPath string) (string, error) {
 		return "", fmt.Errorf("Error resolving path %s: %v", path, err)
 	}
 
-	// Check if path exists
-	_, err = os.Stat(absPath)
-	if os.IsNotExist(err) {
-		return "", fmt.Errorf("Error: Path %s does not exist", path)
-	}
-
 	// Get relative path from git root
 	relPath, err := filepath.Rel(gitRoot, absPath)
 	if err != nil {
 		return "", fmt.Errorf("Error getting relative path from git root: %v", err)
 	}
 
+	// Check if path exists, on disk or, for deleted files, in the history
+	_, err = os.Stat(absPath)
+	if os.IsNotExist(err) && !pathInHistory(gitRoot, relPath) {
+		return "", fmt.Errorf("Error: Path %s does not exist", path)
+	}
+
 	return relPath, nil
 }
 
-// executeGitLog runs the git log command and returns its output
-func executeGitLog(relPath string, timeRange string, repoPath string) (string, error) {
-	// Prepare git log command
-	dateFilter := getDateFilter(timeRange)
-	args := []string{
-		"-C", repoPath,
-		"log",
-		"--format=%an|%ae",
-		"--numstat",
+// pathInHistory reports whether a commit of any branch or tag touched a
+// path relative to the repository root, or a file below it
+func pathInHistory(root string, relPath string) bool {
+	relPath = filepath.ToSlash(relPath)
+	if relPath == ".." || strings.HasPrefix(relPath, "../") {
+		return false
 	}
-
-	if dateFilter != "" {
-		args = append(args, dateFilter)
+	if usesGoGit() {
+		return goGitPathInHistory(root, relPath)
 	}
+	output, err := runGit(nil, "-C", root, "log", "-1", "--all", "--format=%H", "--", ":(top,literal)"+relPath)
+	return err == nil && strings.TrimSpace(output) != ""
+}
 
-	// Add path argument
-	args = append(args, "--", relPath)
-
-	// Execute git log command
-	cmd := exec.Command("git", args...)
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = os.Stderr
-
-	err := cmd.Run()
+// logOptions returns the options of the analyzer reading the history of
+// the revisions, or HEAD when there are none, that touched the paths
+// within the time range, as the flags shape it. The paths are relative to
+// the root of the repository at repoPath.
+func logOptions(relPaths []string, revisions []string, timeRange string, repoPath string) ([]gitwho.Option, error) {
+	since, until, dateArgs, err := commitDateWindow(timeRange)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-
-	return out.String(), nil
+	options := []gitwho.Option{
+		gitwho.WithBackend(commitSource()),
+		gitwho.WithRepo(repoPath),
+		gitwho.WithPaths(relPaths...),
+		gitwho.WithRevisions(revisions...),
+		gitwho.WithTimeRange(since, until),
+		gitwho.WithGitArgs(append(dateArgs, extraGitArgs...)...),
+	}
+	if noMerges {
+		options = append(options, gitwho.WithoutMerges())
+	}
+	if firstParent {
+		options = append(options, gitwho.WithFirstParent())
+	}
+	if readsTrailers() {
+		options = append(options, gitwho.WithTrailers())
+	}
+	if showsSigned() {
+		// Signatures are only verified when the signed column is shown
+		options = append(options, gitwho.WithSignatures())
+	}
+	if ignoreWhitespace {
+		options = append(options, gitwho.WithIgnoreWhitespace())
+	}
+	if findRenames != "" {
+		similarity, _ := strconv.Atoi(findRenames)
+		options = append(options, gitwho.WithRenames(similarity))
+	}
+	if findCopies != "" {
+		similarity, _ := strconv.Atoi(findCopies)
+		options = append(options, gitwho.WithCopies(similarity))
+	}
+	if followRenames && len(relPaths) == 1 && isRegularFile(filepath.Join(repoPath, relPaths[0])) {
+		// git can only follow the history of a single file
+		options = append(options, gitwho.WithFollow())
+	}
+	return options, nil
 }
 
-// parseGitOutput parses git log output to extract contributor statistics
-func parseGitOutput(output string) []*Contributor {
-	stats := make(map[string]*Contributor)
-	lines := strings.Split(output, "\n")
+// isValidSimilarity reports whether value is empty or a rename/copy
+// similarity percentage between 1 and 100
+func isValidSimilarity(value string) bool {
+	if value == "" {
+		return true
+	}
+	n, err := strconv.Atoi(value)
+	return err == nil && n >= 1 && n <= 100
+}
 
-	currentUser := ""
-	currentEmail := ""
+// isRegularFile reports whether path exists and is a regular file
+func isRegularFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
 
-	for _, line := range lines {
-		if strings.Contains(line, "|") {
-			// This is a username|email line
-			parts := strings.Split(line, "|")
-			if len(parts) == 2 {
-				currentUser = parts[0]
-				currentEmail = parts[1]
+// splitCommits splits the text file changes of the commits into groups by
+// the key of their path. Each group holds partial copies of the commits
+// with only the changes of that group.
+func splitCommits(commits []*Commit, key func(path string) string) map[string][]*Commit {
+	groups := make(map[string][]*Commit)
+	for _, commit := range commits {
+		parts := make(map[string]*Commit)
+		for _, change := range commit.Files {
+			if change.Binary {
+				continue
+			}
+			group := key(change.Path)
+			part, exists := parts[group]
+			if !exists {
+				part = &Commit{Hash: commit.Hash, Name: commit.Name, Email: commit.Email, Date: commit.Date, Signed: commit.Signed}
+				parts[group] = part
+				groups[group] = append(groups[group], part)
 			}
-		} else if len(line) > 0 && currentUser != "" && !strings.HasPrefix(line, "commit") {
-			processStatLine(line, currentUser, currentEmail, stats)
+			part.Files = append(part.Files, change)
 		}
 	}
-
-	// Convert map to slice and sort
-	return sortContributors(stats)
+	return groups
 }
 
-// processStatLine processes a single line of git statistics
-func processStatLine(line, currentUser, currentEmail string, stats map[string]*Contributor) {
-	parts := strings.Fields(line)
-	if len(parts) < 3 {
-		return
+// displayResults shows the contributor statistics
+func displayResults(w io.Writer, report *Report, options renderOptions) error {
+	ew := &errWriter{w: w}
+	if len(report.Contributors) == 0 {
+		fmt.Fprintln(ew, "No changes found for the specified path and time range.")
+		return ew.err
 	}
 
-	// Skip binary files
-	if parts[0] == "-" && parts[1] == "-" {
-		return
-	}
+	records := tableRecords(report, options)
+	columns := layoutColumns(options.Columns, records, options.Wide, terminalWidth(w), options.Humanize)
 
-	// Parse additions and deletions
-	var additions, deletions int
-	fmt.Sscanf(parts[0], "%d", &additions)
-	fmt.Sscanf(parts[1], "%d", &deletions)
+	if !options.NoHeader {
+		fmt.Fprintf(ew, "\nContributor Statistics for %s", report.Path)
+		if report.TimeRange != "" {
+			fmt.Fprintf(ew, " (last %s)", report.TimeRange)
+		}
+		for _, qualifier := range options.Qualifiers {
+			fmt.Fprintf(ew, " (%s)", qualifier)
+		}
+		fmt.Fprint(ew, "\n\n")
 
-	key := fmt.Sprintf("%s|%s", currentUser, currentEmail)
-	contributor, exists := stats[key]
-	if !exists {
-		contributor = &Contributor{
-			Name:  currentUser,
-			Email: currentEmail,
+		headers := make([]string, len(columns))
+		for i, col := range columns {
+			headers[i] = colorize(padCell(col.Header, col), ansiBold, options.Color)
 		}
-		stats[key] = contributor
+		fmt.Fprintln(ew, strings.Join(headers, " "))
+		fmt.Fprintln(ew, strings.Repeat("-", tableWidth(columns)))
 	}
 
-	contributor.Commits++
-	contributor.Additions += additions
-	contributor.Deletions += deletions
-}
-
-// sortContributors sorts contributors by total changes (additions + deletions)
-func sortContributors(stats map[string]*Contributor) []*Contributor {
-	contributors := make([]*Contributor, 0, len(stats))
-	for _, contributor := range stats {
-		contributors = append(contributors, contributor)
+	for i, record := range buildReportDocument(report, options).Contributors {
+		// Highlight the top contributor
+		highlight := ""
+		if i == 0 {
+			highlight = ansiBold + ansiYellow
+		}
+		printTableRow(ew, record, columns, highlight, options)
 	}
 
-	// Sort contributors by total changes
-	sort.Slice(contributors, func(i, j int) bool {
-		totalI := contributors[i].Additions + contributors[i].Deletions
-		totalJ := contributors[j].Additions + contributors[j].Deletions
-		return totalI > totalJ
-	})
-
-	return contributors
+	if options.Totals {
+		fmt.Fprintln(ew, strings.Repeat("-", tableWidth(columns)))
+		printTableRow(ew, totalsRecord(report, options), columns, ansiBold, options)
+		fmt.Fprintf(ew, "\nConcentration: Gini %.2f, HHI %.2f\n", report.Summary.Gini, report.Summary.HHI)
+	}
+	return ew.err
 }
 
-// displayResults shows the contributor statistics
-func displayResults(contributors []*Contributor, path string, timeRange string) {
-	if len(contributors) == 0 {
-		fmt.Println("No changes found for the specified path and time range.")
-		return
+// printTableRow prints one row of the table output. Text columns use the
+// highlight color, numeric columns their own color if they have one.
+func printTableRow(w io.Writer, record contributorRecord, columns []column, highlight string, options renderOptions) {
+	cells := make([]string, len(columns))
+	for i, col := range columns {
+		cell := padCell(truncateString(col.text(record, options.Humanize), col.Width), col)
+		if col.Color != "" {
+			cells[i] = colorize(cell, col.Color, options.Color)
+		} else if !col.Numeric {
+			cells[i] = colorize(cell, highlight, options.Color)
+		} else {
+			cells[i] = cell
+		}
 	}
+	fmt.Fprintln(w, strings.Join(cells, " "))
+}
 
-	fmt.Printf("\nContributor Statistics for %s", path)
-	if timeRange != "" {
-		fmt.Printf(" (last %s)", timeRange)
+// padCell pads a table cell to the column's display width, aligning
+// numbers right
+func padCell(value string, col column) string {
+	if col.Numeric {
+		return runewidth.FillLeft(value, col.Width)
 	}
-	fmt.Println("\n")
-
-	fmt.Printf("%-30s %-30s %10s %10s %10s %10s\n",
-		"NAME", "EMAIL", "COMMITS", "ADDED", "DELETED", "TOTAL")
-	fmt.Println(strings.Repeat("-", 100))
+	return runewidth.FillRight(value, col.Width)
+}
 
-	for _, contributor := range contributors {
-		total := contributor.Additions + contributor.Deletions
-		fmt.Printf("%-30s %-30s %10d %10d %10d %10d\n",
-			truncateString(contributor.Name, 30),
-			truncateString(contributor.Email, 30),
-			contributor.Commits,
-			contributor.Additions,
-			contributor.Deletions,
-			total)
+// tableWidth returns the width of a table row for the given columns
+func tableWidth(columns []column) int {
+	width := len(columns) - 1
+	for _, col := range columns {
+		width += col.Width
 	}
+	return width
 }
 
-// truncateString truncates a string to the given length if needed
+// truncateString truncates a string to the given display width if needed.
+// Wide characters such as CJK count as two columns and multi-byte
+// characters are never split.
 func truncateString(s string, length int) string {
-	if len(s) <= length {
-		return s
-	}
-	return s[:length-3] + "..."
+	return runewidth.Truncate(s, length, "...")
 }
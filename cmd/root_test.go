@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestGetRelativePathDeleted(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit("alice", map[string]string{"kept.txt": "kept\n", "docs/gone.md": "gone\n"})
+	repo.commit("bob", map[string]string{"docs/gone.md": ""})
+
+	for _, name := range []string{"exec", "gogit"} {
+		t.Run(name, func(t *testing.T) {
+			defer func(previous string) { backend = previous }(backend)
+			backend = name
+
+			for _, path := range []string{"kept.txt", "docs/gone.md", "docs"} {
+				relPath, err := getRelativePath(filepath.Join(repo.dir, path), repo.dir)
+				if err != nil {
+					t.Errorf("getRelativePath(%s): %v", path, err)
+				} else if relPath != path {
+					t.Errorf("getRelativePath(%s) = %s", path, relPath)
+				}
+			}
+			if _, err := getRelativePath(filepath.Join(repo.dir, "never.txt"), repo.dir); err == nil {
+				t.Errorf("getRelativePath(never.txt) succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestRepoNames(t *testing.T) {
+	tests := []struct {
+		repos []string
+		want  []string
+	}{
+		{[]string{"/src/billing", "/src/invoicing"}, []string{"billing", "invoicing"}},
+		{[]string{"/team-a/app", "/team-b/app", "/team-b/api"}, []string{"team-a/app", "team-b/app", "api"}},
+		{[]string{"/x/org/app", "/y/org/app"}, []string{"x/org/app", "y/org/app"}},
+	}
+	for _, tt := range tests {
+		if got := repoNames(tt.repos); !slices.Equal(got, tt.want) {
+			t.Errorf("repoNames(%v) = %v, want %v", tt.repos, got, tt.want)
+		}
+	}
+}
+
+func TestCollectRepoCommitsExcludes(t *testing.T) {
+	api := newTestRepo(t)
+	api.commit("alice", map[string]string{"main.go": "a\n", "vendor/lib/lib.go": "l\n"})
+	web := newTestRepo(t)
+	web.commit("bob", map[string]string{"index.js": "i\n", "vendor/dep.js": "d\n"})
+	excludes, err := newPathMatcher([]string{"vendor/**"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	commits, err := collectRepoCommits(nil, "", []string{api.dir, web.dir}, excludes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, commit := range commits {
+		for _, change := range commit.Files {
+			got = append(got, change.Path)
+		}
+	}
+	slices.Sort(got)
+	// The patterns match the paths in each repository, not the prefixed ones
+	want := []string{repoName(api.dir) + "/main.go", repoName(web.dir) + "/index.js"}
+	slices.Sort(want)
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestResolveRepoInterrupted(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	defer func(previous context.Context) { runCtx = previous }(runCtx)
+	runCtx = ctx
+
+	_, err := resolveRepo(nil, repo.dir)
+	if err == nil || err.Error() != "Error: interrupted" {
+		t.Errorf("resolveRepo with a cancelled run = %v, want the interruption", err)
+	}
+}
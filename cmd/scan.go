@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/PerArneng/gitwho/pkg/gitwho"
+	"github.com/spf13/cobra"
+)
+
+var scanFormat string
+var scanDepth int
+var scanTop int
+
+// scanCmd represents the scan command
+var scanCmd = &cobra.Command{
+	Use:   "scan [directory]",
+	Short: "Analyze every git repository under a directory",
+	Long: `Scan finds the git repositories under a directory, such as a checkout of
+all of a company's repositories, and analyzes each of them. It prints the
+totals of each repository, followed by the contributors of all of them
+combined and the number of repositories each worked on.
+
+Hidden directories are skipped, and repositories nested inside another
+repository are not searched for. Repositories that cannot be analyzed,
+such as ones without commits, are skipped with a warning.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := "."
+		if len(args) == 1 {
+			dir = args[0]
+		}
+		if !isValidTableFormat(scanFormat) {
+			fmt.Printf("Error: Invalid format %s. Supported formats: table, csv, json\n", scanFormat)
+			os.Exit(1)
+		}
+
+		repos, err := findRepositories(dir, scanDepth)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if len(repos) == 0 {
+			fmt.Printf("Error: no git repositories found in %s\n", dir)
+			os.Exit(1)
+		}
+		infof("Found %s in %s\n", pluralize(len(repos), "git repo"), dir)
+
+		result := scanRepositories(dir, repos, lastTimeRange)
+		if scanTop > 0 && len(result.Contributors) > scanTop {
+			result.Contributors = result.Contributors[:scanTop]
+		}
+		if err := displayScan(os.Stdout, scanFormat, result); err != nil {
+			fmt.Printf("Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	scanCmd.Flags().StringVar(&scanFormat, "format", "table", "Output format (table, csv, json)")
+	scanCmd.Flags().IntVar(&scanDepth, "depth", 0, "Number of directory levels to search for repositories (0 for no limit)")
+	scanCmd.Flags().IntVar(&scanTop, "top", 0, "Only show the top N contributors of all repositories (0 shows all)")
+	rootCmd.AddCommand(scanCmd)
+}
+
+// scanRepo holds the totals of one scanned repository
+type scanRepo struct {
+	Path         string `json:"path"`
+	Contributors int    `json:"contributors"`
+	Commits      int    `json:"commits"`
+	Additions    int    `json:"additions"`
+	Deletions    int    `json:"deletions"`
+	Total        int    `json:"total"`
+	Top          string `json:"topContributor"`
+}
+
+// scanContributor holds a contributor's totals over all scanned
+// repositories
+type scanContributor struct {
+	Name        string  `json:"name"`
+	Email       string  `json:"email"`
+	Repos       int     `json:"repositories"`
+	Commits     int     `json:"commits"`
+	Additions   int     `json:"additions"`
+	Deletions   int     `json:"deletions"`
+	Total       int     `json:"total"`
+	ChangeShare float64 `json:"changeShare"`
+}
+
+// scanResult is the consolidated report of a scan
+type scanResult struct {
+	Repositories []scanRepo        `json:"repositories"`
+	Contributors []scanContributor `json:"contributors"`
+}
+
+// findRepositories returns the git repositories below dir, down to
+// maxDepth directory levels when it is positive
+func findRepositories(dir string, maxDepth int) ([]string, error) {
+	var repos []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			// Unreadable directories are not worth failing the scan for
+			debugf("Skipping %s: %v\n", path, err)
+			return nil
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if path != dir && strings.HasPrefix(entry.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if _, ok := gitDirOf(path); ok {
+			repos = append(repos, path)
+			return filepath.SkipDir
+		}
+		if rel, _ := filepath.Rel(dir, path); maxDepth > 0 && rel != "." && strings.Count(rel, string(filepath.Separator)) >= maxDepth-1 {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error searching %s: %v", dir, err)
+	}
+	return repos, nil
+}
+
+// scanRepositories analyzes each repository and combines their
+// contributors. File paths are prefixed with the repository's path below
+// dir so files of different repositories are told apart.
+func scanRepositories(dir string, repos []string, timeRange string) scanResult {
+	result := scanResult{Repositories: []scanRepo{}, Contributors: []scanContributor{}}
+	var all []*Commit
+	repoCounts := make(map[string]int)
+	for _, repo := range repos {
+		name, err := filepath.Rel(dir, repo)
+		if err != nil {
+			name = repo
+		}
+		if name == "." {
+			// The directory itself is a repository
+			abs, _ := filepath.Abs(repo)
+			name = repoName(abs)
+		}
+		name = filepath.ToSlash(name)
+
+		if checkRevision(repo, "HEAD") != nil {
+			infof("Skipping %s: no commits\n", name)
+			continue
+		}
+		commits, err := collectCommits([]string{repo}, timeRange, repo)
+		if err != nil {
+			infof("Skipping %s: %v\n", name, err)
+			continue
+		}
+		contributors := gitwho.Aggregate(commits)
+		summary := gitwho.Summarize(contributors)
+		record := scanRepo{
+			Path:         name,
+			Contributors: summary.Contributors,
+			Commits:      summary.Commits,
+			Additions:    summary.Additions,
+			Deletions:    summary.Deletions,
+			Total:        summary.Additions + summary.Deletions,
+		}
+		if len(contributors) > 0 {
+			record.Top = contributors[0].Name
+		}
+		result.Repositories = append(result.Repositories, record)
+
+		for _, contributor := range contributors {
+			repoCounts[contributor.Name+"|"+contributor.Email]++
+		}
+		all = append(all, prefixPaths(commits, name)...)
+	}
+
+	contributors := gitwho.Aggregate(all)
+	gitwho.ComputeShares(contributors)
+	for _, contributor := range contributors {
+		result.Contributors = append(result.Contributors, scanContributor{
+			Name:        contributor.Name,
+			Email:       contributor.Email,
+			Repos:       repoCounts[contributor.Name+"|"+contributor.Email],
+			Commits:     contributor.Commits,
+			Additions:   contributor.Additions,
+			Deletions:   contributor.Deletions,
+			Total:       contributor.Additions + contributor.Deletions,
+			ChangeShare: contributor.ChangeShare,
+		})
+	}
+	return result
+}
+
+// displayScan writes the repositories and the combined contributors. The
+// table and csv formats print them as two tables, separated by an empty
+// line.
+func displayScan(w io.Writer, format string, result scanResult) error {
+	repos, contributors := scanRepoTable(result.Repositories), scanContributorTable(result.Contributors)
+	switch format {
+	case "json":
+		return displayTable(w, format, repos, result)
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write(repos.Headers)
+		cw.WriteAll(repos.Rows)
+		fmt.Fprintln(w)
+		cw.Write(contributors.Headers)
+		cw.WriteAll(contributors.Rows)
+		return cw.Error()
+	}
+
+	fmt.Fprintf(w, "Repositories\n\n")
+	if err := displayTable(w, format, repos, result); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\nContributors\n\n")
+	return displayTable(w, format, contributors, result)
+}
+
+// scanRepoTable lays out the totals of each repository
+func scanRepoTable(repos []scanRepo) textTable {
+	table := textTable{
+		Headers: []string{"REPOSITORY", "CONTRIBUTORS", "COMMITS", "ADDED", "DELETED", "TOTAL", "TOP"},
+		Numeric: []bool{false, true, true, true, true, true, false},
+	}
+	for _, repo := range repos {
+		table.Rows = append(table.Rows, []string{
+			repo.Path,
+			strconv.Itoa(repo.Contributors),
+			strconv.Itoa(repo.Commits),
+			strconv.Itoa(repo.Additions),
+			strconv.Itoa(repo.Deletions),
+			strconv.Itoa(repo.Total),
+			repo.Top,
+		})
+	}
+	return table
+}
+
+// scanContributorTable lays out the contributors of all repositories
+func scanContributorTable(contributors []scanContributor) textTable {
+	table := textTable{
+		Headers: []string{"NAME", "EMAIL", "REPOS", "COMMITS", "ADDED", "DELETED", "TOTAL", "CHANGES%"},
+		Numeric: []bool{false, false, true, true, true, true, true, true},
+	}
+	for _, contributor := range contributors {
+		table.Rows = append(table.Rows, []string{
+			contributor.Name,
+			contributor.Email,
+			strconv.Itoa(contributor.Repos),
+			strconv.Itoa(contributor.Commits),
+			strconv.Itoa(contributor.Additions),
+			strconv.Itoa(contributor.Deletions),
+			strconv.Itoa(contributor.Total),
+			formatPercent(contributor.ChangeShare),
+		})
+	}
+	return table
+}
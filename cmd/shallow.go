@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var fetchFullHistory bool
+
+// checkShallow warns that the statistics of a shallow clone, as made by
+// most CI systems, miss the commits before its cut-off. With
+// --fetch-full-history the missing history is fetched instead.
+func checkShallow(repo string) error {
+	if !isShallowRepo(repo) {
+		return nil
+	}
+
+	if !fetchFullHistory {
+		infof("Warning: %s is a shallow clone, so commits before %s are missing from the statistics. Pass --fetch-full-history to fetch them.\n", repo, shallowCutoff(repo))
+		return nil
+	}
+
+	var stderr io.Writer = os.Stderr
+	if quiet {
+		stderr = nil
+	}
+	infof("Fetching the full history of %s\n", repo)
+	origin, _ := runGit(nil, "-C", repo, "remote", "get-url", "origin")
+	if _, err := runGitEnv(credentialEnv(strings.TrimSpace(origin)), "", stderr, "-C", repo, "fetch", "--quiet", "--unshallow"); err != nil {
+		return fmt.Errorf("Error fetching the full history of %s: %v", repo, err)
+	}
+	return nil
+}
+
+// isShallowRepo reports whether the repository is a shallow clone
+func isShallowRepo(repo string) bool {
+	if usesGoGit() {
+		return len(goGitShallow(repo)) > 0
+	}
+	output, err := runGit(nil, "-C", repo, "rev-parse", "--is-shallow-repository")
+	return err == nil && strings.TrimSpace(output) == "true"
+}
+
+// shallowCutoff returns the date of the newest commit whose parents were
+// left out of a shallow clone. History before it may be incomplete.
+func shallowCutoff(repo string) string {
+	if usesGoGit() {
+		return goGitShallowCutoff(repo)
+	}
+	// The boundary commits of a shallow clone look like root commits
+	output, err := runGit(nil, "-C", repo, "log", "--max-parents=0", "--format=%as", "HEAD")
+	if err != nil {
+		return "its cut-off"
+	}
+	date, _, _ := strings.Cut(output, "\n")
+	if date == "" {
+		return "its cut-off"
+	}
+	return date
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/PerArneng/gitwho/pkg/gitwho"
+)
+
+var showSigned bool
+
+// signedColumn shows the percentage of a contributor's commits that carry
+// a GPG or SSH signature
+var signedColumn = column{
+	Name: "signed", Header: "SIGNED%", Title: "Signed %", Width: 8, Numeric: true,
+	Value: func(r contributorRecord) string {
+		if r.SignedShare == nil {
+			return "-"
+		}
+		return formatPercent(*r.SignedShare)
+	},
+}
+
+// showsSigned reports whether the signed column is rendered, so git has to
+// check the commit signatures
+func showsSigned() bool {
+	return slices.ContainsFunc(activeColumns(), func(col column) bool { return col.Name == "signed" })
+}
+
+// signedShare returns the percentage of signed commits
+func signedShare(signed int, commits int) *float64 {
+	share := 0.0
+	if commits > 0 {
+		share = float64(signed) * 100 / float64(commits)
+	}
+	return &share
+}
+
+// verifySignatures has git verify the signatures of the commits marked
+// Signed, which the in-process backends only found to be there. Signed is
+// kept on the commits whose %G? status counts as signed, so every backend
+// agrees with the exec backend about bad and unverifiable signatures.
+func verifySignatures(ctx context.Context, repo string, commits []*Commit) error {
+	var hashes strings.Builder
+	for _, commit := range commits {
+		if commit.Signed {
+			hashes.WriteString(commit.Hash + "\n")
+		}
+	}
+	if hashes.Len() == 0 {
+		return nil
+	}
+	if repo == "" {
+		repo = "."
+	}
+	output, err := runGitContext(ctx, nil, hashes.String(), nil, "-C", repo, "log", "--no-walk=unsorted", "--stdin", "--format=%H %G?")
+	if err != nil {
+		return fmt.Errorf("verifying signatures: %v", err)
+	}
+	signed := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		if hash, status, ok := strings.Cut(line, " "); ok && gitwho.IsSigned(status) {
+			signed[hash] = true
+		}
+	}
+	for _, commit := range commits {
+		commit.Signed = commit.Signed && signed[commit.Hash]
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// sortFields maps each --sort field to a comparison in its natural order:
+// numeric fields put the largest values first, text fields sort A to Z
+var sortFields = map[string]func(a, b *Contributor) int{
+	"commits": func(a, b *Contributor) int {
+		return cmp.Compare(b.Commits, a.Commits)
+	},
+	"additions": func(a, b *Contributor) int {
+		return cmp.Compare(b.Additions, a.Additions)
+	},
+	"deletions": func(a, b *Contributor) int {
+		return cmp.Compare(b.Deletions, a.Deletions)
+	},
+	"total": func(a, b *Contributor) int {
+		return cmp.Compare(b.Additions+b.Deletions, a.Additions+a.Deletions)
+	},
+	"name": func(a, b *Contributor) int {
+		return cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	},
+	"email": func(a, b *Contributor) int {
+		return cmp.Compare(strings.ToLower(a.Email), strings.ToLower(b.Email))
+	},
+}
+
+// isValidSortField reports whether field is a supported --sort value
+func isValidSortField(field string) bool {
+	_, ok := sortFields[field]
+	return ok
+}
+
+// sortContributorsBy sorts contributors by the given field. Ties are broken
+// by name and email so the output is stable between runs. When reverse is
+// set the whole order is flipped.
+func sortContributorsBy(contributors []*Contributor, field string, reverse bool) {
+	compare := sortFields[field]
+	slices.SortFunc(contributors, func(a, b *Contributor) int {
+		c := cmp.Or(
+			compare(a, b),
+			cmp.Compare(a.Name, b.Name),
+			cmp.Compare(a.Email, b.Email),
+		)
+		if reverse {
+			return -c
+		}
+		return c
+	})
+}
+
+// limitContributors keeps the first top contributors. When others is set,
+// the remaining contributors are merged into a single trailing row.
+func limitContributors(contributors []*Contributor, top int, others bool) []*Contributor {
+	if top <= 0 || len(contributors) <= top {
+		return contributors
+	}
+
+	limited := contributors[:top:top]
+	if !others {
+		return limited
+	}
+
+	rest := contributors[top:]
+	merged := &Contributor{
+		Name:        fmt.Sprintf("(%d others)", len(rest)),
+		Others:      true,
+		Files:       make(map[string]int),
+		BinaryFiles: make(map[string]int),
+		Days:        make(map[string]bool),
+	}
+	for _, contributor := range rest {
+		merged.Commits += contributor.Commits
+		merged.SignedCommits += contributor.SignedCommits
+		merged.Additions += contributor.Additions
+		merged.Deletions += contributor.Deletions
+		merged.CommitSizes = append(merged.CommitSizes, contributor.CommitSizes...)
+		merged.CommitDates = append(merged.CommitDates, contributor.CommitDates...)
+		merged.CommitHashes = append(merged.CommitHashes, contributor.CommitHashes...)
+		merged.SignedHashes = append(merged.SignedHashes, contributor.SignedHashes...)
+		merged.RecordCommitDate(contributor.FirstCommit)
+		merged.RecordCommitDate(contributor.LastCommit)
+		for day := range contributor.Days {
+			merged.Days[day] = true
+		}
+		merged.CommitShare += contributor.CommitShare
+		merged.ChangeShare += contributor.ChangeShare
+		for file, changes := range contributor.Files {
+			merged.Files[file] += changes
+		}
+		for file, changes := range contributor.BinaryFiles {
+			merged.BinaryFiles[file] += changes
+		}
+	}
+
+	return append(limited, merged)
+}
+
+// isOthersRow reports whether contributor is the row limitContributors
+// merges the contributors after the top ones into, rather than a person
+func isOthersRow(contributor *Contributor) bool {
+	return contributor.Others
+}
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PerArneng/gitwho/pkg/gitwho"
+)
+
+// CommitSource is the gitwho.Backend the analysis reads the history with.
+// Each backend selectable with --backend is one, and the rest of the
+// analysis does not depend on which of them produced the commits.
+type CommitSource interface {
+	// Commits returns the commits the query selects. Reading stops with
+	// an error when ctx is cancelled.
+	gitwho.Backend
+
+	// Unsupported returns the first flag given that the source cannot
+	// honor, or "" when it can run the analysis
+	Unsupported() string
+}
+
+var backend string
+
+// commitSources create the sources of the backends: exec runs the git
+// binary, gogit reads repositories in-process with go-git. Builds with the
+// libgit2 tag add libgit2.
+var commitSources = map[string]func() CommitSource{
+	"exec":  func() CommitSource { return execSource{} },
+	"gogit": func() CommitSource { return goGitSource{} },
+}
+
+// backendNames returns the names of the backends, sorted
+func backendNames() []string {
+	return slices.Sorted(maps.Keys(commitSources))
+}
+
+// isValidBackend reports whether name is a supported --backend
+func isValidBackend(name string) bool {
+	_, ok := commitSources[name]
+	return ok
+}
+
+// cacheHistory is set for runs that may read the same history more than
+// once, like --per-path. Other runs stream the history straight from the
+// backend instead of keeping it in memory.
+var cacheHistory bool
+
+// sources are the caching sources of the backends used during the run
+var sources = make(map[string]CommitSource)
+
+// commitSource returns the source of the selected backend. With
+// cacheHistory it caches the history it read, so analyses that read the
+// same history again get it without walking it twice.
+func commitSource() CommitSource {
+	if !cacheHistory {
+		return commitSources[backend]()
+	}
+	if source, ok := sources[backend]; ok {
+		return source
+	}
+	source := newCachedSource(commitSources[backend]())
+	sources[backend] = source
+	return source
+}
+
+// execSource runs git log with the library's GitBackend
+type execSource struct{}
+
+func (s execSource) Commits(ctx context.Context, query gitwho.Query) ([]*Commit, error) {
+	var commits []*Commit
+	for commit, err := range s.Stream(ctx, query) {
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+// Stream yields the commits as git log writes them, and draws the
+// progress of reading them. With --verbose the command line, timing and
+// commits read are logged like those of the other git commands.
+func (execSource) Stream(ctx context.Context, query gitwho.Query) iter.Seq2[*Commit, error] {
+	return func(yield func(*Commit, error) bool) {
+		args, err := gitwho.LogArgs(query)
+		if err != nil {
+			yield(nil, fmt.Errorf("Error: %v", err))
+			return
+		}
+		debugf("+ %s\n", formatCommandLine("git", args))
+		start := time.Now()
+		read := 0
+		var failure error
+		defer func() {
+			debugf("  took %s, %d commits read", time.Since(start).Round(time.Millisecond), read)
+			if failure != nil {
+				debugf(", failed: %v", failure)
+			}
+			debugf("\n")
+		}()
+
+		progress := newProgress(args)
+		defer progress.finish()
+		for commit, err := range (gitwho.GitBackend{}).Stream(ctx, query) {
+			if err != nil {
+				failure = err
+				yield(nil, execError(ctx, err))
+				return
+			}
+			read++
+			progress.advance()
+			if !yield(commit, nil) {
+				return
+			}
+		}
+	}
+}
+
+// execError reports an interruption when ctx was cancelled, and otherwise
+// the error git log failed with
+func execError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return interruption(ctx)
+	}
+	return fmt.Errorf("Error: %v", err)
+}
+
+// Unsupported returns "", git honors every flag
+func (execSource) Unsupported() string {
+	return ""
+}
+
+// cachedSource remembers the commits another source returned by the
+// query they were read with
+type cachedSource struct {
+	source  CommitSource
+	entries map[string][]*Commit
+}
+
+// newCachedSource returns a cachedSource reading through source
+func newCachedSource(source CommitSource) *cachedSource {
+	return &cachedSource{source: source, entries: make(map[string][]*Commit)}
+}
+
+func (c *cachedSource) Commits(ctx context.Context, query gitwho.Query) ([]*Commit, error) {
+	var commits []*Commit
+	for commit, err := range c.Stream(ctx, query) {
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+// Stream yields copies of the cached commits, since the analysis modifies
+// the commits it is given. A history not read before is streamed from the
+// source, and only cached once it was read to the end.
+func (c *cachedSource) Stream(ctx context.Context, query gitwho.Query) iter.Seq2[*Commit, error] {
+	return func(yield func(*Commit, error) bool) {
+		key := queryKey(query)
+		if commits, ok := c.entries[key]; ok {
+			debugf("Reusing the history of %s read before\n", query.Repo)
+			for _, commit := range commits {
+				if !yield(cloneCommit(commit), nil) {
+					return
+				}
+			}
+			return
+		}
+
+		var commits []*Commit
+		for commit, err := range c.read(ctx, query) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			commits = append(commits, cloneCommit(commit))
+			if !yield(commit, nil) {
+				return
+			}
+		}
+		c.entries[key] = commits
+	}
+}
+
+// queryKey identifies the history a query selects by all of its fields
+func queryKey(query gitwho.Query) string {
+	return strings.Join([]string{
+		strconv.Quote(query.Repo),
+		fmt.Sprintf("%q", query.Paths),
+		fmt.Sprintf("%q", query.Revisions),
+		query.Since.UTC().Format(time.RFC3339Nano),
+		query.Until.UTC().Format(time.RFC3339Nano),
+		strconv.FormatBool(query.NoMerges),
+		strconv.FormatBool(query.FirstParent),
+		strconv.FormatBool(query.Trailers),
+		strconv.FormatBool(query.Signed),
+		strconv.Quote(query.Mailmap),
+		strconv.FormatBool(query.IgnoreWhitespace),
+		strconv.Itoa(query.RenameSimilarity),
+		strconv.Itoa(query.CopySimilarity),
+		strconv.FormatBool(query.Follow),
+		fmt.Sprintf("%q", query.GitArgs),
+	}, " ")
+}
+
+// read yields the commits of the source, all at once unless it can
+// stream them
+func (c *cachedSource) read(ctx context.Context, query gitwho.Query) iter.Seq2[*Commit, error] {
+	if streaming, ok := c.source.(gitwho.StreamingBackend); ok {
+		return streaming.Stream(ctx, query)
+	}
+	return func(yield func(*Commit, error) bool) {
+		commits, err := c.source.Commits(ctx, query)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for _, commit := range commits {
+			if !yield(commit, nil) {
+				return
+			}
+		}
+	}
+}
+
+func (c *cachedSource) Unsupported() string {
+	return c.source.Unsupported()
+}
+
+// cloneCommit returns a deep copy of a commit
+func cloneCommit(commit *Commit) *Commit {
+	clone := *commit
+	clone.Files = slices.Clone(commit.Files)
+	clone.CoAuthors = slices.Clone(commit.CoAuthors)
+	clone.Reviewers = slices.Clone(commit.Reviewers)
+	clone.SignOffs = slices.Clone(commit.SignOffs)
+	return &clone
+}
@@ -0,0 +1,380 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PerArneng/gitwho/pkg/gitwho"
+)
+
+func TestCommitSources(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit("alice", map[string]string{"src/main.go": "a\nb\n", "README.md": "hi\n"})
+	repo.commit("bob", map[string]string{"src/main.go": "a\nc\nd\n"})
+	query := gitwho.Query{Repo: repo.dir, Paths: []string{"src"}}
+
+	for _, name := range backendNames() {
+		t.Run(name, func(t *testing.T) {
+			commits, err := commitSources[name]().Commits(context.Background(), query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, commit := range commits {
+				for _, change := range commit.Files {
+					got = append(got, commit.Name+" "+change.Path)
+				}
+			}
+			// Newest first, only the changes below the paths
+			want := []string{"bob src/main.go", "alice src/main.go"}
+			if !slices.Equal(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+			if commits[0].Files[0].Additions != 2 || commits[0].Files[0].Deletions != 1 {
+				t.Errorf("got %+v, want 2 additions and 1 deletion", commits[0].Files[0])
+			}
+		})
+	}
+}
+
+func TestCommitSourcesMailmapFile(t *testing.T) {
+	// The mailmap file overrides the repository's .mailmap, as with git's
+	// mailmap.file
+	repo := newTestRepo(t)
+	repo.commit("alice", map[string]string{".mailmap": "Alice A <alice@example.com>\n", "main.go": "a\n"})
+	repo.commit("bob", map[string]string{"main.go": "b\n"})
+	mailmap := filepath.Join(t.TempDir(), "mailmap")
+	if err := os.WriteFile(mailmap, []byte("Alice Anders <alice@example.com>\nBob B <bob@example.org> <bob@example.com>\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	query := gitwho.Query{Repo: repo.dir, Mailmap: mailmap}
+
+	for _, name := range backendNames() {
+		t.Run(name, func(t *testing.T) {
+			commits, err := commitSources[name]().Commits(context.Background(), query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, commit := range commits {
+				got = append(got, commit.Name+" <"+commit.Email+">")
+			}
+			want := []string{"Bob B <bob@example.org>", "Alice Anders <alice@example.com>"}
+			if !slices.Equal(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestCommitSourcesInterrupted(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit("alice", map[string]string{"main.go": "a\n"})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, name := range backendNames() {
+		t.Run(name, func(t *testing.T) {
+			_, err := commitSources[name]().Commits(ctx, gitwho.Query{Repo: repo.dir})
+			if err == nil || !strings.HasSuffix(err.Error(), "interrupted") {
+				t.Errorf("reading with a cancelled context = %v, want the interruption", err)
+			}
+		})
+	}
+}
+
+func TestExecSourceVerbose(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit("alice", map[string]string{"main.go": "a\n"})
+	repo.commit("bob", map[string]string{"main.go": "b\n"})
+	defer func(previous bool, output io.Writer) { verbose, debugOutput = previous, output }(verbose, debugOutput)
+	var log strings.Builder
+	verbose, debugOutput = true, &log
+
+	query := gitwho.Query{Repo: repo.dir}
+	if _, err := commitSources["exec"]().Commits(context.Background(), query); err != nil {
+		t.Fatal(err)
+	}
+	args, err := gitwho.LogArgs(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "+ " + formatCommandLine("git", args) + "\n"; !strings.Contains(log.String(), want) {
+		t.Errorf("verbose output %q does not show the git log command line %q", log.String(), want)
+	}
+	if !strings.Contains(log.String(), ", 2 commits read\n") {
+		t.Errorf("verbose output %q does not show the 2 commits read", log.String())
+	}
+}
+
+func TestCommitSourceUnsupported(t *testing.T) {
+	defer func(previous string) { findCopies = previous }(findCopies)
+	findCopies = "50"
+
+	if flag := commitSources["exec"]().Unsupported(); flag != "" {
+		t.Errorf("exec does not support %s, want every flag", flag)
+	}
+	if flag := commitSources["gogit"]().Unsupported(); flag != "--find-copies" {
+		t.Errorf("gogit does not support %q, want --find-copies", flag)
+	}
+}
+
+// TestCommitSourcesAgree reads one history with every backend and expects
+// the same commits, files and line counts as git reports
+func TestCommitSourcesAgree(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit("alice", map[string]string{
+		"main.go":  "package main\n\nfunc main() {\n}\n",
+		"notes.md": "b\nb\nd\nd\na\na\na\nc\nb\na\na\n",
+	})
+	repo.commit("bob", map[string]string{
+		"main.go":  "package main\n\nfunc main() {\n\tprintln(1)\n}\n\nfunc f() {\n}\n",
+		"notes.md": "c\nc\nc\nc\n",
+	})
+	repo.git("mv", "main.go", "app.go")
+	repo.commit("carol", map[string]string{"app.go": "package main\n\nfunc main()  {\n\tprintln(1)\n}\n\nfunc f() {\n}\n"})
+	repo.commit("alice", map[string]string{"notes.md": ""})
+	// Changes of whitespace only, with every character git takes as one
+	repo.commit("bob", map[string]string{"space.txt": "a b\n\tc\nd\ve\nf\fg\nh\r\n"})
+	repo.commit("carol", map[string]string{"space.txt": "ab\n\v c\nd\fe\nf\t g\nh\n"})
+	// Paths git quotes in its output
+	repo.commit("alice", map[string]string{"docs/ä.md": "ä\n", "tab\tname.txt": "t\n"})
+
+	for _, ignoreWhitespace := range []bool{false, true} {
+		query := gitwho.Query{Repo: repo.dir, IgnoreWhitespace: ignoreWhitespace}
+		want := describeCommits(t, "exec", query)
+		for _, name := range backendNames() {
+			if got := describeCommits(t, name, query); !slices.Equal(got, want) {
+				t.Errorf("%s with ignoreWhitespace %v read %v, want %v like git", name, ignoreWhitespace, got, want)
+			}
+		}
+	}
+}
+
+// TestCommitSourcesAgreeOnSignatures signs commits with an SSH key, and
+// expects every backend to count the good signature and not the bad one
+func TestCommitSourcesAgreeOnSignatures(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is not installed")
+	}
+	repo := newTestRepo(t)
+	key := filepath.Join(t.TempDir(), "key")
+	if output, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", key).CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen: %v\n%s", err, output)
+	}
+	public, err := os.ReadFile(key + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signers := filepath.Join(t.TempDir(), "allowed_signers")
+	if err := os.WriteFile(signers, []byte("* "+string(public)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo.git("config", "gpg.format", "ssh")
+	repo.git("config", "user.signingkey", key)
+	repo.git("config", "gpg.ssh.allowedSignersFile", signers)
+
+	repo.commit("alice", map[string]string{"a.txt": "a\n"})
+	repo.git("config", "commit.gpgsign", "true")
+	repo.commit("bob", map[string]string{"b.txt": "b\n"})
+	repo.commit("carol", map[string]string{"c.txt": "c\n"})
+	// Changing the message of carol's commit breaks its signature
+	tampered := strings.Replace(repo.git("cat-file", "commit", "HEAD"), "Commit 3", "Commit 3, changed", 1)
+	object := filepath.Join(t.TempDir(), "commit")
+	if err := os.WriteFile(object, []byte(tampered), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo.git("update-ref", "HEAD", strings.TrimSpace(repo.git("hash-object", "-t", "commit", "-w", object)))
+
+	query := gitwho.Query{Repo: repo.dir, Signed: true}
+	for _, name := range backendNames() {
+		commits, err := commitSources[name]().Commits(context.Background(), query)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		var signed []string
+		for _, commit := range commits {
+			if commit.Signed {
+				signed = append(signed, commit.Name)
+			}
+		}
+		if want := []string{"bob"}; !slices.Equal(signed, want) {
+			t.Errorf("%s counts the commits of %v as signed, want %v", name, signed, want)
+		}
+	}
+}
+
+// describeCommits reads the history with a backend and describes each
+// file change as "author path additions/deletions"
+func describeCommits(t *testing.T, backend string, query gitwho.Query) []string {
+	t.Helper()
+	commits, err := commitSources[backend]().Commits(context.Background(), query)
+	if err != nil {
+		t.Fatalf("%s: %v", backend, err)
+	}
+	var changes []string
+	for _, commit := range commits {
+		for _, change := range commit.Files {
+			changes = append(changes, fmt.Sprintf("%s %s %d/%d", commit.Name, change.Path, change.Additions, change.Deletions))
+		}
+	}
+	slices.Sort(changes)
+	return changes
+}
+
+// countingSource returns the same commits for every query, and counts how
+// often it was read
+type countingSource struct {
+	commits []*Commit
+	reads   int
+}
+
+func (s *countingSource) Commits(ctx context.Context, query gitwho.Query) ([]*Commit, error) {
+	s.reads++
+	var commits []*Commit
+	for _, commit := range s.commits {
+		commits = append(commits, cloneCommit(commit))
+	}
+	return commits, nil
+}
+
+func (s *countingSource) Unsupported() string {
+	return ""
+}
+
+func TestCachedSource(t *testing.T) {
+	source := &countingSource{commits: []*Commit{
+		{Name: "alice", Files: []gitwho.FileChange{{Path: "main.go", Additions: 2}}},
+		{Name: "bob", Files: []gitwho.FileChange{{Path: "README.md", Deletions: 1}}},
+	}}
+	cached := newCachedSource(source)
+	query := gitwho.Query{Repo: "repo", Paths: []string{"src"}}
+
+	first, err := cached.Commits(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The analysis drops the changes to excluded files from the commits
+	first[0].Files = nil
+
+	second, err := cached.Commits(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source.reads != 1 {
+		t.Errorf("the history was read %d times, want once", source.reads)
+	}
+	if len(second) != 2 || len(second[0].Files) != 1 || second[0].Files[0].Additions != 2 {
+		t.Errorf("the cached history is %+v, want it as read", second)
+	}
+
+	// Another query reads the history again
+	if _, err := cached.Commits(context.Background(), gitwho.Query{Repo: "repo"}); err != nil {
+		t.Fatal(err)
+	}
+	if source.reads != 2 {
+		t.Errorf("the history was read %d times, want twice", source.reads)
+	}
+}
+
+func TestCommitSourceCachesOnlyWhenAsked(t *testing.T) {
+	defer func(previous bool) { cacheHistory = previous }(cacheHistory)
+	defer func(previous map[string]CommitSource) { sources = previous }(sources)
+	sources = make(map[string]CommitSource)
+
+	cacheHistory = false
+	if _, ok := commitSource().(*cachedSource); ok {
+		t.Errorf("a run reading its history once caches it")
+	}
+	cacheHistory = true
+	if _, ok := commitSource().(*cachedSource); !ok {
+		t.Errorf("a run reading its history again does not cache it")
+	}
+}
+
+// TestQueryKey changes each field of a query in turn, and expects every
+// change to give another key
+func TestQueryKey(t *testing.T) {
+	base := queryKey(gitwho.Query{})
+	if again := queryKey(gitwho.Query{Paths: []string{}}); again != base {
+		t.Errorf("equal queries have the keys %q and %q", base, again)
+	}
+
+	fields := reflect.TypeFor[gitwho.Query]()
+	for i := range fields.NumField() {
+		var query gitwho.Query
+		field := reflect.ValueOf(&query).Elem().Field(i)
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString("x")
+		case reflect.Bool:
+			field.SetBool(true)
+		case reflect.Int:
+			field.SetInt(1)
+		case reflect.Slice:
+			field.Set(reflect.ValueOf([]string{"x"}))
+		case reflect.Struct:
+			field.Set(reflect.ValueOf(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+		default:
+			t.Fatalf("queryKey test cannot set %s", fields.Field(i).Name)
+		}
+		if queryKey(query) == base {
+			t.Errorf("queryKey ignores %s", fields.Field(i).Name)
+		}
+	}
+
+	// Lists are told apart by their elements, not only their text
+	a := queryKey(gitwho.Query{Paths: []string{"a b"}})
+	b := queryKey(gitwho.Query{Paths: []string{"a", "b"}})
+	if a == b {
+		t.Errorf("the paths [a b] and [a, b] have the same key %q", a)
+	}
+}
+
+func TestCachedSourceStoppedEarly(t *testing.T) {
+	source := &countingSource{commits: []*Commit{{Name: "alice"}, {Name: "bob"}}}
+	cached := newCachedSource(source)
+	query := gitwho.Query{Repo: "repo"}
+
+	for range cached.Stream(context.Background(), query) {
+		break
+	}
+	commits, err := cached.Commits(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The history read in part is not cached, and is read again in full
+	if source.reads != 2 || len(commits) != 2 {
+		t.Errorf("read %d times into %d commits, want twice into 2", source.reads, len(commits))
+	}
+}
+
+func TestCachedSourceStreams(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit("alice", map[string]string{"main.go": "a\n"})
+	repo.commit("bob", map[string]string{"main.go": "b\n"})
+	cached := newCachedSource(commitSources["exec"]())
+	query := gitwho.Query{Repo: repo.dir}
+
+	for range 2 {
+		var names []string
+		for commit, err := range cached.Stream(context.Background(), query) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			names = append(names, commit.Name)
+		}
+		if want := []string{"bob", "alice"}; !slices.Equal(names, want) {
+			t.Errorf("got %v, want %v", names, want)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var squashPRs bool
+
+// squashPattern matches the pull request number GitHub appends to the
+// subject of a squash merge, e.g. "Add login page (#123)". Reverts, merges
+// and cherry-picks can end the same way, so a match is only taken for a
+// squash merge when the pull request was merged as that very commit.
+var squashPattern = regexp.MustCompile(`\(#(\d+)\)$`)
+
+// pullCommitsPage is the number of commits of a pull request fetched per
+// request, the most the API allows
+const pullCommitsPage = 100
+
+// pullCache is the cache file of the commit authors by pull request
+const pullCache = "pulls.json"
+
+// pullAuthor is the author of a commit in a pull request
+type pullAuthor struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// pullRequest is the merge commit of a pull request and the authors of
+// its commits
+type pullRequest struct {
+	MergeCommit string       `json:"mergeCommit"`
+	Authors     []pullAuthor `json:"authors"`
+}
+
+// githubRepoSlug returns the owner/repo of the repository's origin remote
+func githubRepoSlug(root string) (string, error) {
+	var origin string
+	var err error
+	if usesGoGit() {
+		origin, err = goGitRemoteURL(root, "origin")
+	} else {
+		origin, err = runGit(nil, "-C", root, "remote", "get-url", "origin")
+	}
+	if err != nil {
+		return "", fmt.Errorf("Error: --squash-prs needs an origin remote on GitHub")
+	}
+	parts := strings.Split(filepath.ToSlash(cacheKey(strings.TrimSpace(origin))), "/")
+	if len(parts) < 3 || parts[0] != "github.com" && os.Getenv(githubAPIEnv) == "" {
+		return "", fmt.Errorf("Error: --squash-prs needs an origin remote on GitHub, got %s (set %s for GitHub Enterprise)", strings.TrimSpace(origin), githubAPIEnv)
+	}
+	return parts[len(parts)-2] + "/" + parts[len(parts)-1], nil
+}
+
+// fetchPullRequest returns the merge commit of a pull request and the
+// distinct authors of its commits, in the order of their first commit. A
+// number that is not a pull request has no authors.
+func fetchPullRequest(client *http.Client, slug string, number string) (pullRequest, error) {
+	var pull struct {
+		MergeCommit string `json:"merge_commit_sha"`
+	}
+	err := githubGet(client, "/repos/"+slug+"/pulls/"+number, &pull)
+	if err == errNotFound {
+		return pullRequest{}, nil
+	}
+	if err != nil {
+		return pullRequest{}, err
+	}
+
+	request := pullRequest{MergeCommit: pull.MergeCommit, Authors: []pullAuthor{}}
+	seen := make(map[string]bool)
+	for page := 1; ; page++ {
+		var commits []struct {
+			Commit struct {
+				Author pullAuthor `json:"author"`
+			} `json:"commit"`
+		}
+		path := fmt.Sprintf("/repos/%s/pulls/%s/commits?per_page=%d&page=%d", slug, number, pullCommitsPage, page)
+		if err := githubGet(client, path, &commits); err != nil {
+			return pullRequest{}, err
+		}
+		for _, commit := range commits {
+			key := strings.ToLower(commit.Commit.Author.Email)
+			if !seen[key] {
+				seen[key] = true
+				request.Authors = append(request.Authors, commit.Commit.Author)
+			}
+		}
+		if len(commits) < pullCommitsPage {
+			return request, nil
+		}
+	}
+}
+
+// attributePullRequests credits squash merges to the authors of the
+// commits of their pull request, dividing the lines evenly between them.
+// The Co-authored-by trailers GitHub adds to such merges name the same
+// people, so they are dropped. Pull requests are looked up on GitHub and
+// cached on disk; when a lookup fails the remaining squash merges keep
+// their author.
+func attributePullRequests(commits []*Commit, root string) ([]*Commit, error) {
+	slug, err := githubRepoSlug(root)
+	if err != nil {
+		return nil, err
+	}
+	cache := loadGitHubCache(pullCache)
+	client := &http.Client{Timeout: githubTimeout}
+
+	var lookupErr error
+	unresolved := 0
+	var attributed []*Commit
+	for _, commit := range commits {
+		match := squashPattern.FindStringSubmatch(commit.Subject)
+		if match == nil {
+			attributed = append(attributed, commit)
+			continue
+		}
+
+		key := slug + "#" + match[1]
+		var pull pullRequest
+		ok := cache.get(key, &pull)
+		if !ok && lookupErr == nil {
+			pull, lookupErr = fetchPullRequest(client, slug, match[1])
+			if lookupErr == nil {
+				cache.put(key, pull, len(pull.Authors) == 0)
+				ok = true
+			}
+		}
+		if !ok {
+			unresolved++
+		}
+		if len(pull.Authors) == 0 || pull.MergeCommit != commit.Hash {
+			attributed = append(attributed, commit)
+			continue
+		}
+		authors := pull.Authors
+
+		commit.Name, commit.Email = authors[0].Name, authors[0].Email
+		commit.CoAuthors = nil
+		credited := []*Commit{commit}
+		for _, author := range authors[1:] {
+			credited = append(credited, &Commit{Hash: commit.Hash, Name: author.Name, Email: author.Email, Date: commit.Date, Signed: commit.Signed, Subject: commit.Subject})
+		}
+		divideFiles(credited, commit.Files, "split")
+		attributed = append(attributed, credited...)
+	}
+
+	if err := cache.save(); err != nil {
+		debugf("Not caching pull requests: %v\n", err)
+	}
+	if lookupErr != nil {
+		// A failed lookup keeps the squash merges' authors rather than
+		// failing the analysis
+		infof("Error looking up %s on GitHub: %v\n", pluralize(unresolved, "pull request"), lookupErr)
+	}
+	return attributed, nil
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAttributePullRequests(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.git("remote", "add", "origin", "https://github.com/org/repo.git")
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	// Pull request 1 has more commits than fit on a page, by two authors
+	var pages [][]string
+	for page := 0; page < 2; page++ {
+		var emails []string
+		for i := 0; i < pullCommitsPage; i++ {
+			emails = append(emails, "alice@example.com")
+		}
+		pages = append(pages, emails)
+	}
+	pages = append(pages, []string{"bob@example.com"})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/repos/org/repo/pulls/1":
+			fmt.Fprint(w, `{"merge_commit_sha": "squash"}`)
+		case r.URL.Path == "/repos/org/repo/pulls/1/commits":
+			var page int
+			fmt.Sscan(r.URL.Query().Get("page"), &page)
+			var commits []string
+			if page >= 1 && page <= len(pages) {
+				for _, email := range pages[page-1] {
+					name, _, _ := strings.Cut(email, "@")
+					commits = append(commits, fmt.Sprintf(`{"commit": {"author": {"name": %q, "email": %q}}}`, name, email))
+				}
+			}
+			fmt.Fprint(w, "["+strings.Join(commits, ",")+"]")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	t.Setenv(githubAPIEnv, server.URL)
+
+	commits := []*Commit{
+		{Hash: "squash", Name: "merger", Subject: "Add login page (#1)", Files: []FileChange{{Path: "a.go", Additions: 10}}},
+		{Hash: "revert", Name: "carol", Subject: "Revert login page (#1)", Files: []FileChange{{Path: "a.go", Deletions: 10}}},
+		{Hash: "other", Name: "dave", Subject: "Unrelated change", Files: []FileChange{{Path: "b.go", Additions: 1}}},
+	}
+	attributed, err := attributePullRequests(commits, repo.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, commit := range attributed {
+		got = append(got, commit.Hash+":"+commit.Name)
+	}
+	want := "squash:alice squash:bob revert:carol other:dave"
+	if strings.Join(got, " ") != want {
+		t.Errorf("attributed %v, want %s", got, want)
+	}
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// gitlinkMode is the file mode git records for a submodule
+const gitlinkMode = "160000"
+
+// findSubmodules returns the paths of the submodules below the paths,
+// relative to the repository root
+func findSubmodules(root string, relPaths []string) ([]string, error) {
+	if usesGoGit() {
+		submodules, err := goGitSubmodules(root, relPaths)
+		if err != nil {
+			return nil, fmt.Errorf("Error listing submodules: %v", err)
+		}
+		return submodules, nil
+	}
+	output, err := runGit(nil, append([]string{"-C", root, "ls-files", "--stage", "--"}, relPaths...)...)
+	if err != nil {
+		return nil, fmt.Errorf("Error listing submodules: %v", err)
+	}
+
+	var submodules []string
+	for _, line := range strings.Split(output, "\n") {
+		// Lines look like "160000 <hash> 0\tpath"
+		info, path, ok := strings.Cut(line, "\t")
+		if ok && strings.HasPrefix(info, gitlinkMode+" ") {
+			submodules = append(submodules, path)
+		}
+	}
+	return submodules, nil
+}
+
+// withSubmodules replaces the changes to the commit pointers of the
+// submodules below the paths with the history of the submodules
+// themselves, whose file paths are prefixed with the submodule path.
+// Submodules are analyzed at their checked-out commit over the same time
+// range; revision ranges, tags and branches only apply to the repository
+// that contains them. Submodules that are not checked out are skipped.
+func withSubmodules(commits []*Commit, relPaths []string, timeRange string, root string) ([]*Commit, error) {
+	submodules, err := findSubmodules(root, relPaths)
+	if err != nil || len(submodules) == 0 {
+		return commits, err
+	}
+
+	gitlinks := make(map[string]bool)
+	for _, submodule := range submodules {
+		gitlinks[submodule] = true
+	}
+	for _, commit := range commits {
+		var files []FileChange
+		for _, change := range commit.Files {
+			if !gitlinks[change.Path] {
+				files = append(files, change)
+			}
+		}
+		commit.Files = files
+	}
+
+	for _, submodule := range submodules {
+		dir := filepath.Join(root, submodule)
+		if subRoot, err := findGitRoot(dir); err != nil || subRoot != dir {
+			// An uninitialized submodule is an empty directory inside the
+			// parent's work tree
+			infof("Skipping submodule %s: not checked out\n", submodule)
+			continue
+		}
+
+		subCommits, err := collectRevisions([]string{"."}, nil, timeRange, dir)
+		if err != nil {
+			return nil, fmt.Errorf("Error analyzing submodule %s: %v", submodule, err)
+		}
+		commits = append(commits, prefixPaths(subCommits, submodule)...)
+	}
+	return commits, nil
+}
@@ -0,0 +1,329 @@
+package cmd
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/PerArneng/gitwho/pkg/gitwho"
+	"github.com/spf13/cobra"
+)
+
+var suggestStaged bool
+var suggestPatch string
+var suggestTop int
+var suggestIncludeSelf bool
+var suggestFormat string
+
+// suggestCmd represents the suggest command
+var suggestCmd = &cobra.Command{
+	Use:   "suggest",
+	Short: "Suggest reviewers for the uncommitted changes or a patch",
+	Long: `Suggest looks at the files changed in the working tree and the index
+compared to HEAD, or only the staged files with --staged, and ranks the
+people who contributed most to those files as reviewers. With --patch the
+files are taken from a unified diff or patch file instead, or from stdin
+when the file is -.
+
+Each reviewer scores their share of the changed lines of every touched
+file, so someone who wrote most of three of the files ranks above someone
+who wrote a little of all of them. You are left out of the list unless
+--include-self is given.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !isValidTableFormat(suggestFormat) {
+			fmt.Printf("Error: Invalid format %s. Supported formats: table, csv, json\n", suggestFormat)
+			os.Exit(1)
+		}
+
+		repo := repoPath
+		if repo == "" {
+			var err error
+			if repo, err = findRepoForPath("."); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+		root, err := findGitRoot(repo)
+		if err != nil {
+			fmt.Printf("Error finding git root: %v\n", err)
+			os.Exit(1)
+		}
+
+		var files []string
+		if suggestPatch != "" {
+			files, err = patchFilesFrom(suggestPatch)
+		} else {
+			files, err = changedFiles(root, suggestStaged)
+		}
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		suggestReviewers(root, files)
+	},
+}
+
+func init() {
+	suggestCmd.Flags().BoolVar(&suggestStaged, "staged", false, "Only consider the staged changes")
+	suggestCmd.Flags().StringVar(&suggestPatch, "patch", "", "Suggest reviewers for the files of a unified diff or patch file (- for stdin)")
+	suggestCmd.Flags().IntVar(&suggestTop, "top", 5, "Number of reviewers to suggest (0 for all)")
+	suggestCmd.Flags().BoolVar(&suggestIncludeSelf, "include-self", false, "Include yourself (git config user.email) in the suggestions")
+	suggestCmd.Flags().StringVar(&suggestFormat, "format", "table", "Output format (table, csv, json)")
+	rootCmd.AddCommand(suggestCmd)
+}
+
+// reviewerSuggestion is a ranked reviewer candidate for a set of files
+type reviewerSuggestion struct {
+	Name    string   `json:"name"`
+	Email   string   `json:"email"`
+	Score   float64  `json:"score"`
+	Commits int      `json:"commits"`
+	Files   []string `json:"files"`
+}
+
+// changedFiles lists the files with uncommitted changes compared to HEAD,
+// or only the staged ones, relative to the repository root
+func changedFiles(root string, staged bool) ([]string, error) {
+	args := []string{"-C", root, "diff", "--name-only", "-z"}
+	if staged {
+		args = append(args, "--cached")
+	} else {
+		args = append(args, "HEAD")
+	}
+	output, err := runGit(os.Stderr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Error listing changed files: %v", err)
+	}
+
+	var files []string
+	for _, file := range strings.Split(output, "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// patchFilesFrom reads the files changed by a patch file, or by the patch
+// on stdin when path is -
+func patchFilesFrom(path string) ([]string, error) {
+	if path == "-" {
+		return patchFiles(os.Stdin)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading patch: %v", err)
+	}
+	defer file.Close()
+	return patchFiles(file)
+}
+
+// patchFiles returns the files changed by a unified diff, as produced by
+// git diff, git format-patch or diff -u. The a/ and b/ prefixes are
+// stripped; deleted files are listed by their old path. The lines of a
+// hunk are counted off its @@ header so content starting with --- or +++
+// is not taken for a file name.
+func patchFiles(r io.Reader) ([]string, error) {
+	var files []string
+	add := func(path string) {
+		if path != "" && !slices.Contains(files, path) {
+			files = append(files, path)
+		}
+	}
+
+	var oldPath string
+	var oldLines, newLines int
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if oldLines > 0 || newLines > 0 {
+			switch {
+			case strings.HasPrefix(line, "-"):
+				oldLines--
+			case strings.HasPrefix(line, "+"):
+				newLines--
+			case strings.HasPrefix(line, "\\"):
+				// "\ No newline at end of file"
+			default:
+				oldLines--
+				newLines--
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			// Renames, mode changes and binary files may have no ---/+++
+			// lines, so the file is taken from the header when it is
+			// unambiguous
+			oldPath = ""
+			add(gitDiffHeaderPath(strings.TrimPrefix(line, "diff --git ")))
+		case strings.HasPrefix(line, "rename to "), strings.HasPrefix(line, "copy to "):
+			_, path, _ := strings.Cut(line, " to ")
+			add(patchPath(path, ""))
+		case strings.HasPrefix(line, "--- "):
+			oldPath = patchPath(strings.TrimPrefix(line, "--- "), "a/")
+		case strings.HasPrefix(line, "+++ "):
+			path := patchPath(strings.TrimPrefix(line, "+++ "), "b/")
+			if path == "" {
+				path = oldPath
+			}
+			add(path)
+		case strings.HasPrefix(line, "@@ "):
+			var ok bool
+			if oldLines, newLines, ok = hunkLines(line); !ok {
+				return nil, fmt.Errorf("Error reading patch: invalid hunk header %q", line)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Error reading patch: %v", err)
+	}
+	return files, nil
+}
+
+// hunkLines returns the number of old and new lines of a hunk from its
+// "@@ -start,count +start,count @@" header. A missing count means one line.
+func hunkLines(header string) (oldLines int, newLines int, ok bool) {
+	fields := strings.Fields(header)
+	if len(fields) < 4 || fields[3] != "@@" {
+		return 0, 0, false
+	}
+	count := func(field string, sign string) (int, bool) {
+		r, found := strings.CutPrefix(field, sign)
+		if !found {
+			return 0, false
+		}
+		_, n, found := strings.Cut(r, ",")
+		if !found {
+			return 1, true
+		}
+		lines, err := strconv.Atoi(n)
+		return lines, err == nil
+	}
+	oldLines, okOld := count(fields[1], "-")
+	newLines, okNew := count(fields[2], "+")
+	return oldLines, newLines, okOld && okNew
+}
+
+// gitDiffHeaderPath returns the path of a "diff --git a/path b/path"
+// header when both sides name the same file, or "" when the header is
+// quoted or names different files
+func gitDiffHeaderPath(names string) string {
+	n := len(names) - len("a/ b/")
+	if n <= 0 || n%2 != 0 || !strings.HasPrefix(names, "a/") {
+		return ""
+	}
+	oldPath, newPath := names[2:2+n/2], names[2+n/2:]
+	if newPath != " b/"+oldPath {
+		return ""
+	}
+	return oldPath
+}
+
+// patchPath extracts the path from the file name of a ---/+++ line, which
+// may be quoted and followed by a tab and a timestamp. /dev/null yields "".
+func patchPath(name string, prefix string) string {
+	name, _, _ = strings.Cut(name, "\t")
+	name = strings.TrimSpace(name)
+	if unquoted, err := strconv.Unquote(name); err == nil {
+		name = unquoted
+	}
+	if name == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(name, prefix)
+}
+
+// suggestReviewers ranks and prints the reviewers for files, given
+// relative to the repository root
+func suggestReviewers(root string, files []string) {
+	if len(files) == 0 {
+		fmt.Println("No changed files to suggest reviewers for")
+		return
+	}
+
+	// The files are handed to git as literal pathspecs from the root so
+	// files the change deletes are found in the history too
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = ":(top,literal)" + file
+	}
+	commits, err := collectCommits(paths, lastTimeRange, root)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	suggestions := buildSuggestions(commits, files)
+	if !suggestIncludeSelf {
+		self, _ := runGit(nil, "-C", root, "config", "user.email")
+		suggestions = slices.DeleteFunc(suggestions, func(s *reviewerSuggestion) bool {
+			return strings.EqualFold(s.Email, strings.TrimSpace(self))
+		})
+	}
+	if suggestTop > 0 && len(suggestions) > suggestTop {
+		suggestions = suggestions[:suggestTop]
+	}
+
+	if err := displayTable(os.Stdout, suggestFormat, suggestionTable(suggestions), suggestions); err != nil {
+		fmt.Printf("Error writing output: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// buildSuggestions scores every contributor to the files by the sum of
+// their share of each file's changed lines, the highest score first
+func buildSuggestions(commits []*Commit, files []string) []*reviewerSuggestion {
+	stats := make(map[string]*reviewerSuggestion)
+	perFile := splitCommits(commits, func(path string) string { return path })
+	for _, file := range files {
+		contributors := gitwho.Aggregate(perFile[file])
+		gitwho.ComputeShares(contributors)
+		for _, contributor := range contributors {
+			key := contributor.Name + "|" + contributor.Email
+			s := stats[key]
+			if s == nil {
+				s = &reviewerSuggestion{Name: contributor.Name, Email: contributor.Email}
+				stats[key] = s
+			}
+			s.Score += contributor.ChangeShare / 100
+			s.Commits += contributor.Commits
+			s.Files = append(s.Files, file)
+		}
+	}
+
+	suggestions := make([]*reviewerSuggestion, 0, len(stats))
+	for _, s := range stats {
+		suggestions = append(suggestions, s)
+	}
+	slices.SortFunc(suggestions, func(a, b *reviewerSuggestion) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(b.Commits, a.Commits), cmp.Compare(a.Name, b.Name))
+	})
+	return suggestions
+}
+
+// suggestionTable lays out the ranked reviewers
+func suggestionTable(suggestions []*reviewerSuggestion) textTable {
+	table := textTable{
+		Headers: []string{"NAME", "EMAIL", "SCORE", "FILES", "COMMITS"},
+		Numeric: []bool{false, false, true, true, true},
+	}
+	for _, s := range suggestions {
+		table.Rows = append(table.Rows, []string{
+			s.Name,
+			s.Email,
+			strconv.FormatFloat(s.Score, 'f', 2, 64),
+			strconv.Itoa(len(s.Files)),
+			strconv.Itoa(s.Commits),
+		})
+	}
+	return table
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPatchFiles(t *testing.T) {
+	patch := `From 1234 Mon Sep 17 00:00:00 2001
+Subject: [PATCH] Change things
+
+---
+diff --git a/docs/notes.md b/docs/notes.md
+index 1111111..2222222 100644
+--- a/docs/notes.md
++++ b/docs/notes.md
+@@ -1,2 +1,2 @@
+ # Notes
+--- a/not/a/file
++++ b/not/a/file
+\ No newline at end of file
+diff --git a/old.go b/old.go
+deleted file mode 100644
+index 3333333..0000000
+--- a/old.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package old
+diff --git a/before.go b/after.go
+similarity index 100%
+rename from before.go
+rename to after.go
+diff --git a/logo.png b/logo.png
+index 4444444..5555555 100644
+Binary files a/logo.png and b/logo.png differ
+diff -u plain.txt.orig plain.txt
+--- plain.txt.orig	2024-01-01 00:00:00
++++ plain.txt	2024-01-02 00:00:00
+@@ -1,2 +1,2 @@
+-one
++two
+ three
+`
+	files, err := patchFiles(strings.NewReader(patch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"docs/notes.md", "old.go", "after.go", "logo.png", "plain.txt"}
+	if !slices.Equal(files, want) {
+		t.Errorf("patchFiles = %v, want %v", files, want)
+	}
+}
+
+func TestHunkLines(t *testing.T) {
+	tests := []struct {
+		header             string
+		oldLines, newLines int
+		ok                 bool
+	}{
+		{"@@ -1,3 +1,4 @@", 3, 4, true},
+		{"@@ -5 +5 @@ func main() {", 1, 1, true},
+		{"@@ -0,0 +1,2 @@", 0, 2, true},
+		{"@@ -1,x +1 @@", 0, 0, false},
+		{"@@ garbage", 0, 0, false},
+	}
+	for _, tt := range tests {
+		oldLines, newLines, ok := hunkLines(tt.header)
+		if ok != tt.ok || (ok && (oldLines != tt.oldLines || newLines != tt.newLines)) {
+			t.Errorf("hunkLines(%q) = %d, %d, %v, want %d, %d, %v", tt.header, oldLines, newLines, ok, tt.oldLines, tt.newLines, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// summaryFollowers is the number of runners-up mentioned after the leader
+const summaryFollowers = 2
+
+// buildSummaryText describes the contributor statistics as a short English
+// paragraph. Contributors are expected to be sorted already. The totals and
+// shares are those of every contributor in the report's summary, not only
+// of the ones --top keeps. The qualifiers of the table's title, like
+// "since 2024-01-01", narrow down the period.
+func buildSummaryText(report *Report, qualifiers []string) string {
+	period := summaryPeriod(report.TimeRange, qualifiers)
+	path := summaryPath(report.Path)
+
+	// The row merging the contributors after --top is nobody to name
+	contributors := slices.DeleteFunc(slices.Clone(report.Contributors), isOthersRow)
+	if len(contributors) == 0 {
+		return fmt.Sprintf("%s, nobody changed %s.", period, path)
+	}
+
+	summary := report.Summary
+	changes := summary.Additions + summary.Deletions
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s, %s made %s to %s.",
+		period,
+		pluralize(summary.Contributors, "contributor"),
+		pluralize(summary.Commits, "commit"),
+		path)
+
+	leader := contributors[0]
+	fmt.Fprintf(&b, " %s led with %s of changes", leader.Name, sharePercent(leader, changes))
+
+	followers := contributors[1:]
+	if len(followers) > summaryFollowers {
+		followers = followers[:summaryFollowers]
+	}
+	if len(followers) > 0 {
+		parts := make([]string, len(followers))
+		for i, contributor := range followers {
+			parts[i] = fmt.Sprintf("%s (%s)", contributor.Name, sharePercent(contributor, changes))
+		}
+		fmt.Fprintf(&b, ", followed by %s", strings.Join(parts, " and "))
+	}
+	b.WriteString(".")
+
+	return b.String()
+}
+
+// summaryPeriod names the part of the history a report covers to open a
+// sentence, e.g. "Over the last 90 days" or "Since 2024-01-01 on main"
+func summaryPeriod(timeRange string, qualifiers []string) string {
+	var phrases []string
+	if timeRange != "" {
+		phrases = append(phrases, timeRangePhrase(timeRange))
+	}
+	phrases = append(phrases, qualifiers...)
+	if len(phrases) == 0 {
+		return "Over the full history"
+	}
+	period := strings.Join(phrases, " ")
+	return strings.ToUpper(period[:1]) + period[1:]
+}
+
+// timeRangeUnits are the names of the --last time range units
+var timeRangeUnits = map[string]string{
+	"h": "hour", "hour": "hour", "hours": "hour",
+	"d": "day", "day": "day", "days": "day",
+	"w": "week", "week": "week", "weeks": "week",
+	"m": "month", "month": "month", "months": "month",
+	"q": "quarter", "quarter": "quarter", "quarters": "quarter",
+	"half-year": "half year", "half-years": "half year",
+	"y": "year", "year": "year", "years": "year",
+}
+
+// timeRangePhrase reads a --last time range as English, e.g. "over the
+// last 90 days" for 90d or "in the last calendar quarter"
+func timeRangePhrase(timeRange string) string {
+	if unit, ok := strings.CutPrefix(timeRange, "calendar-"); ok {
+		return "in the last calendar " + strings.ReplaceAll(unit, "-", " ")
+	}
+	n, unit := 1, strings.ToLower(timeRange)
+	if match := durationPattern.FindStringSubmatch(unit); match != nil {
+		n, _ = strconv.Atoi(match[1])
+		unit = match[2]
+	}
+	name, ok := timeRangeUnits[unit]
+	if !ok {
+		return "over the last " + timeRange
+	}
+	if n == 1 {
+		return "over the last " + name
+	}
+	return "over the last " + pluralize(n, name)
+}
+
+// summaryPath names the analyzed path in a sentence: the repository's name
+// instead of ".", so the whole repository does not read as "to ."
+func summaryPath(path string) string {
+	if path != "." {
+		return path
+	}
+	repo := repoPath
+	if repo == "" {
+		repo = "."
+	}
+	root, err := findGitRoot(repo)
+	if err != nil {
+		return path
+	}
+	return repoName(root)
+}
+
+// sharePercent formats a contributor's share of all line changes
+func sharePercent(contributor *Contributor, changes int) string {
+	if changes == 0 {
+		return "0%"
+	}
+	total := contributor.Additions + contributor.Deletions
+	return fmt.Sprintf("%.0f%%", float64(total)*100/float64(changes))
+}
+
+// pluralize formats a count followed by a singular or plural noun
+func pluralize(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// displaySummaryText prints the contributor statistics as a paragraph
+func displaySummaryText(w io.Writer, report *Report, options renderOptions) error {
+	_, err := fmt.Fprintln(w, buildSummaryText(report, options.Qualifiers))
+	return err
+}
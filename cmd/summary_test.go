@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PerArneng/gitwho/pkg/gitwho"
+)
+
+func TestBuildSummaryText(t *testing.T) {
+	contributors := []*Contributor{
+		{Name: "Jane Doe", Commits: 30, Additions: 500, Deletions: 100},
+		{Name: "John Smith", Commits: 10, Additions: 250, Deletions: 50},
+		{Name: "Ann Lee", Commits: 2, Additions: 80, Deletions: 20},
+	}
+	report := &Report{
+		Path:         "src/",
+		TimeRange:    "month",
+		Contributors: contributors,
+		Summary:      gitwho.Summarize(contributors),
+	}
+
+	want := "Over the last month, 3 contributors made 42 commits to src/. " +
+		"Jane Doe led with 60% of changes, followed by John Smith (30%) and Ann Lee (10%)."
+	got := buildSummaryText(report, nil)
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestBuildSummaryTextSingleContributor(t *testing.T) {
+	contributors := []*Contributor{{Name: "Jane Doe", Commits: 1, Additions: 3}}
+	report := &Report{
+		Path:         "src/",
+		Contributors: contributors,
+		Summary:      gitwho.Summarize(contributors),
+	}
+
+	got := buildSummaryText(report, nil)
+	for _, want := range []string{"Over the full history", "1 contributor made 1 commit", "Jane Doe led with 100%"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%q does not contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, "followed by") {
+		t.Errorf("%q mentions followers", got)
+	}
+}
+
+func TestBuildSummaryTextEmpty(t *testing.T) {
+	got := buildSummaryText(&Report{Path: "docs/", TimeRange: "week"}, nil)
+	if want := "Over the last week, nobody changed docs/."; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestBuildSummaryTextTop(t *testing.T) {
+	// --top 1 --others keeps the leader and merges the rest into one row,
+	// but the totals and shares are still those of everybody
+	contributors := []*Contributor{
+		{Name: "Jane Doe", Commits: 30, Additions: 500, Deletions: 100},
+		{Name: "John Smith", Commits: 10, Additions: 250, Deletions: 50},
+		{Name: "Ann Lee", Commits: 2, Additions: 80, Deletions: 20},
+	}
+	report := &Report{
+		Path:         "src/",
+		Contributors: limitContributors(contributors, 1, true),
+		Summary:      gitwho.Summarize(contributors),
+	}
+
+	want := "Over the full history, 3 contributors made 42 commits to src/. Jane Doe led with 60% of changes."
+	if got := buildSummaryText(report, nil); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestBuildSummaryTextRepository(t *testing.T) {
+	// The whole repository is named rather than "."
+	repo := newTestRepo(t)
+	defer func(previous string) { repoPath = previous }(repoPath)
+	repoPath = repo.dir
+
+	contributors := []*Contributor{{Name: "Jane Doe", Commits: 1, Additions: 3}}
+	report := &Report{Path: ".", Contributors: contributors, Summary: gitwho.Summarize(contributors)}
+	got := buildSummaryText(report, nil)
+	if want := "made 1 commit to " + filepath.Base(repo.dir) + "."; !strings.Contains(got, want) {
+		t.Errorf("%q does not contain %q", got, want)
+	}
+}
+
+func TestBuildSummaryTextQualifiers(t *testing.T) {
+	contributors := []*Contributor{{Name: "Jane Doe", Commits: 4, Additions: 3}}
+	report := &Report{Path: "src/", Contributors: contributors, Summary: gitwho.Summarize(contributors)}
+
+	defer func(since, until string) { sinceDate, untilDate = since, until }(sinceDate, untilDate)
+	sinceDate, untilDate = "2024-01-01", "2024-06-30"
+	got := buildSummaryText(report, newRenderOptions(false).Qualifiers)
+	if want := "Since 2024-01-01 until 2024-06-30, 1 contributor made 4 commits to src/."; !strings.HasPrefix(got, want) {
+		t.Errorf("got %q, want it to start with %q", got, want)
+	}
+}
+
+func TestSummaryPeriod(t *testing.T) {
+	tests := []struct {
+		timeRange  string
+		qualifiers []string
+		want       string
+	}{
+		{"", nil, "Over the full history"},
+		{"month", nil, "Over the last month"},
+		{"90d", nil, "Over the last 90 days"},
+		{"2 weeks", nil, "Over the last 2 weeks"},
+		{"calendar-quarter", nil, "In the last calendar quarter"},
+		{"calendar-half-year", nil, "In the last calendar half year"},
+		{"", []string{"on main..topic"}, "On main..topic"},
+		{"week", []string{"on all branches"}, "Over the last week on all branches"},
+	}
+	for _, test := range tests {
+		if got := summaryPeriod(test.timeRange, test.qualifiers); got != test.want {
+			t.Errorf("summaryPeriod(%q, %q) = %q, want %q", test.timeRange, test.qualifiers, got, test.want)
+		}
+	}
+}
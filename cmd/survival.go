@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/PerArneng/gitwho/pkg/gitwho"
+	"github.com/spf13/cobra"
+)
+
+var survivalFormat string
+
+// survivalCmd represents the survival command
+var survivalCmd = &cobra.Command{
+	Use:   "survival [file/directory]",
+	Short: "Show how many added lines survive at HEAD",
+	Long: `Survival compares the lines each contributor added to a file or
+directory with the lines git blame still attributes to their commits at
+HEAD. The surviving share is a measure of lasting contribution: code that
+was rewritten or deleted later does not count.
+
+With --last, only the lines added by commits in that time range are
+considered.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+		if !isValidTableFormat(survivalFormat) {
+			fmt.Printf("Error: Invalid format %s. Supported formats: table, csv, json\n", survivalFormat)
+			os.Exit(1)
+		}
+
+		commits, err := collectCommits([]string{path}, lastTimeRange, repoPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		root, files, err := collectBlameFiles(path, repoPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		survivors, err := buildSurvival(commits, root, files)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if err := displayTable(os.Stdout, survivalFormat, survivalTable(survivors), survivors); err != nil {
+			fmt.Printf("Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	survivalCmd.Flags().StringVar(&survivalFormat, "format", "table", "Output format (table, csv, json)")
+	rootCmd.AddCommand(survivalCmd)
+}
+
+// lineSurvival compares the lines a contributor added with the lines of
+// theirs that still exist at HEAD
+type lineSurvival struct {
+	Name      string  `json:"name"`
+	Email     string  `json:"email"`
+	Additions int     `json:"additions"`
+	Surviving int     `json:"surviving"`
+	Survival  float64 `json:"survival"`
+}
+
+// buildSurvival blames the files and credits each surviving line to the
+// author of the commit it came from, if that commit is one of commits
+func buildSurvival(commits []*Commit, root string, files []string) ([]*lineSurvival, error) {
+	contributors := gitwho.Aggregate(commits)
+	stats := make(map[string]*lineSurvival)
+	for _, contributor := range contributors {
+		stats[contributor.Name+"|"+contributor.Email] = &lineSurvival{
+			Name:      contributor.Name,
+			Email:     contributor.Email,
+			Additions: contributor.Additions,
+		}
+	}
+
+	authors := make(map[string]string)
+	for _, commit := range commits {
+		authors[commit.Hash] = commit.Name + "|" + commit.Email
+	}
+
+	for _, file := range files {
+		lines, _, err := blameFile(root, file)
+		if err != nil {
+			return nil, err
+		}
+		for hash, count := range lines {
+			if s, ok := stats[authors[hash]]; ok {
+				s.Surviving += count
+			}
+		}
+	}
+
+	result := make([]*lineSurvival, 0, len(stats))
+	for _, s := range stats {
+		if s.Additions > 0 {
+			s.Survival = float64(s.Surviving) * 100 / float64(s.Additions)
+		}
+		result = append(result, s)
+	}
+	slices.SortFunc(result, func(a, b *lineSurvival) int {
+		return cmp.Or(cmp.Compare(b.Surviving, a.Surviving), cmp.Compare(a.Name, b.Name), cmp.Compare(a.Email, b.Email))
+	})
+	return result, nil
+}
+
+// survivalTable lays out one row per contributor
+func survivalTable(survivors []*lineSurvival) textTable {
+	table := textTable{
+		Headers: []string{"NAME", "EMAIL", "ADDED", "SURVIVING", "SURVIVAL"},
+		Numeric: []bool{false, false, true, true, true},
+	}
+	for _, s := range survivors {
+		table.Rows = append(table.Rows, []string{
+			s.Name,
+			s.Email,
+			strconv.Itoa(s.Additions),
+			strconv.Itoa(s.Surviving),
+			formatPercent(s.Survival),
+		})
+	}
+	return table
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// textTable is a plain table of rows rendered by the subcommands
+type textTable struct {
+	Headers []string
+	Numeric []bool
+	Rows    [][]string
+}
+
+// isValidTableFormat reports whether format is supported by the subcommands
+// that print a textTable
+func isValidTableFormat(format string) bool {
+	return format == "table" || format == "csv" || format == "json"
+}
+
+// displayTable writes a subcommand's result as a table or CSV, or value
+// as JSON. The table is colored as --color selects.
+func displayTable(w io.Writer, format string, table textTable, value any) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write(table.Headers)
+		cw.WriteAll(table.Rows)
+		return cw.Error()
+	}
+
+	color, err := resolveColor(colorMode, w)
+	if err != nil {
+		return err
+	}
+	return writeTable(w, table, color)
+}
+
+// writeTable prints the table with each column as wide as its widest
+// cell, with a bold header when color is set
+func writeTable(w io.Writer, table textTable, color bool) error {
+	widths := make([]int, len(table.Headers))
+	for i, header := range table.Headers {
+		widths[i] = runewidth.StringWidth(header)
+	}
+	for _, row := range table.Rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], runewidth.StringWidth(cell))
+		}
+	}
+
+	pad := func(i int, value string) string {
+		if table.Numeric[i] {
+			return runewidth.FillLeft(value, widths[i])
+		}
+		return runewidth.FillRight(value, widths[i])
+	}
+
+	ew := &errWriter{w: w}
+	cells := make([]string, len(table.Headers))
+	for i, header := range table.Headers {
+		cells[i] = colorize(pad(i, header), ansiBold, color)
+	}
+	fmt.Fprintln(ew, strings.Join(cells, " "))
+
+	width := len(widths) - 1
+	for _, n := range widths {
+		width += n
+	}
+	fmt.Fprintln(ew, strings.Repeat("-", width))
+
+	for _, row := range table.Rows {
+		for i, cell := range row {
+			cells[i] = pad(i, cell)
+		}
+		fmt.Fprintln(ew, strings.Join(cells, " "))
+	}
+	return ew.err
+}
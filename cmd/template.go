@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+)
+
+var templateText string
+var templateFile string
+
+// loadTemplate parses the template given by --template or --template-file.
+// It returns nil when neither flag is set.
+func loadTemplate() (*template.Template, error) {
+	text := templateText
+	if templateFile != "" {
+		data, err := os.ReadFile(templateFile)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading template file: %v", err)
+		}
+		// The trailing newline of the file would otherwise double up with
+		// the newline printed after each contributor
+		text = strings.TrimSuffix(string(data), "\n")
+	}
+	if text == "" {
+		return nil, nil
+	}
+
+	tmpl, err := template.New("contributor").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing template: %v", err)
+	}
+	return tmpl, nil
+}
+
+// displayTemplate renders every contributor with the template, one
+// contributor per line. The template sees the same fields as the JSON
+// output, such as Name, Email, Commits, Files, Additions, Deletions and Total.
+func displayTemplate(w io.Writer, tmpl *template.Template, report *Report, options renderOptions) error {
+	for _, record := range buildReportDocument(report, options).Contributors {
+		if err := tmpl.Execute(w, record); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
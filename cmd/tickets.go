@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/PerArneng/gitwho/pkg/gitwho"
+	"github.com/spf13/cobra"
+)
+
+// defaultTicketPatterns match GitHub style issue references (#123, GH-123)
+// and issue keys of trackers such as Jira (PROJ-456)
+var defaultTicketPatterns = []string{
+	`#\d+\b`,
+	`\b[A-Z][A-Z0-9]+-\d+\b`,
+}
+
+var ticketPatterns []string
+var ticketsFormat string
+
+// ticketsCmd represents the tickets command
+var ticketsCmd = &cobra.Command{
+	Use:   "tickets [file/directory]",
+	Short: "Count the issue references in each contributor's commit messages",
+	Long: `Tickets extracts issue references such as #123 or PROJ-456 from the
+messages of the commits that touched a file or directory and reports, per
+contributor, how many commits and changed lines are linked to a ticket and
+how many distinct tickets they worked on.
+
+The patterns are regular expressions; replace the defaults with
+--ticket-pattern or the tickets list of the config file.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+		if !isValidTableFormat(ticketsFormat) {
+			fmt.Printf("Error: Invalid format %s. Supported formats: table, csv, json\n", ticketsFormat)
+			os.Exit(1)
+		}
+
+		commits, err := collectCommits([]string{path}, lastTimeRange, repoPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		config, err := loadConfig()
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		patterns := ticketPatterns
+		if len(patterns) == 0 {
+			patterns = config.Tickets
+		}
+		if len(patterns) == 0 {
+			patterns = defaultTicketPatterns
+		}
+		compiled, err := compilePatterns(patterns)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		repo := repoPath
+		if repo == "" {
+			if repo, err = findRepoForPath(path); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+		messages, err := commitMessages(repo, commits)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		stats := buildTicketStats(commits, messages, compiled)
+		if err := displayTable(os.Stdout, ticketsFormat, ticketsTable(stats), stats); err != nil {
+			fmt.Printf("Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	ticketsCmd.Flags().StringArrayVar(&ticketPatterns, "ticket-pattern", nil, "Regex of an issue reference, e.g. 'ABC-\\d+' (repeatable, replaces the defaults)")
+	ticketsCmd.Flags().StringVar(&ticketsFormat, "format", "table", "Output format (table, csv, json)")
+	rootCmd.AddCommand(ticketsCmd)
+}
+
+// ticketStats holds the issue references of one contributor's commits
+type ticketStats struct {
+	Name        string   `json:"name"`
+	Email       string   `json:"email"`
+	Commits     int      `json:"commits"`
+	Linked      int      `json:"linkedCommits"`
+	Churn       int      `json:"churn"`
+	LinkedChurn int      `json:"linkedChurn"`
+	Tickets     []string `json:"tickets"`
+}
+
+// commitMessages reads the full messages of the commits, keyed by hash
+func commitMessages(repoPath string, commits []*Commit) (map[string]string, error) {
+	var hashes []string
+	seen := make(map[string]bool)
+	for _, commit := range commits {
+		if !seen[commit.Hash] {
+			seen[commit.Hash] = true
+			hashes = append(hashes, commit.Hash)
+		}
+	}
+	messages := make(map[string]string)
+	if len(hashes) == 0 {
+		return messages, nil
+	}
+
+	output, err := runGitInput(strings.Join(hashes, "\n")+"\n", os.Stderr,
+		"-C", repoPath, "log", "--no-walk=unsorted", "--stdin", "--format="+gitwho.CommitMarker+"%H"+gitwho.FieldSeparator+"%B")
+	if err != nil {
+		return nil, fmt.Errorf("Error reading commit messages: %v", err)
+	}
+	for _, record := range strings.Split(output, gitwho.CommitMarker) {
+		if hash, message, ok := strings.Cut(record, gitwho.FieldSeparator); ok {
+			messages[hash] = message
+		}
+	}
+	return messages, nil
+}
+
+// findTickets returns the distinct references in a message, in order
+func findTickets(message string, patterns []*regexp.Regexp) []string {
+	var tickets []string
+	for _, re := range patterns {
+		for _, ticket := range re.FindAllString(message, -1) {
+			if !slices.Contains(tickets, ticket) {
+				tickets = append(tickets, ticket)
+			}
+		}
+	}
+	return tickets
+}
+
+// buildTicketStats counts the linked commits, lines and distinct tickets
+// per contributor, the contributors with the most tickets first
+func buildTicketStats(commits []*Commit, messages map[string]string, patterns []*regexp.Regexp) []*ticketStats {
+	stats := make(map[string]*ticketStats)
+	for _, commit := range commits {
+		if len(commit.Files) == 0 {
+			continue
+		}
+		key := commit.Name + "|" + commit.Email
+		s := stats[key]
+		if s == nil {
+			s = &ticketStats{Name: commit.Name, Email: commit.Email, Tickets: []string{}}
+			stats[key] = s
+		}
+
+		churn := 0
+		for _, change := range commit.Files {
+			churn += change.Additions + change.Deletions
+		}
+		s.Commits++
+		s.Churn += churn
+
+		tickets := findTickets(messages[commit.Hash], patterns)
+		if len(tickets) == 0 {
+			continue
+		}
+		s.Linked++
+		s.LinkedChurn += churn
+		for _, ticket := range tickets {
+			if !slices.Contains(s.Tickets, ticket) {
+				s.Tickets = append(s.Tickets, ticket)
+			}
+		}
+	}
+
+	result := make([]*ticketStats, 0, len(stats))
+	for _, s := range stats {
+		slices.Sort(s.Tickets)
+		result = append(result, s)
+	}
+	slices.SortFunc(result, func(a, b *ticketStats) int {
+		return cmp.Or(
+			cmp.Compare(len(b.Tickets), len(a.Tickets)),
+			cmp.Compare(b.Commits, a.Commits),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+	return result
+}
+
+// ticketsTable lays out the ticket statistics
+func ticketsTable(stats []*ticketStats) textTable {
+	table := textTable{
+		Headers: []string{"NAME", "EMAIL", "COMMITS", "LINKED", "CHURN", "LINKED_CHURN", "TICKETS"},
+		Numeric: []bool{false, false, true, true, true, true, true},
+	}
+	for _, s := range stats {
+		table.Rows = append(table.Rows, []string{
+			s.Name,
+			s.Email,
+			strconv.Itoa(s.Commits),
+			strconv.Itoa(s.Linked),
+			strconv.Itoa(s.Churn),
+			strconv.Itoa(s.LinkedChurn),
+			strconv.Itoa(len(s.Tickets)),
+		})
+	}
+	return table
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/PerArneng/gitwho/pkg/gitwho"
+	"github.com/spf13/cobra"
+)
+
+var timelineBucket string
+var timelineFormat string
+
+// timelineCmd represents the timeline command
+var timelineCmd = &cobra.Command{
+	Use:   "timeline [file/directory]",
+	Short: "Show contributor statistics per week, month, quarter or year",
+	Long: `Timeline splits the history of a file or directory into time buckets
+and shows the commits and line changes of each contributor per bucket, so
+you can see how ownership shifted over time. Commits are bucketed by their
+author date in the author's timezone.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+		if !isValidBucket(timelineBucket) {
+			fmt.Printf("Error: Invalid bucket %s. Supported buckets: week, month, quarter, year\n", timelineBucket)
+			os.Exit(1)
+		}
+		if !isValidTableFormat(timelineFormat) {
+			fmt.Printf("Error: Invalid format %s. Supported formats: table, csv, json\n", timelineFormat)
+			os.Exit(1)
+		}
+
+		commits, err := collectCommits([]string{path}, lastTimeRange, repoPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		periods := buildTimeline(commits, timelineBucket)
+		if err := displayTable(os.Stdout, timelineFormat, timelineTable(periods), periods); err != nil {
+			fmt.Printf("Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	timelineCmd.Flags().StringVar(&timelineBucket, "bucket", "month", "Size of the time buckets (week, month, quarter, year)")
+	timelineCmd.Flags().StringVar(&timelineFormat, "format", "table", "Output format (table, csv, json)")
+	rootCmd.AddCommand(timelineCmd)
+}
+
+// timelinePeriod holds the contributor statistics of one time bucket
+type timelinePeriod struct {
+	Period       string              `json:"period"`
+	Start        time.Time           `json:"start"`
+	Commits      int                 `json:"commits"`
+	Additions    int                 `json:"additions"`
+	Deletions    int                 `json:"deletions"`
+	Contributors []contributorRecord `json:"contributors"`
+}
+
+// isValidBucket reports whether bucket is a supported time bucket size
+func isValidBucket(bucket string) bool {
+	switch bucket {
+	case "week", "month", "quarter", "year":
+		return true
+	}
+	return false
+}
+
+// bucketStart returns the first day of the bucket containing date. The
+// calendar date in date's own timezone is used, the result is in UTC.
+func bucketStart(date time.Time, bucket string) time.Time {
+	year, month, day := date.Date()
+	switch bucket {
+	case "week":
+		// Weeks start on Monday as in ISO 8601
+		offset := (int(date.Weekday()) + 6) % 7
+		return time.Date(year, month, day-offset, 0, 0, 0, 0, time.UTC)
+	case "quarter":
+		return time.Date(year, month-(month-1)%3, 1, 0, 0, 0, 0, time.UTC)
+	case "year":
+		return time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// nextBucket returns the start of the bucket following the one at start
+func nextBucket(start time.Time, bucket string) time.Time {
+	switch bucket {
+	case "week":
+		return start.AddDate(0, 0, 7)
+	case "quarter":
+		return start.AddDate(0, 3, 0)
+	case "year":
+		return start.AddDate(1, 0, 0)
+	}
+	return start.AddDate(0, 1, 0)
+}
+
+// bucketLabel names the bucket starting at start, e.g. 2025-W03, 2025-01,
+// 2025-Q1 or 2025
+func bucketLabel(start time.Time, bucket string) string {
+	switch bucket {
+	case "week":
+		year, week := start.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case "quarter":
+		return fmt.Sprintf("%d-Q%d", start.Year(), (int(start.Month())-1)/3+1)
+	case "year":
+		return strconv.Itoa(start.Year())
+	}
+	return start.Format("2006-01")
+}
+
+// buildTimeline aggregates the commits per bucket. Every bucket from the
+// first to the last commit is included, even when nobody committed in it.
+func buildTimeline(commits []*Commit, bucket string) []timelinePeriod {
+	byBucket := make(map[time.Time][]*Commit)
+	var first, last time.Time
+	for _, commit := range commits {
+		if commit.Date.IsZero() {
+			continue
+		}
+		start := bucketStart(commit.Date, bucket)
+		byBucket[start] = append(byBucket[start], commit)
+		if first.IsZero() || start.Before(first) {
+			first = start
+		}
+		if start.After(last) {
+			last = start
+		}
+	}
+	if first.IsZero() {
+		return nil
+	}
+
+	var periods []timelinePeriod
+	for start := first; !start.After(last); start = nextBucket(start, bucket) {
+		contributors := gitwho.Aggregate(byBucket[start])
+		gitwho.ComputeShares(contributors)
+		sortContributorsBy(contributors, sortField, reverseSort)
+
+		summary := gitwho.Summarize(contributors)
+		periods = append(periods, timelinePeriod{
+			Period:       bucketLabel(start, bucket),
+			Start:        start,
+			Commits:      summary.Commits,
+			Additions:    summary.Additions,
+			Deletions:    summary.Deletions,
+			Contributors: buildReportDocument(&Report{Contributors: contributors}, newRenderOptions(false)).Contributors,
+		})
+	}
+	return periods
+}
+
+// timelineTable lays out one row per period and contributor. Periods
+// without commits are left out.
+func timelineTable(periods []timelinePeriod) textTable {
+	table := textTable{
+		Headers: []string{"PERIOD", "NAME", "EMAIL", "COMMITS", "ADDED", "DELETED", "TOTAL"},
+		Numeric: []bool{false, false, false, true, true, true, true},
+	}
+	for _, period := range periods {
+		for _, record := range period.Contributors {
+			table.Rows = append(table.Rows, []string{
+				period.Period,
+				record.Name,
+				record.Email,
+				strconv.Itoa(record.Commits),
+				strconv.Itoa(record.Additions),
+				strconv.Itoa(record.Deletions),
+				strconv.Itoa(record.Total),
+			})
+		}
+	}
+	return table
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// countReviews is set by the reviews command, which counts the review
+// trailers of the commits
+var countReviews bool
+
+// readsTrailers reports whether the trailers of the commits are used, so
+// git has to extract them from every commit message
+func readsTrailers() bool {
+	return coAuthorMode != "none" || countReviews
+}
+
+// parseIdentity splits a "Name <email>" trailer value into name and email
+func parseIdentity(value string) (string, string, bool) {
+	name, email, ok := strings.Cut(strings.TrimSpace(value), "<")
+	if !ok || !strings.HasSuffix(email, ">") {
+		return "", "", false
+	}
+	return strings.TrimSpace(name), strings.TrimSuffix(email, ">"), true
+}
+
+// resolveTrailers maps the identities in the trailers of the commits
+// through the repository's .mailmap, like %aN and %aE do for the author
+func resolveTrailers(commits []*Commit, repoPath string) error {
+	var identities []string
+	seen := make(map[string]bool)
+	for _, commit := range commits {
+		for _, trailers := range [][]string{commit.CoAuthors, commit.Reviewers, commit.SignOffs} {
+			for _, value := range trailers {
+				if _, _, ok := parseIdentity(value); ok && !seen[value] {
+					seen[value] = true
+					identities = append(identities, value)
+				}
+			}
+		}
+	}
+	if len(identities) == 0 {
+		return nil
+	}
+	if usesGoGit() {
+		return goGitResolveTrailers(commits, repoPath)
+	}
+
+	input := strings.Join(identities, "\n") + "\n"
+	output, err := runGitInput(input, nil, "-C", repoPath, "check-mailmap", "--stdin")
+	if err != nil {
+		return fmt.Errorf("Error resolving trailer identities: %v", err)
+	}
+	mapped := make(map[string]string)
+	for i, line := range strings.Split(strings.TrimSuffix(output, "\n"), "\n") {
+		if i < len(identities) {
+			mapped[identities[i]] = line
+		}
+	}
+	for _, commit := range commits {
+		for _, trailers := range [][]string{commit.CoAuthors, commit.Reviewers, commit.SignOffs} {
+			for i, value := range trailers {
+				if resolved, ok := mapped[value]; ok {
+					trailers[i] = resolved
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var treeDepth int
+var treeFormat string
+
+// treeCmd represents the tree command
+var treeCmd = &cobra.Command{
+	Use:   "tree [directory]",
+	Short: "Show ownership per subdirectory as a tree",
+	Long: `Tree aggregates the statistics of a directory and its subdirectories down
+to --depth levels and prints them as an indented tree with the dominant
+owner of each directory and their share of its changes.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := "."
+		if len(args) == 1 {
+			dir = args[0]
+		}
+		if !isValidTableFormat(treeFormat) {
+			fmt.Printf("Error: Invalid format %s. Supported formats: table, csv, json\n", treeFormat)
+			os.Exit(1)
+		}
+		if treeDepth < 0 {
+			fmt.Println("Error: --depth must not be negative")
+			os.Exit(1)
+		}
+
+		commits, err := collectCommits([]string{dir}, lastTimeRange, repoPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		repo := repoPath
+		if repo == "" {
+			if repo, err = findRepoForPath(dir); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+		base, err := getRelativePath(dir, repo)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		nodes := buildTree(commits, base, treeDepth)
+		if err := displayTable(os.Stdout, treeFormat, treeTable(nodes, treeFormat == "table"), nodes); err != nil {
+			fmt.Printf("Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	treeCmd.Flags().IntVar(&treeDepth, "depth", 2, "Number of subdirectory levels to show")
+	treeCmd.Flags().StringVar(&treeFormat, "format", "table", "Output format (table, csv, json)")
+	rootCmd.AddCommand(treeCmd)
+}
+
+// treeNode is a directory in the ownership tree
+type treeNode struct {
+	ownershipStats
+	Depth int `json:"depth"`
+}
+
+// treeKey returns the directory of file that is depth levels below base,
+// or "" when file is not nested that deep
+func treeKey(file string, base string, depth int) string {
+	rel := file
+	if base != "." {
+		rel = strings.TrimPrefix(file, base+"/")
+	}
+	dirs := strings.Split(path.Dir(rel), "/")
+	if path.Dir(rel) == "." {
+		dirs = nil
+	}
+	if len(dirs) < depth {
+		return ""
+	}
+	return path.Join(append([]string{base}, dirs[:depth]...)...)
+}
+
+// buildTree aggregates the ownership of base and its subdirectories down to
+// maxDepth levels. Nodes are returned in depth-first order.
+func buildTree(commits []*Commit, base string, maxDepth int) []treeNode {
+	var nodes []treeNode
+	for depth := 0; depth <= maxDepth; depth++ {
+		groups := splitCommits(commits, func(file string) string {
+			return treeKey(file, base, depth)
+		})
+		for dir, dirCommits := range groups {
+			if dir == "" {
+				continue
+			}
+			nodes = append(nodes, treeNode{buildOwnership(dir, dirCommits), depth})
+		}
+	}
+
+	// Sorting by path segments puts every directory right before its children
+	slices.SortFunc(nodes, func(a, b treeNode) int {
+		return slices.Compare(strings.Split(a.Path, "/"), strings.Split(b.Path, "/"))
+	})
+	return nodes
+}
+
+// treeTable lays out the tree. When indent is set, subdirectories are shown
+// by name indented below their parent instead of by full path.
+func treeTable(nodes []treeNode, indent bool) textTable {
+	stats := make([]ownershipStats, len(nodes))
+	for i, node := range nodes {
+		stats[i] = node.ownershipStats
+		if indent && node.Depth > 0 {
+			stats[i].Path = strings.Repeat("  ", node.Depth) + path.Base(node.Path)
+		}
+		stats[i].Path += "/"
+	}
+	return ownershipTable("DIRECTORY", stats)
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// conventionalTypes are the commit types of the Conventional Commits
+// specification and its common extensions, in display order
+var conventionalTypes = []string{"feat", "fix", "docs", "style", "refactor", "perf", "test", "build", "ci", "chore", "revert"}
+
+// otherType groups the commits without a known conventional type
+const otherType = "other"
+
+// conventionalPattern matches a subject such as "feat(parser)!: add x"
+var conventionalPattern = regexp.MustCompile(`^([A-Za-z]+)(\([^)]*\))?!?:\s`)
+
+var typesFormat string
+
+// typesCmd represents the types command
+var typesCmd = &cobra.Command{
+	Use:   "types [file/directory]",
+	Short: "Break down each contributor's commits by conventional-commit type",
+	Long: `Types classifies the subjects of the commits that touched a file or
+directory by their Conventional Commits type (feat, fix, docs, chore, ...)
+and counts the commits of each type per contributor. Commits without a
+known type are counted as other.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		path := "."
+		if len(args) == 1 {
+			path = args[0]
+		}
+		if !isValidTableFormat(typesFormat) {
+			fmt.Printf("Error: Invalid format %s. Supported formats: table, csv, json\n", typesFormat)
+			os.Exit(1)
+		}
+
+		commits, err := collectCommits([]string{path}, lastTimeRange, repoPath)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		breakdowns := buildTypeBreakdown(commits)
+		if err := displayTable(os.Stdout, typesFormat, typesTable(breakdowns), breakdowns); err != nil {
+			fmt.Printf("Error writing output: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	typesCmd.Flags().StringVar(&typesFormat, "format", "table", "Output format (table, csv, json)")
+	rootCmd.AddCommand(typesCmd)
+}
+
+// typeBreakdown holds the number of commits per type of one contributor
+type typeBreakdown struct {
+	Name    string         `json:"name"`
+	Email   string         `json:"email"`
+	Commits int            `json:"commits"`
+	Types   map[string]int `json:"types"`
+}
+
+// commitType returns the conventional-commit type of a subject, or
+// otherType when it has none or an unknown one
+func commitType(subject string) string {
+	match := conventionalPattern.FindStringSubmatch(subject)
+	if match == nil {
+		return otherType
+	}
+	kind := strings.ToLower(match[1])
+	if !slices.Contains(conventionalTypes, kind) {
+		return otherType
+	}
+	return kind
+}
+
+// buildTypeBreakdown counts the commits of each type per contributor, the
+// contributors with the most commits first
+func buildTypeBreakdown(commits []*Commit) []*typeBreakdown {
+	stats := make(map[string]*typeBreakdown)
+	for _, commit := range commits {
+		if len(commit.Files) == 0 {
+			continue
+		}
+		key := commit.Name + "|" + commit.Email
+		if stats[key] == nil {
+			stats[key] = &typeBreakdown{Name: commit.Name, Email: commit.Email, Types: make(map[string]int)}
+		}
+		stats[key].Commits++
+		stats[key].Types[commitType(commit.Subject)]++
+	}
+
+	breakdowns := make([]*typeBreakdown, 0, len(stats))
+	for _, b := range stats {
+		breakdowns = append(breakdowns, b)
+	}
+	slices.SortFunc(breakdowns, func(a, b *typeBreakdown) int {
+		return cmp.Or(
+			cmp.Compare(b.Commits, a.Commits),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+	return breakdowns
+}
+
+// typesTable lays out the breakdown with a column for each type that
+// occurs, followed by other
+func typesTable(breakdowns []*typeBreakdown) textTable {
+	var kinds []string
+	for _, kind := range append(slices.Clone(conventionalTypes), otherType) {
+		if slices.ContainsFunc(breakdowns, func(b *typeBreakdown) bool { return b.Types[kind] > 0 }) {
+			kinds = append(kinds, kind)
+		}
+	}
+
+	table := textTable{
+		Headers: []string{"NAME", "EMAIL", "COMMITS"},
+		Numeric: []bool{false, false, true},
+	}
+	for _, kind := range kinds {
+		table.Headers = append(table.Headers, strings.ToUpper(kind))
+		table.Numeric = append(table.Numeric, true)
+	}
+	for _, b := range breakdowns {
+		row := []string{b.Name, b.Email, strconv.Itoa(b.Commits)}
+		for _, kind := range kinds {
+			row = append(row, strconv.Itoa(b.Types[kind]))
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return table
+}
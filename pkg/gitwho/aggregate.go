@@ -0,0 +1,229 @@
+package gitwho
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Aggregate collects per-contributor statistics from parsed commits,
+// sorted by total changes (additions + deletions)
+func Aggregate(commits []*Commit) []*Contributor {
+	stats := make(map[string]*Contributor)
+
+	for _, commit := range commits {
+		var contributor *Contributor
+		size := 0
+		for _, change := range commit.Files {
+			if contributor == nil {
+				contributor = contributorFor(commit, stats)
+				contributor.Commits++
+				if commit.Signed {
+					contributor.SignedCommits++
+					contributor.SignedHashes = append(contributor.SignedHashes, commit.Hash)
+				}
+			}
+
+			// Binary files have no line counts
+			if change.Binary {
+				contributor.BinaryFiles[change.Path]++
+				continue
+			}
+			contributor.Additions += change.Additions
+			contributor.Deletions += change.Deletions
+			contributor.Files[change.Path] += change.Additions + change.Deletions
+			size += change.Additions + change.Deletions
+		}
+
+		if contributor != nil {
+			contributor.CommitSizes = append(contributor.CommitSizes, size)
+			contributor.CommitDates = append(contributor.CommitDates, commit.Date)
+			contributor.CommitHashes = append(contributor.CommitHashes, commit.Hash)
+			contributor.RecordCommitDate(commit.Date)
+		}
+	}
+
+	// Convert map to slice and sort
+	return sortContributors(stats)
+}
+
+// RecordCommitDate marks the day of date as active for the contributor and
+// widens their first/last commit range to include it
+func (c *Contributor) RecordCommitDate(date time.Time) {
+	if date.IsZero() {
+		return
+	}
+	c.Days[date.Format("2006-01-02")] = true
+	if c.FirstCommit.IsZero() || date.Before(c.FirstCommit) {
+		c.FirstCommit = date
+	}
+	if date.After(c.LastCommit) {
+		c.LastCommit = date
+	}
+}
+
+// AverageCommitSize returns the mean number of lines changed per commit
+func (c *Contributor) AverageCommitSize() float64 {
+	if len(c.CommitSizes) == 0 {
+		return 0
+	}
+	return float64(c.Additions+c.Deletions) / float64(len(c.CommitSizes))
+}
+
+// MedianCommitSize returns the median number of lines changed per commit
+func (c *Contributor) MedianCommitSize() float64 {
+	return median(c.CommitSizes)
+}
+
+// Summarize computes the totals over all contributors. A commit credited
+// to several of them counts once in the commit totals and sizes, with the
+// most lines any of them was credited with.
+func Summarize(contributors []*Contributor) Summary {
+	summary := Summary{Contributors: len(contributors)}
+	files := make(map[string]bool)
+	binaries := make(map[string]bool)
+	days := make(map[string]bool)
+	sizes, repeated := distinctCommits(contributors)
+	signed := make(map[string]bool)
+	for _, contributor := range contributors {
+		for _, hash := range contributor.SignedHashes {
+			if hash == "" || !signed[hash] {
+				summary.SignedCommits++
+			}
+			signed[hash] = true
+		}
+		for file := range contributor.Files {
+			files[file] = true
+		}
+		for file := range contributor.BinaryFiles {
+			binaries[file] = true
+		}
+		for day := range contributor.Days {
+			days[day] = true
+		}
+		summary.Commits += contributor.Commits
+		summary.Additions += contributor.Additions
+		summary.Deletions += contributor.Deletions
+		summary.CommitShare += contributor.CommitShare
+		summary.ChangeShare += contributor.ChangeShare
+		if !contributor.FirstCommit.IsZero() && (summary.FirstCommit.IsZero() || contributor.FirstCommit.Before(summary.FirstCommit)) {
+			summary.FirstCommit = contributor.FirstCommit
+		}
+		if contributor.LastCommit.After(summary.LastCommit) {
+			summary.LastCommit = contributor.LastCommit
+		}
+	}
+	summary.Commits -= repeated
+	summary.Files = len(files)
+	summary.BinaryFiles = len(binaries)
+	summary.ActiveDays = len(days)
+	if len(sizes) > 0 {
+		total := 0
+		for _, size := range sizes {
+			total += size
+		}
+		summary.AvgCommitSize = float64(total) / float64(len(sizes))
+		summary.MedianCommitSize = median(sizes)
+	}
+	summary.Gini = giniCoefficient(contributorTotals(contributors))
+	summary.HHI = herfindahlIndex(contributorTotals(contributors))
+	return summary
+}
+
+// distinctCommits returns the size of each commit of the contributors,
+// counting a commit credited to several of them once with its largest
+// size, and how many credits repeated a commit. Commits without a hash
+// are each counted.
+func distinctCommits(contributors []*Contributor) (sizes []int, repeated int) {
+	index := make(map[string]int)
+	for _, contributor := range contributors {
+		for i, size := range contributor.CommitSizes {
+			hash := ""
+			if i < len(contributor.CommitHashes) {
+				hash = contributor.CommitHashes[i]
+			}
+			if j, ok := index[hash]; ok && hash != "" {
+				sizes[j] = max(sizes[j], size)
+				repeated++
+				continue
+			}
+			index[hash] = len(sizes)
+			sizes = append(sizes, size)
+		}
+	}
+	return sizes, repeated
+}
+
+// median returns the median of the values without modifying them
+func median(values []int) float64 {
+	n := len(values)
+	if n == 0 {
+		return 0
+	}
+
+	sizes := slices.Clone(values)
+	slices.Sort(sizes)
+	if n%2 == 1 {
+		return float64(sizes[n/2])
+	}
+	return float64(sizes[n/2-1]+sizes[n/2]) / 2
+}
+
+// ComputeShares sets each contributor's percentage of all commits and of
+// all changed lines. A commit credited to several contributors counts for
+// each of them, but once in all commits.
+func ComputeShares(contributors []*Contributor) {
+	_, repeated := distinctCommits(contributors)
+	commits, changes := -repeated, 0
+	for _, contributor := range contributors {
+		commits += contributor.Commits
+		changes += contributor.Additions + contributor.Deletions
+	}
+
+	for _, contributor := range contributors {
+		if commits > 0 {
+			contributor.CommitShare = float64(contributor.Commits) * 100 / float64(commits)
+		}
+		if changes > 0 {
+			contributor.ChangeShare = float64(contributor.Additions+contributor.Deletions) * 100 / float64(changes)
+		}
+	}
+}
+
+// contributorFor returns the contributor entry for the author of commit,
+// creating it if needed
+func contributorFor(commit *Commit, stats map[string]*Contributor) *Contributor {
+	key := fmt.Sprintf("%s|%s", commit.Name, commit.Email)
+	contributor, exists := stats[key]
+	if !exists {
+		contributor = &Contributor{
+			Name:        commit.Name,
+			Email:       commit.Email,
+			Files:       make(map[string]int),
+			BinaryFiles: make(map[string]int),
+			Days:        make(map[string]bool),
+		}
+		stats[key] = contributor
+	}
+	return contributor
+}
+
+// sortContributors sorts contributors by total changes (additions +
+// deletions). Ties are broken by name and email so the order is stable
+// between runs.
+func sortContributors(stats map[string]*Contributor) []*Contributor {
+	contributors := make([]*Contributor, 0, len(stats))
+	for _, contributor := range stats {
+		contributors = append(contributors, contributor)
+	}
+
+	slices.SortFunc(contributors, func(a, b *Contributor) int {
+		return cmp.Or(
+			cmp.Compare(b.Additions+b.Deletions, a.Additions+a.Deletions),
+			cmp.Compare(a.Name, b.Name),
+			cmp.Compare(a.Email, b.Email),
+		)
+	})
+	return contributors
+}
@@ -0,0 +1,70 @@
+package gitwho
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAggregate(t *testing.T) {
+	day := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	commits := []*Commit{
+		{Name: "Jane", Email: "jane@example.com", Date: day.AddDate(0, 0, 1), Files: []FileChange{
+			{Path: "a.go", Additions: 10, Deletions: 2},
+			{Path: "logo.png", Binary: true},
+		}},
+		{Name: "John", Email: "john@example.com", Date: day, Files: []FileChange{{Path: "b.go", Additions: 3}}},
+		{Name: "Jane", Email: "jane@example.com", Date: day, Files: []FileChange{{Path: "a.go", Additions: 4}}},
+		// Commits without file changes are not counted
+		{Name: "Jane", Email: "jane@example.com", Date: day},
+	}
+
+	contributors := Aggregate(commits)
+	ComputeShares(contributors)
+	if len(contributors) != 2 || contributors[0].Name != "Jane" {
+		t.Fatalf("got contributors %+v, want Jane first", contributors)
+	}
+	jane := contributors[0]
+	if jane.Commits != 2 || jane.Additions != 14 || jane.Deletions != 2 || jane.Files["a.go"] != 16 {
+		t.Errorf("got %+v", jane)
+	}
+	if jane.BinaryFiles["logo.png"] != 1 || len(jane.Days) != 2 || !jane.FirstCommit.Equal(day) {
+		t.Errorf("got binaries %v, days %v and first commit %v", jane.BinaryFiles, jane.Days, jane.FirstCommit)
+	}
+	if jane.CommitShare != 200.0/3 || jane.ChangeShare != 1600.0/19 {
+		t.Errorf("got shares %v and %v", jane.CommitShare, jane.ChangeShare)
+	}
+
+	summary := Summarize(contributors)
+	if summary.Contributors != 2 || summary.Commits != 3 || summary.Files != 2 || summary.BinaryFiles != 1 {
+		t.Errorf("got summary %+v", summary)
+	}
+	if summary.Additions != 17 || summary.Deletions != 2 || summary.MedianCommitSize != 4 || summary.ActiveDays != 2 {
+		t.Errorf("got summary %+v", summary)
+	}
+}
+
+func TestSummarizeCountsCreditedCommitsOnce(t *testing.T) {
+	day := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	commits := []*Commit{
+		{Hash: "c1", Name: "Jane", Email: "jane@example.com", Date: day, Signed: true, Files: []FileChange{{Path: "a.go", Additions: 10}}},
+		// The same commit credited to its committer as well
+		{Hash: "c1", Name: "John", Email: "john@example.com", Date: day, Signed: true, Files: []FileChange{{Path: "a.go", Additions: 10}}},
+		{Hash: "c2", Name: "John", Email: "john@example.com", Date: day, Files: []FileChange{{Path: "b.go", Additions: 2}}},
+	}
+
+	contributors := Aggregate(commits)
+	ComputeShares(contributors)
+	for _, contributor := range contributors {
+		if want := float64(contributor.Commits) * 100 / 2; contributor.CommitShare != want {
+			t.Errorf("%s has a commit share of %v, want %v of the 2 commits", contributor.Name, contributor.CommitShare, want)
+		}
+	}
+
+	summary := Summarize(contributors)
+	if summary.Commits != 2 || summary.SignedCommits != 1 {
+		t.Errorf("got %d commits, %d signed, want 2 commits, 1 signed", summary.Commits, summary.SignedCommits)
+	}
+	if summary.AvgCommitSize != 6 || summary.MedianCommitSize != 6 {
+		t.Errorf("got average %v and median %v, want the sizes of the 2 commits", summary.AvgCommitSize, summary.MedianCommitSize)
+	}
+}
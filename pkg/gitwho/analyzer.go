@@ -0,0 +1,241 @@
+package gitwho
+
+import (
+	"context"
+	"iter"
+	"strings"
+	"time"
+)
+
+// Analyzer computes the contributor statistics of a repository's history
+type Analyzer struct {
+	query    Query
+	backend  Backend
+	excludes []string
+	matcher  *PathMatcher
+}
+
+// Option configures an Analyzer
+type Option func(*Analyzer)
+
+// WithRepo analyzes the repository containing dir instead of the one of
+// the current directory
+func WithRepo(dir string) Option {
+	return func(a *Analyzer) { a.query.Repo = dir }
+}
+
+// WithPaths only counts the changes to the files and directories, relative
+// to the repository directory
+func WithPaths(paths ...string) Option {
+	return func(a *Analyzer) { a.query.Paths = append(a.query.Paths, paths...) }
+}
+
+// WithRevisions analyzes the history of the revisions and ranges like
+// main..topic instead of HEAD
+func WithRevisions(revisions ...string) Option {
+	return func(a *Analyzer) { a.query.Revisions = append(a.query.Revisions, revisions...) }
+}
+
+// WithTimeRange only counts the commits made at or after since and before
+// until. A zero time leaves that end open.
+func WithTimeRange(since time.Time, until time.Time) Option {
+	return func(a *Analyzer) { a.query.Since, a.query.Until = since, until }
+}
+
+// WithExcludes leaves the files whose paths, relative to the repository
+// root, match the gitignore-style patterns out of the statistics. As in
+// .gitignore, a pattern prefixed with "!" re-includes files an earlier
+// pattern excluded.
+func WithExcludes(patterns ...string) Option {
+	return func(a *Analyzer) { a.excludes = append(a.excludes, patterns...) }
+}
+
+// WithMailmap maps the identities of the commits through the mailmap file,
+// in addition to the repository's .mailmap
+func WithMailmap(file string) Option {
+	return func(a *Analyzer) { a.query.Mailmap = file }
+}
+
+// WithBackend reads the history with backend instead of the git binary
+func WithBackend(backend Backend) Option {
+	return func(a *Analyzer) { a.backend = backend }
+}
+
+// WithoutMerges skips merge commits
+func WithoutMerges() Option {
+	return func(a *Analyzer) { a.query.NoMerges = true }
+}
+
+// WithFirstParent follows only the first parent of merges and counts the
+// changes the merges brought in
+func WithFirstParent() Option {
+	return func(a *Analyzer) { a.query.FirstParent = true }
+}
+
+// WithTrailers reads the Co-authored-by, Reviewed-by and Signed-off-by
+// trailers of the commits into their CoAuthors, Reviewers and SignOffs
+func WithTrailers() Option {
+	return func(a *Analyzer) { a.query.Trailers = true }
+}
+
+// WithSignatures checks the signatures of the commits to count each
+// contributor's SignedCommits, which is slow on large histories
+func WithSignatures() Option {
+	return func(a *Analyzer) { a.query.Signed = true }
+}
+
+// WithIgnoreWhitespace ignores changes to whitespace when counting the
+// changed lines, so reformatting commits count little
+func WithIgnoreWhitespace() Option {
+	return func(a *Analyzer) { a.query.IgnoreWhitespace = true }
+}
+
+// WithRenames detects renames of files whose content is at least
+// similarity percent the same, instead of 50 percent
+func WithRenames(similarity int) Option {
+	return func(a *Analyzer) { a.query.RenameSimilarity = similarity }
+}
+
+// WithCopies detects files copied from others whose content is at least
+// similarity percent the same
+func WithCopies(similarity int) Option {
+	return func(a *Analyzer) { a.query.CopySimilarity = similarity }
+}
+
+// WithFollow follows the history of a single file, the only path, across
+// renames
+func WithFollow() Option {
+	return func(a *Analyzer) { a.query.Follow = true }
+}
+
+// WithGitArgs passes further arguments to git log, such as
+// --diff-filter=A. Only the git backend understands them.
+func WithGitArgs(args ...string) Option {
+	return func(a *Analyzer) { a.query.GitArgs = append(a.query.GitArgs, args...) }
+}
+
+// NewAnalyzer returns an Analyzer reading the history of the repository of
+// the current directory with git, configured by the options
+func NewAnalyzer(options ...Option) (*Analyzer, error) {
+	a := &Analyzer{backend: GitBackend{}}
+	for _, option := range options {
+		option(a)
+	}
+	matcher, err := NewPathMatcher(a.excludes)
+	if err != nil {
+		return nil, err
+	}
+	a.matcher = matcher
+	return a, nil
+}
+
+// Analyze returns the statistics of the contributors of the history the
+// options select, sorted by total changes
+func Analyze(ctx context.Context, options ...Option) (*Report, error) {
+	a, err := NewAnalyzer(options...)
+	if err != nil {
+		return nil, err
+	}
+	return a.Analyze(ctx)
+}
+
+// Analyze returns the statistics of the contributors, sorted by total
+// changes
+func (a *Analyzer) Analyze(ctx context.Context) (*Report, error) {
+	commits, err := a.Commits(ctx)
+	if err != nil {
+		return nil, err
+	}
+	contributors := Aggregate(commits)
+	ComputeShares(contributors)
+
+	path := "."
+	if len(a.query.Paths) > 0 {
+		path = strings.Join(a.query.Paths, " ")
+	}
+	return &Report{Path: path, Contributors: contributors, Summary: Summarize(contributors)}, nil
+}
+
+// Commits returns the commits that touched the paths, newest first,
+// without the changes to excluded files
+func (a *Analyzer) Commits(ctx context.Context) ([]*Commit, error) {
+	var commits []*Commit
+	for commit, err := range a.Stream(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+// Stream yields the commits that touched the paths, newest first, without
+// the changes to excluded files. With a StreamingBackend, such as the
+// default, each commit is yielded as soon as it was read. Stopping the
+// iteration stops reading the history.
+func (a *Analyzer) Stream(ctx context.Context) iter.Seq2[*Commit, error] {
+	return func(yield func(*Commit, error) bool) {
+		for commit, err := range a.read(ctx) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if a.matcher.Len() > 0 {
+				var kept []FileChange
+				for _, change := range commit.Files {
+					if !a.matcher.Match(change.Path) {
+						kept = append(kept, change)
+					}
+				}
+				commit.Files = kept
+			}
+			if !yield(commit, nil) {
+				return
+			}
+		}
+	}
+}
+
+// FileEvent is the change of one file in a commit
+type FileEvent struct {
+	Commit *Commit
+	FileChange
+}
+
+// Files yields the changes to the files that are not excluded, commit by
+// commit as Stream yields them
+func (a *Analyzer) Files(ctx context.Context) iter.Seq2[FileEvent, error] {
+	return func(yield func(FileEvent, error) bool) {
+		for commit, err := range a.Stream(ctx) {
+			if err != nil {
+				yield(FileEvent{}, err)
+				return
+			}
+			for _, change := range commit.Files {
+				if !yield(FileEvent{Commit: commit, FileChange: change}, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// read returns the commits of the backend, all at once unless it can
+// stream them
+func (a *Analyzer) read(ctx context.Context) iter.Seq2[*Commit, error] {
+	if streaming, ok := a.backend.(StreamingBackend); ok {
+		return streaming.Stream(ctx, a.query)
+	}
+	return func(yield func(*Commit, error) bool) {
+		commits, err := a.backend.Commits(ctx, a.query)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for _, commit := range commits {
+			if !yield(commit, nil) {
+				return
+			}
+		}
+	}
+}
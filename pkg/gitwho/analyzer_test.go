@@ -0,0 +1,181 @@
+package gitwho
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// testRepo creates a repository in a temporary directory that ignores the
+// user's and the system's git configuration, with the commits of
+// testHistory
+func testRepo(t *testing.T) string {
+	t.Helper()
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	dir := t.TempDir()
+	git := func(env []string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), env...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	git(nil, "init", "-q", "-b", "main")
+
+	for i, c := range testHistory {
+		for name, contents := range c.files {
+			path := filepath.Join(dir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		date := testDate(i).Format(time.RFC3339)
+		git(nil, "add", "-A")
+		git([]string{"GIT_COMMITTER_DATE=" + date}, "-c", "user.name="+c.author, "-c", "user.email="+c.author+"@example.com",
+			"commit", "-q", "--date", date, "-m", "Commit")
+	}
+	return dir
+}
+
+// testHistory are the commits of testRepo, oldest first
+var testHistory = []struct {
+	author string
+	files  map[string]string
+}{
+	{"jane", map[string]string{"src/main.go": "a\nb\nc\n", "vendor/lib.go": "x\n"}},
+	{"john", map[string]string{"docs/guide.md": "one\ntwo\n"}},
+	{"jane", map[string]string{"src/main.go": "a\nB\nc\nd\n"}},
+}
+
+// testDate is the date of the ith commit of testHistory
+func testDate(i int) time.Time {
+	return time.Date(2024, 1, 1+i, 12, 0, 0, 0, time.UTC)
+}
+
+func TestAnalyze(t *testing.T) {
+	dir := testRepo(t)
+	report, err := Analyze(context.Background(), WithRepo(dir), WithPaths("src", "vendor"), WithExcludes("vendor/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Path != "src vendor" || len(report.Contributors) != 1 {
+		t.Fatalf("got report of %s with %d contributors, want src vendor with 1", report.Path, len(report.Contributors))
+	}
+	jane := report.Contributors[0]
+	if jane.Name != "jane" || jane.Commits != 2 || jane.Additions != 5 || jane.Deletions != 1 {
+		t.Errorf("got %+v", jane)
+	}
+	if _, ok := jane.Files["vendor/lib.go"]; ok {
+		t.Errorf("excluded file counted: %v", jane.Files)
+	}
+	if report.Summary.Commits != 2 || report.Summary.Files != 1 {
+		t.Errorf("got summary %+v", report.Summary)
+	}
+}
+
+func TestAnalyzeTimeRange(t *testing.T) {
+	dir := testRepo(t)
+	commits, err := mustAnalyzer(t, WithRepo(dir), WithTimeRange(testDate(1), testDate(2))).Commits(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 1 || commits[0].Name != "john" || !commits[0].Date.Equal(testDate(1)) {
+		t.Errorf("got commits %+v, want only john's", commits)
+	}
+}
+
+func TestAnalyzerStreamStops(t *testing.T) {
+	dir := testRepo(t)
+	var names []string
+	for commit, err := range mustAnalyzer(t, WithRepo(dir)).Stream(context.Background()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, commit.Name)
+		break
+	}
+	// Commits are listed newest first
+	if !slices.Equal(names, []string{"jane"}) {
+		t.Errorf("got %v", names)
+	}
+}
+
+func TestAnalyzeCancelled(t *testing.T) {
+	dir := testRepo(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Analyze(ctx, WithRepo(dir)); err != context.Canceled {
+		t.Errorf("got error %v, want %v", err, context.Canceled)
+	}
+}
+
+// fakeBackend returns its commits and remembers the query it was given
+type fakeBackend struct {
+	commits []*Commit
+	query   Query
+}
+
+func (b *fakeBackend) Commits(ctx context.Context, query Query) ([]*Commit, error) {
+	b.query = query
+	return b.commits, nil
+}
+
+func TestAnalyzeWithBackend(t *testing.T) {
+	backend := &fakeBackend{commits: []*Commit{
+		{Name: "Jane", Email: "jane@example.com", Files: []FileChange{{Path: "a.go", Additions: 2}, {Path: "gen/b.go", Additions: 9}}},
+	}}
+	report, err := Analyze(context.Background(), WithBackend(backend), WithPaths("."), WithoutMerges(), WithRenames(70), WithExcludes("gen/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !backend.query.NoMerges || backend.query.RenameSimilarity != 70 || !slices.Equal(backend.query.Paths, []string{"."}) {
+		t.Errorf("got query %+v", backend.query)
+	}
+	if len(report.Contributors) != 1 || report.Contributors[0].Additions != 2 {
+		t.Errorf("got contributors %+v, want Jane without the excluded file", report.Contributors)
+	}
+}
+
+func TestLogArgs(t *testing.T) {
+	query := Query{
+		Repo:             "repo",
+		Paths:            []string{"src"},
+		Revisions:        []string{"main"},
+		NoMerges:         true,
+		IgnoreWhitespace: true,
+		RenameSimilarity: 70,
+		CopySimilarity:   60,
+		Follow:           true,
+		Since:            time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		GitArgs:          []string{"--diff-filter=A"},
+	}
+	args, err := LogArgs(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"-C", "repo", "log", "--format=" + LogFormat(LogFields{}), "--numstat",
+		"--no-merges", "--ignore-all-space", "-M70%", "-C60%", "--find-copies-harder", "--follow",
+		"--since=2024-01-01T00:00:00Z", "--diff-filter=A", "main", "--", "src"}
+	if !slices.Equal(args, want) {
+		t.Errorf("got %q\nwant %q", args, want)
+	}
+}
+
+// mustAnalyzer returns the analyzer of the options or fails the test
+func mustAnalyzer(t *testing.T, options ...Option) *Analyzer {
+	t.Helper()
+	a, err := NewAnalyzer(options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
@@ -0,0 +1,173 @@
+package gitwho
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"iter"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Query selects the history a Backend reads
+type Query struct {
+	Repo      string   // directory in the repository, the current directory when empty
+	Paths     []string // files and directories relative to Repo, all of them when empty
+	Revisions []string // revisions and ranges like main..topic, HEAD when empty
+
+	Since time.Time // only commits made at or after Since, unless zero
+	Until time.Time // only commits made before Until, unless zero
+
+	NoMerges    bool   // skip merge commits
+	FirstParent bool   // follow only the first parent of merges, counting their changes
+	Trailers    bool   // read the commits' CoAuthors, Reviewers and SignOffs
+	Signed      bool   // check the commits' signatures for SignedCommits
+	Mailmap     string // mailmap file mapping identities besides the repository's .mailmap
+
+	IgnoreWhitespace bool // ignore whitespace when counting changed lines
+	RenameSimilarity int  // minimum similarity percentage of renames, 50 when 0
+	CopySimilarity   int  // minimum similarity percentage of copies, only detected when above 0
+	Follow           bool // follow the history of the only path, a file, across renames
+
+	// GitArgs are further git log arguments, which only the git binary
+	// understands
+	GitArgs []string
+}
+
+// Backend reads the commits of a repository's history, newest first, with
+// the identities resolved through the mailmap
+type Backend interface {
+	Commits(ctx context.Context, query Query) ([]*Commit, error)
+}
+
+// StreamingBackend is a Backend that hands out the commits while it is
+// still reading the history. Stream stops reading when the iteration is
+// stopped.
+type StreamingBackend interface {
+	Backend
+	Stream(ctx context.Context, query Query) iter.Seq2[*Commit, error]
+}
+
+// GitBackend runs git log and parses its output as git writes it. It is
+// the default Backend, and the only one this package provides.
+type GitBackend struct{}
+
+func (b GitBackend) Commits(ctx context.Context, query Query) ([]*Commit, error) {
+	var commits []*Commit
+	for commit, err := range b.Stream(ctx, query) {
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+func (GitBackend) Stream(ctx context.Context, query Query) iter.Seq2[*Commit, error] {
+	return func(yield func(*Commit, error) bool) {
+		args, err := LogArgs(query)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+
+		// git is stopped when the iteration is
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, "git", args...)
+		// Processes git started may hold on to its output after it was killed
+		cmd.WaitDelay = time.Second
+		cmd.Stderr = &stderr
+		stdout, err := cmd.StdoutPipe()
+		if err == nil {
+			err = cmd.Start()
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				yield(nil, ctx.Err())
+				return
+			}
+			yield(nil, fmt.Errorf("git log: %v", err))
+			return
+		}
+
+		stopped := false
+		parseErr := parseLog(stdout, func(commit *Commit) bool {
+			stopped = !yield(commit, nil)
+			return !stopped
+		})
+		if stopped || parseErr != nil {
+			// git may still be writing output nobody reads
+			cancel()
+			cmd.Wait()
+			if parseErr != nil {
+				yield(nil, fmt.Errorf("git log: %v", parseErr))
+			}
+			return
+		}
+		if err := cmd.Wait(); err != nil {
+			if ctx.Err() != nil {
+				yield(nil, ctx.Err())
+				return
+			}
+			if message := strings.TrimSpace(stderr.String()); message != "" {
+				err = fmt.Errorf("%v: %s", err, message)
+			}
+			yield(nil, fmt.Errorf("git log: %v", err))
+		}
+	}
+}
+
+// LogArgs returns the arguments of the git log GitBackend runs for the
+// query, which lists the history in LogFormat
+func LogArgs(query Query) ([]string, error) {
+	repo := query.Repo
+	if repo == "" {
+		repo = "."
+	}
+	args := []string{"-C", repo}
+	if query.Mailmap != "" {
+		// git resolves the file against the repository, not the caller
+		mailmap, err := filepath.Abs(query.Mailmap)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "-c", "mailmap.file="+mailmap)
+	}
+	args = append(args, "log", "--format="+LogFormat(LogFields{Trailers: query.Trailers, Signed: query.Signed}), "--numstat")
+	if query.NoMerges {
+		args = append(args, "--no-merges")
+	}
+	if query.IgnoreWhitespace {
+		args = append(args, "--ignore-all-space")
+	}
+	if query.RenameSimilarity > 0 {
+		args = append(args, fmt.Sprintf("-M%d%%", query.RenameSimilarity))
+	}
+	if query.CopySimilarity > 0 {
+		// Copies are usually made from files the commit did not touch
+		args = append(args, fmt.Sprintf("-C%d%%", query.CopySimilarity), "--find-copies-harder")
+	}
+	if query.Follow {
+		args = append(args, "--follow")
+	}
+	if query.FirstParent {
+		// Merge commits are diffed against their first parent, so they
+		// carry the changes they brought in
+		args = append(args, "--first-parent", "--diff-merges=first-parent")
+	}
+	if !query.Since.IsZero() {
+		args = append(args, "--since="+query.Since.Format(time.RFC3339))
+	}
+	if !query.Until.IsZero() {
+		// --until includes commits made at the second it names
+		args = append(args, "--until="+query.Until.Add(-time.Second).Format(time.RFC3339))
+	}
+	args = append(args, query.GitArgs...)
+	args = append(args, query.Revisions...)
+	args = append(args, "--")
+	return append(args, query.Paths...), nil
+}
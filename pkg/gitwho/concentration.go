@@ -0,0 +1,51 @@
+package gitwho
+
+import "slices"
+
+// giniCoefficient measures how unevenly values are distributed, from 0 when
+// everybody contributed the same to close to 1 when one contributor made
+// nearly everything
+func giniCoefficient(values []int) float64 {
+	n := len(values)
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	sum, weighted := 0, 0
+	for i, value := range sorted {
+		sum += value
+		weighted += (i + 1) * value
+	}
+	if n == 0 || sum == 0 {
+		return 0
+	}
+	return 2*float64(weighted)/(float64(n)*float64(sum)) - float64(n+1)/float64(n)
+}
+
+// herfindahlIndex is the sum of the squared shares of the values, from 1/n
+// when everybody contributed the same to 1 when one contributor made
+// everything
+func herfindahlIndex(values []int) float64 {
+	sum := 0
+	for _, value := range values {
+		sum += value
+	}
+	if sum == 0 {
+		return 0
+	}
+
+	index := 0.0
+	for _, value := range values {
+		share := float64(value) / float64(sum)
+		index += share * share
+	}
+	return index
+}
+
+// contributorTotals returns the changed lines of each contributor
+func contributorTotals(contributors []*Contributor) []int {
+	totals := make([]int, len(contributors))
+	for i, contributor := range contributors {
+		totals[i] = contributor.Additions + contributor.Deletions
+	}
+	return totals
+}
@@ -0,0 +1,110 @@
+// Package gitwho analyzes the history of a git repository and computes
+// the statistics of its contributors: their commits and the lines they
+// added and deleted, overall and per file.
+//
+// Analyze runs an analysis with the git binary, configured by options:
+//
+//	report, err := gitwho.Analyze(ctx, gitwho.WithPaths("src"), gitwho.WithExcludes("*.pb.go"))
+//
+// The gitwho command is built on this package, and Aggregate, ComputeShares
+// and Summarize are available for commits read some other way.
+//
+// GitBackend, which runs the git binary, is the only Backend the package
+// provides. The go-git and libgit2 backends of the command's --backend flag
+// are part of the command, not of this package; programs that read the
+// history in process implement Backend themselves.
+package gitwho
+
+import "time"
+
+// Contributor represents a git contributor with their statistics
+type Contributor struct {
+	Name      string
+	Email     string
+	Commits   int
+	Additions int
+	Deletions int
+	Files     map[string]int // lines changed per file path
+
+	BinaryFiles map[string]int // changes per binary file path
+
+	SignedCommits int // commits with a GPG or SSH signature
+
+	GitHubLogin string // GitHub account, set by gitwho --enrich github
+	AvatarURL   string // avatar of the GitHub account
+
+	CommitSizes  []int           // lines changed by each commit
+	CommitDates  []time.Time     // author date of each commit
+	CommitHashes []string        // hash of each commit
+	SignedHashes []string        // hash of each signed commit
+	FirstCommit  time.Time       // author date of the earliest commit
+	LastCommit   time.Time       // author date of the latest commit
+	Days         map[string]bool // distinct days with commits, as YYYY-MM-DD
+
+	CommitShare float64 // percentage of all commits
+	ChangeShare float64 // percentage of all changed lines
+
+	Others bool // merges the contributors left out by a limit, not a person
+}
+
+// Report holds the contributor statistics of one analysis, ready to display
+type Report struct {
+	Path         string
+	TimeRange    string
+	Contributors []*Contributor // contributors to display, sorted and limited
+	Summary      Summary        // totals over all contributors that passed the filters
+}
+
+// Summary holds the totals of a report. A commit credited to several
+// contributors, like its author and committer, is one of the Commits.
+type Summary struct {
+	Contributors int
+	Commits      int
+	Files        int // distinct files changed by any contributor
+	BinaryFiles  int // distinct binary files changed by any contributor
+	Additions    int
+	Deletions    int
+	CommitShare  float64
+	ChangeShare  float64
+
+	SignedCommits int
+
+	AvgCommitSize    float64
+	MedianCommitSize float64
+
+	FirstCommit time.Time
+	LastCommit  time.Time
+	ActiveDays  int // distinct days with commits by any contributor
+
+	// Concentration of line changes among the contributors
+	Gini float64
+	HHI  float64
+}
+
+// Commit represents a single commit parsed from git log output
+type Commit struct {
+	Hash  string
+	Name  string
+	Email string
+	Date  time.Time // author date in the commit's own timezone
+	Files []FileChange
+
+	CoAuthors []string // "Name <email>" values of the Co-authored-by trailers
+	Reviewers []string // "Name <email>" values of the Reviewed-by trailers
+	SignOffs  []string // "Name <email>" values of the Signed-off-by trailers
+	Signed    bool     // the commit has a GPG or SSH signature
+
+	CommitterName  string
+	CommitterEmail string
+	CommitDate     time.Time // committer date in the commit's own timezone
+
+	Subject string
+}
+
+// FileChange represents the numstat entry of a single file in a commit
+type FileChange struct {
+	Path      string
+	Additions int
+	Deletions int
+	Binary    bool
+}
@@ -0,0 +1,212 @@
+package gitwho
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Markers used to tell commit header lines apart from numstat lines
+const (
+	CommitMarker   = "\x1e"
+	FieldSeparator = "\x1f"
+)
+
+// trailerSep separates the values of a trailer in a commit header line
+const trailerSep = "\x1d"
+
+// LogFields selects the optional fields of LogFormat. Fields left out are
+// empty in the output, and in the commits ParseLog returns.
+type LogFields struct {
+	Trailers bool // the Co-authored-by, Reviewed-by and Signed-off-by trailers
+	Signed   bool // the signature status, which makes git verify every signature
+}
+
+// LogFormat returns the git log --format that ParseLog reads, to be used
+// together with --numstat. Asking for signatures is slow on large
+// histories.
+func LogFormat(fields LogFields) string {
+	trailers := []string{"", "", ""}
+	if fields.Trailers {
+		trailers = []string{trailerFormat("Co-authored-by"), trailerFormat("Reviewed-by"), trailerFormat("Signed-off-by")}
+	}
+	// %aN, %aE, %cN and %cE resolve the identities through the repository's .mailmap
+	format := CommitMarker + "%H" + FieldSeparator + "%aN" + FieldSeparator + "%aE" + FieldSeparator + "%aI" +
+		FieldSeparator + strings.Join(trailers, FieldSeparator) +
+		FieldSeparator + "%cN" + FieldSeparator + "%cE" + FieldSeparator + "%cI" + FieldSeparator + "%s"
+	if fields.Signed {
+		format += FieldSeparator + "%G?"
+	}
+	return format
+}
+
+// trailerFormat returns the git log placeholder that lists the values of
+// the commit's trailers with the given key, separated by trailerSep. Values
+// continued on indented lines are unfolded so they stay on the header line.
+func trailerFormat(key string) string {
+	return "%(trailers:key=" + key + ",valueonly,unfold,separator=%x1d)"
+}
+
+// ParseLog parses git log output in LogFormat into individual commits
+func ParseLog(output string) ([]*Commit, error) {
+	var commits []*Commit
+	err := parseLog(strings.NewReader(output), func(commit *Commit) bool {
+		commits = append(commits, commit)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return commits, nil
+}
+
+// parseLog parses git log output in LogFormat as it is read from r, and
+// passes each commit to yield once its numstat lines were read. It stops
+// early when yield returns false, and with an error at a header line it
+// cannot parse.
+func parseLog(r io.Reader, yield func(*Commit) bool) error {
+	reader := bufio.NewReader(r)
+	var current *Commit
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		line = strings.TrimSuffix(line, "\n")
+
+		if strings.HasPrefix(line, CommitMarker) {
+			// This is a hash/name/email/date header line
+			if current != nil && !yield(current) {
+				return nil
+			}
+			header, parseErr := parseHeader(line)
+			if parseErr != nil {
+				return parseErr
+			}
+			current = header
+		} else if len(line) > 0 && current != nil {
+			if change, ok := parseStatLine(line); ok {
+				current.Files = append(current.Files, change)
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+	if current != nil {
+		yield(current)
+	}
+	return nil
+}
+
+// parseHeader parses the header line of a commit, which needs at least
+// the hash, name, email and date. The dates must be in strict ISO 8601
+// form, as %aI prints them, since the hour filters and timelines depend on
+// them.
+func parseHeader(line string) (*Commit, error) {
+	parts := strings.Split(strings.TrimPrefix(line, CommitMarker), FieldSeparator)
+	if len(parts) < 4 {
+		return nil, fmt.Errorf("incomplete header of commit %s with %d fields, want its hash, name, email and date", parts[0], len(parts))
+	}
+	commit := &Commit{Hash: parts[0], Name: parts[1], Email: parts[2]}
+	date, err := time.Parse(time.RFC3339, parts[3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid author date %q of commit %s", parts[3], commit.Hash)
+	}
+	commit.Date = date
+	if len(parts) > 6 {
+		commit.CoAuthors = parseTrailers(parts[4])
+		commit.Reviewers = parseTrailers(parts[5])
+		commit.SignOffs = parseTrailers(parts[6])
+	}
+	if len(parts) > 9 {
+		commit.CommitterName, commit.CommitterEmail = parts[7], parts[8]
+		date, err := time.Parse(time.RFC3339, parts[9])
+		if err != nil {
+			return nil, fmt.Errorf("invalid committer date %q of commit %s", parts[9], commit.Hash)
+		}
+		commit.CommitDate = date
+	}
+	if len(parts) > 10 {
+		commit.Subject = parts[10]
+	}
+	if len(parts) > 11 {
+		commit.Signed = IsSigned(parts[11])
+	}
+	return commit, nil
+}
+
+// parseTrailers returns the non-empty values of a trailerSep separated list
+func parseTrailers(field string) []string {
+	var values []string
+	for _, value := range strings.Split(field, trailerSep) {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// IsSigned reports whether a %G? signature status is a signature. Only
+// "N" (no signature) and "B" (bad signature) count as unsigned; a valid
+// signature by an unknown or expired key still counts as signed.
+func IsSigned(status string) bool {
+	return status != "" && status != "N" && status != "B"
+}
+
+// parseStatLine parses a single line of git numstat output
+func parseStatLine(line string) (FileChange, bool) {
+	parts := strings.SplitN(line, "\t", 3)
+	if len(parts) < 3 {
+		return FileChange{}, false
+	}
+
+	change := FileChange{Path: unquotePath(renamedPath(parts[2]))}
+
+	// Binary files have no line counts
+	if parts[0] == "-" && parts[1] == "-" {
+		change.Binary = true
+		return change, true
+	}
+
+	// Parse additions and deletions
+	fmt.Sscanf(parts[0], "%d", &change.Additions)
+	fmt.Sscanf(parts[1], "%d", &change.Deletions)
+
+	return change, true
+}
+
+// unquotePath returns a path git quoted for containing special or, with
+// the default core.quotePath, non-ASCII characters as the plain path. Git
+// quotes with the escapes of a Go string, bytes of UTF-8 in octal.
+func unquotePath(path string) string {
+	if len(path) < 2 || path[0] != '"' || path[len(path)-1] != '"' {
+		return path
+	}
+	if unquoted, err := strconv.Unquote(path); err == nil {
+		return unquoted
+	}
+	return path
+}
+
+// renamedPath returns the new path of a numstat rename entry such as
+// "src/{old.go => new.go}" or "old.go => new.go", or path itself
+func renamedPath(path string) string {
+	if open := strings.Index(path, "{"); open >= 0 {
+		if close := strings.Index(path[open:], "}"); close >= 0 {
+			inner := path[open+1 : open+close]
+			if _, to, ok := strings.Cut(inner, " => "); ok {
+				joined := path[:open] + to + path[open+close+1:]
+				return strings.ReplaceAll(joined, "//", "/")
+			}
+		}
+	}
+	if _, to, ok := strings.Cut(path, " => "); ok {
+		return to
+	}
+	return path
+}
@@ -0,0 +1,102 @@
+package gitwho
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// header returns a LogFormat header line with the fields
+func header(fields ...string) string {
+	return CommitMarker + strings.Join(fields, FieldSeparator) + "\n"
+}
+
+func TestParseLog(t *testing.T) {
+	output := header("a1", "Jane Doe", "jane@example.com", "2024-03-04T16:30:00+09:00") +
+		"\n3\t1\tsrc/main.go\n-\t-\tlogo.png\n2\t0\tsrc/{old.go => new.go}\n" +
+		header("b2", "John Smith", "john@example.com", "2024-03-01T09:00:00-05:00") +
+		"\n10\t0\tREADME.md\n"
+
+	commits, err := ParseLog(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("got %d commits, want 2", len(commits))
+	}
+
+	first := commits[0]
+	if first.Hash != "a1" || first.Name != "Jane Doe" || first.Email != "jane@example.com" {
+		t.Errorf("got identity %s %s %s", first.Hash, first.Name, first.Email)
+	}
+	if hour := first.Date.Hour(); hour != 16 {
+		t.Errorf("got hour %d, want 16 in the author's timezone", hour)
+	}
+	want := []FileChange{
+		{Path: "src/main.go", Additions: 3, Deletions: 1},
+		{Path: "logo.png", Binary: true},
+		{Path: "src/new.go", Additions: 2},
+	}
+	if len(first.Files) != len(want) {
+		t.Fatalf("got files %+v, want %+v", first.Files, want)
+	}
+	for i := range want {
+		if first.Files[i] != want[i] {
+			t.Errorf("got file %+v, want %+v", first.Files[i], want[i])
+		}
+	}
+	if !commits[1].Date.Equal(time.Date(2024, 3, 1, 14, 0, 0, 0, time.UTC)) {
+		t.Errorf("got date %v", commits[1].Date)
+	}
+}
+
+func TestParseLogInvalidDate(t *testing.T) {
+	output := header("a1", "Jane Doe", "jane@example.com", "yesterday") + "\n3\t1\tmain.go\n"
+	if _, err := ParseLog(output); err == nil {
+		t.Fatal("ParseLog succeeded, want an error for the invalid date")
+	}
+}
+
+func TestParseLogIncompleteHeader(t *testing.T) {
+	output := header("a1", "Jane Doe", "jane@example.com", "2024-03-01T14:00:00Z") + "\n3\t1\tmain.go\n" +
+		header("b2", "John Smith") + "\n2\t0\tREADME.md\n"
+	_, err := ParseLog(output)
+	if err == nil {
+		t.Fatal("ParseLog succeeded, want an error for the header without email and date")
+	}
+	if !strings.Contains(err.Error(), "commit b2") {
+		t.Errorf("the error %q does not name the header of commit b2", err)
+	}
+}
+
+func TestRenamedPath(t *testing.T) {
+	tests := map[string]string{
+		"main.go":                  "main.go",
+		"old.go => new.go":         "new.go",
+		"src/{old.go => new.go}":   "src/new.go",
+		"src/{a => b}/main.go":     "src/b/main.go",
+		"src/{ => sub}/main.go":    "src/sub/main.go",
+		"src/{sub => }/main.go":    "src/main.go",
+		"{docs => doc}/guide/x.md": "doc/guide/x.md",
+	}
+	for path, want := range tests {
+		if got := renamedPath(path); got != want {
+			t.Errorf("renamedPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestParseStatLineQuoted(t *testing.T) {
+	tests := map[string]string{
+		"3\t1\t\"\\303\\244.go\"":                        "ä.go",
+		"1\t0\t\"src/tab\\tname.go\"":                    "src/tab\tname.go",
+		"0\t0\t\"\\303\\244.go\" => \"d/\\303\\266.go\"": "d/ö.go",
+		"2\t2\t\"quote\\\"d.go\"":                        "quote\"d.go",
+	}
+	for line, want := range tests {
+		change, ok := parseStatLine(line)
+		if !ok || change.Path != want {
+			t.Errorf("parseStatLine(%q) = %q, want %q", line, change.Path, want)
+		}
+	}
+}
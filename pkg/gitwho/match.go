@@ -0,0 +1,140 @@
+package gitwho
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ignoreRule is a single compiled gitignore-style pattern
+type ignoreRule struct {
+	re     *regexp.Regexp
+	negate bool
+}
+
+// PathMatcher matches repository paths against gitignore-style patterns.
+// As in .gitignore, the last matching pattern wins and a pattern prefixed
+// with "!" re-includes paths excluded by an earlier one.
+type PathMatcher struct {
+	rules []ignoreRule
+}
+
+// NewPathMatcher compiles the patterns. Empty patterns and patterns
+// starting with "#" are ignored.
+func NewPathMatcher(patterns []string) (*PathMatcher, error) {
+	m := &PathMatcher{}
+	for _, pattern := range patterns {
+		if err := m.add(pattern); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// add compiles a pattern and appends it to the matcher's rules
+func (m *PathMatcher) add(pattern string) error {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return nil
+	}
+
+	rule := ignoreRule{}
+	if strings.HasPrefix(pattern, "!") {
+		rule.negate = true
+		pattern = pattern[1:]
+	}
+
+	re, err := regexp.Compile(GlobRegexp(pattern))
+	if err != nil {
+		return fmt.Errorf("invalid pattern %q: %v", pattern, err)
+	}
+	rule.re = re
+	m.rules = append(m.rules, rule)
+	return nil
+}
+
+// Match reports whether path, relative to the repository root, is matched.
+// A path also matches when one of its parent directories does.
+func (m *PathMatcher) Match(path string) bool {
+	if m == nil || len(m.rules) == 0 {
+		return false
+	}
+
+	matched := false
+	for _, rule := range m.rules {
+		if rule.matches(path) {
+			matched = !rule.negate
+		}
+	}
+	return matched
+}
+
+// Len returns the number of patterns
+func (m *PathMatcher) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.rules)
+}
+
+// matches reports whether the rule matches path or one of its parents
+func (r ignoreRule) matches(path string) bool {
+	for {
+		if r.re.MatchString(path) {
+			return true
+		}
+		i := strings.LastIndex(path, "/")
+		if i < 0 {
+			return false
+		}
+		path = path[:i]
+	}
+}
+
+// GlobRegexp converts a gitignore-style glob to an anchored regular
+// expression. Patterns without a slash match a name at any depth, "**"
+// matches across directories and "*" and "?" stay within one directory.
+func GlobRegexp(pattern string) string {
+	pattern = strings.TrimSuffix(pattern, "/")
+
+	var b strings.Builder
+	b.WriteString("^")
+	if strings.HasPrefix(pattern, "/") {
+		pattern = pattern[1:]
+	} else if !strings.Contains(pattern, "/") {
+		b.WriteString("(?:.*/)?")
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	b.WriteString("$")
+	return b.String()
+}
@@ -0,0 +1,25 @@
+package gitwho
+
+import "testing"
+
+func TestPathMatcher(t *testing.T) {
+	matcher, err := NewPathMatcher([]string{"vendor/", "*.pb.go", "!api/keep.pb.go", "# comment", ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matcher.Len() != 3 {
+		t.Errorf("got %d patterns, want 3", matcher.Len())
+	}
+	tests := map[string]bool{
+		"vendor/lib/a.go": true,
+		"src/vendor.go":   false,
+		"api/user.pb.go":  true,
+		"api/keep.pb.go":  false,
+		"main.go":         false,
+	}
+	for path, want := range tests {
+		if got := matcher.Match(path); got != want {
+			t.Errorf("Match(%q) = %v, want %v", path, got, want)
+		}
+	}
+}